@@ -1,43 +1,195 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	musplayer "musPlayer"
+	"musPlayer/internal/cache"
 	"musPlayer/internal/config"
+	"musPlayer/internal/grpcServer"
 	"musPlayer/internal/handler"
+	"musPlayer/internal/health"
+	"musPlayer/internal/lifecycle"
 	"musPlayer/internal/logger"
 	servicegenius "musPlayer/internal/serviceGenius"
 	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	memoryrepo "musPlayer/pkg/memoryRepo"
 	postgresrepo "musPlayer/pkg/postgresRepo"
+	sqliterepo "musPlayer/pkg/sqliteRepo"
+	"os"
 
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(configCommand(args[1:]))
+	}
 
-	cfg, err := config.MustLoad()
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		// Справку по флагам уже напечатал config.Load
+		os.Exit(0)
+	}
 	if err != nil {
 		log.Fatal("Error loading configuration: ", err)
 	}
 
 	// Инициализация логгера
-	logger.InitLogger(cfg.Logging.Level)
+	if err := logger.InitLogger(cfg.Logging); err != nil {
+		log.Fatal("Error initializing logger: ", err)
+	}
 
 	// Пример использования конфигурации
 	logger.Logger.Infof("Starting application on port %s", cfg.App.Port)
 
-	db, err := postgresrepo.NewPostgresDb(cfg.Database)
+	dbRepo, db, err := openRepository(cfg.Database)
 	if err != nil {
 		logrus.Fatalf("error while connecting to db: %v", err)
 	}
+	usePostgres := cfg.Database.Driver == models.DriverPostgres
+
+	app := lifecycle.New(cfg.App.ShutdownTimeout, cfg.App.DrainDelay)
+
+	// Без Postgres общего кеша нет, ответы Genius кешируются только в памяти процесса
+	var cacheStore cache.Store
+	if usePostgres {
+		cacheStore = dbRepo.ProviderCacheRepository
+	}
+	providerCache := cache.NewTiered(cache.NewLRU(cfg.Cache.LRUSize), cacheStore)
+	geniusSrv := servicegenius.NewGeniusService(cfg.GeniusConfig, cfg.API.BaseURL, providerCache, cfg.Cache)
+	dbSrv := servicePostgres.NewServicePostgres(dbRepo, geniusSrv, cfg.Cache, cfg.Sync, cfg.Similarity, cfg.Listens, cfg.Webhooks, cfg.Stream, cfg.Changes)
+
+	app.Go("cache-cleanup", func(ctx context.Context) error {
+		return providerCache.RunCleanup(ctx, cfg.Cache.CleanupInterval)
+	})
+	// Остальные обработчики работают с данными, которые есть только в Postgres
+	if usePostgres {
+		app.Go("song-refresher", dbSrv.RunRefresher)
+		app.Go("similarity-index", dbSrv.RunIndexer)
+		app.Go("listen-ingester", dbSrv.RunIngester)
+		app.Go("webhook-dispatcher", dbSrv.RunDispatcher)
+		app.Go("song-stream", dbSrv.RunStream)
+		app.Go("tombstone-purger", dbSrv.RunTombstonePurger)
+	}
 
-	dbRepo := postgresrepo.NewRepository(db)
-	dbSrv := servicePostgres.NewServicePostgres(dbRepo)
-	geniusSrv := servicegenius.NewGeniusService(cfg.GeniusConfig.ID, cfg.GeniusConfig.Secret, cfg.GeniusConfig.RedirectURI)
-	handler := handler.NewHandler(dbSrv, geniusSrv)
+	// Проверки готовности для /readyz: каждая зависимость регистрирует свою проверку
+	checks := health.NewRegistry(cfg.App.ReadinessTimeout)
+	checks.Register("lifecycle", app.CheckReady)
+	switch cfg.Database.Driver {
+	case models.DriverPostgres:
+		checks.Register("postgres", dbRepo.Ping)
+		checks.Register("migrations", dbRepo.CheckMigrations)
+	case models.DriverSQLite:
+		checks.Register("sqlite", db.PingContext)
+	}
+	checks.Register("genius_token", geniusSrv.CheckToken)
+
+	handler := handler.NewHandler(dbSrv, geniusSrv, checks, cfg)
+
+	srv := musplayer.NewServer(cfg.App.Port, handler)
+	servers := []func() error{srv.Run}
+	stops := []lifecycle.Func{srv.Shutdown}
+
+	// gRPC для внутренних потребителей вызывает тот же сервисный слой на отдельном порту
+	if cfg.GRPC.Enabled {
+		grpcSrv := grpcServer.NewServer(dbSrv, geniusSrv, cfg.GRPC)
+		servers = append(servers, func() error {
+			return grpcSrv.Run(cfg.GRPC.Port)
+		})
+		stops = append(stops, grpcSrv.Shutdown)
+	}
+
+	// Порядок остановки: серверы дожидаются текущих запросов в Run, затем закрываем пул соединений и сбрасываем логи
+	app.OnShutdown("database", func(ctx context.Context) error {
+		if db == nil {
+			return nil
+		}
+		return db.Close()
+	})
+	app.OnShutdown("logger", func(ctx context.Context) error {
+		return logger.Close()
+	})
+
+	os.Exit(app.Run(func() error {
+		return serveAll(servers)
+	}, func(ctx context.Context) error {
+		// Живые потоки не завершаются сами, поэтому закрываются до ожидания текущих запросов
+		dbSrv.CloseStreams()
+		var errs []error
+		for _, stop := range stops {
+			errs = append(errs, stop(ctx))
+		}
+		return errors.Join(errs...)
+	}))
+}
 
-	srv := new(musplayer.Server)
-	if err := srv.Run(cfg.App.Port, handler); err != nil {
-		logrus.Panic("error while running server")
+// serveAll запускает серверы и возвращает первую ошибку; nil — когда все серверы остановлены
+func serveAll(servers []func() error) error {
+	errCh := make(chan error, len(servers))
+	for _, serve := range servers {
+		go func(serve func() error) {
+			errCh <- serve()
+		}(serve)
+	}
+	for range servers {
+		if err := <-errCh; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openRepository подключает хранилище из database.driver. SQLite и память хранят только каталог песен,
+// остальные данные требуют Postgres. db равен nil, если хранилище не использует database/sql
+func openRepository(cfg models.DatabaseConfig) (repo *postgresrepo.Repository, db *sql.DB, err error) {
+	switch cfg.Driver {
+	case models.DriverSQLite:
+		if db, err = sqliterepo.NewSqliteDb(cfg.Path); err != nil {
+			return nil, nil, err
+		}
+		return postgresrepo.NewCatalogRepository(sqliterepo.NewSongRepository(db)), db, nil
+	case models.DriverMemory:
+		return postgresrepo.NewCatalogRepository(memoryrepo.NewSongRepository()), nil, nil
+	}
+
+	if db, err = postgresrepo.NewPostgresDb(cfg); err != nil {
+		return nil, nil, err
+	}
+	return postgresrepo.NewRepository(db, postgresrepo.DataSourceName(cfg)), db, nil
+}
+
+// configCommand обрабатывает "musplayer config print [флаги]": выводит действующую конфигурацию без секретов
+func configCommand(args []string) int {
+	const usage = "usage: musplayer config print [flags]"
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		fmt.Println(usage)
+		return 0
+	}
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	cfg, err := config.Load(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if cfg != nil {
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			fmt.Fprintln(os.Stderr, printErr)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration errors:\n%v\n", err)
+		return 1
 	}
+	return 0
 }
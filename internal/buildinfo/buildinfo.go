@@ -0,0 +1,39 @@
+package buildinfo
+
+import (
+	"runtime"
+	"time"
+)
+
+// Version и Commit задаются при сборке через -ldflags "-X musPlayer/internal/buildinfo.Version=..."
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+var startTime = time.Now()
+
+// Info описывает сборку и время работы процесса
+type Info struct {
+	Version   string    `json:"version"`
+	Commit    string    `json:"commit"`
+	GoVersion string    `json:"go_version"`
+	StartedAt time.Time `json:"started_at"`
+	Uptime    string    `json:"uptime"`
+}
+
+// Get возвращает информацию о текущей сборке
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		GoVersion: runtime.Version(),
+		StartedAt: startTime,
+		Uptime:    Uptime().Round(time.Second).String(),
+	}
+}
+
+// Uptime возвращает время, прошедшее с запуска процесса
+func Uptime() time.Duration {
+	return time.Since(startTime)
+}
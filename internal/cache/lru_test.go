@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(3)
+	c.Set("a", []byte("1"), time.Hour)
+	c.Set("b", []byte("2"), time.Hour)
+	c.Set("c", []byte("3"), time.Hour)
+
+	// Чтение и перезапись поднимают запись в начало очереди
+	c.Get("a")
+	c.Set("b", []byte("2b"), time.Hour)
+	c.Set("d", []byte("4"), time.Hour)
+	c.Set("e", []byte("5"), time.Hour)
+
+	tests := []struct {
+		key   string
+		want  string
+		found bool
+	}{
+		{key: "a", found: false},
+		{key: "c", found: false},
+		{key: "b", want: "2b", found: true},
+		{key: "d", want: "4", found: true},
+		{key: "e", want: "5", found: true},
+	}
+	for _, tt := range tests {
+		value, ok := c.Get(tt.key)
+		if ok != tt.found || string(value) != tt.want {
+			t.Errorf("Get(%q) = %q, %v; want %q, %v", tt.key, value, ok, tt.want, tt.found)
+		}
+	}
+	if n := c.Len(); n != 3 {
+		t.Fatalf("Len() = %d, want 3", n)
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	c := NewLRU(10)
+	c.Set("short", []byte("1"), 10*time.Millisecond)
+	c.Set("long", []byte("2"), time.Hour)
+
+	time.Sleep(15 * time.Millisecond)
+	if _, ok := c.Get("short"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if _, ok := c.Get("long"); !ok {
+		t.Fatal("live entry was not returned")
+	}
+	// Устаревшая запись удаляется при чтении
+	if n := c.Len(); n != 1 {
+		t.Fatalf("Len() = %d, want 1", n)
+	}
+
+	// Перезапись продлевает срок
+	c.Set("long", []byte("3"), 10*time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	if _, ok := c.Get("long"); ok {
+		t.Fatal("entry kept the old TTL after being overwritten")
+	}
+}
+
+func TestLRUPurge(t *testing.T) {
+	c := NewLRU(10)
+	for _, key := range []string{"genius:search:a", "genius:search:b", "genius:lyrics:a", "other"} {
+		c.Set(key, []byte(key), time.Hour)
+	}
+
+	if n := c.Purge("genius:search:"); n != 2 {
+		t.Fatalf("Purge() = %d, want 2", n)
+	}
+	if _, ok := c.Get("genius:lyrics:a"); !ok {
+		t.Fatal("Purge removed an entry outside the prefix")
+	}
+	if n := c.Purge(""); n != 2 {
+		t.Fatalf("Purge(\"\") = %d, want 2", n)
+	}
+	if n := c.Len(); n != 0 {
+		t.Fatalf("Len() = %d after purging everything", n)
+	}
+}
+
+func TestLRUZeroCapacity(t *testing.T) {
+	c := NewLRU(0)
+	c.Set("a", []byte("1"), time.Hour)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("cache with zero capacity stored an entry")
+	}
+}
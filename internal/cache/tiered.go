@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"expvar"
+	"musPlayer/internal/logger"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var stats = expvar.NewMap("cache")
+
+// Store — второй, разделяемый между экземплярами уровень кеша (таблица provider_cache в Postgres)
+type Store interface {
+	GetCacheEntry(ctx context.Context, key string) ([]byte, bool, error)
+	SetCacheEntry(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	PurgeCacheEntries(ctx context.Context, prefix string) (int64, error)
+	DeleteExpiredCacheEntries(ctx context.Context) (int64, error)
+}
+
+// Tiered — двухуровневый кеш: LRU в памяти процесса и общее хранилище.
+// Одновременные промахи по одному ключу схлопываются в одну загрузку
+type Tiered struct {
+	lru   *LRU
+	store Store
+	group singleflight.Group
+}
+
+// NewTiered создает двухуровневый кеш. store может быть nil — тогда используется только LRU
+func NewTiered(lru *LRU, store Store) *Tiered {
+	return &Tiered{lru: lru, store: store}
+}
+
+type refreshKey struct{}
+
+// WithRefresh возвращает контекст, в котором GetOrLoad не читает кеш, а загружает значение заново
+// и перезаписывает им кеш
+func WithRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+func isRefresh(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// GetOrLoad возвращает значение из кеша или вызывает load и сохраняет результат на ttl.
+// Ошибки load не кешируются. Ошибки хранилища только логируются: кеш не должен ломать запрос
+func (t *Tiered) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	refresh := isRefresh(ctx)
+	if !refresh {
+		if value, ok := t.lru.Get(key); ok {
+			stats.Add("lru_hits_total", 1)
+			return value, nil
+		}
+	}
+
+	flightKey := key
+	if refresh {
+		flightKey = "refresh:" + key
+	}
+
+	// Загрузка не должна прерываться, если отменил запрос только один из ожидающих
+	loadCtx := context.WithoutCancel(ctx)
+	ch := t.group.DoChan(flightKey, func() (interface{}, error) {
+		if t.store != nil && !refresh {
+			value, ok, err := t.store.GetCacheEntry(loadCtx, key)
+			if err != nil {
+				logger.FromContext(loadCtx).Warnf("Failed to read cache entry %s: %v", key, err)
+			} else if ok {
+				stats.Add("store_hits_total", 1)
+				t.lru.Set(key, value, ttl)
+				return value, nil
+			}
+		}
+
+		stats.Add("misses_total", 1)
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		t.lru.Set(key, value, ttl)
+		if t.store != nil {
+			if err := t.store.SetCacheEntry(loadCtx, key, value, ttl); err != nil {
+				logger.FromContext(loadCtx).Warnf("Failed to write cache entry %s: %v", key, err)
+			}
+		}
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Shared {
+			stats.Add("coalesced_total", 1)
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	}
+}
+
+// Purge удаляет записи с ключами, начинающимися с prefix, из обоих уровней.
+// Пустой prefix очищает кеш полностью
+func (t *Tiered) Purge(ctx context.Context, prefix string) (int64, error) {
+	removed := int64(t.lru.Purge(prefix))
+	if t.store == nil {
+		return removed, nil
+	}
+
+	n, err := t.store.PurgeCacheEntries(ctx, prefix)
+	if err != nil {
+		return removed, err
+	}
+	if n > removed {
+		removed = n
+	}
+	return removed, nil
+}
+
+// RunCleanup периодически удаляет устаревшие записи из хранилища, пока не отменен ctx
+func (t *Tiered) RunCleanup(ctx context.Context, interval time.Duration) error {
+	if t.store == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			n, err := t.store.DeleteExpiredCacheEntries(ctx)
+			if err != nil {
+				logger.Logger.Warnf("Failed to delete expired cache entries: %v", err)
+				continue
+			}
+			if n > 0 {
+				logger.Logger.Debugf("Deleted %d expired cache entries", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryStore — Store в памяти для проверки второго уровня
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	failing bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[string][]byte)}
+}
+
+func (s *memoryStore) GetCacheEntry(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return nil, false, errors.New("store is down")
+	}
+	value, ok := s.entries[key]
+	return value, ok, nil
+}
+
+func (s *memoryStore) SetCacheEntry(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return errors.New("store is down")
+	}
+	s.entries[key] = value
+	return nil
+}
+
+func (s *memoryStore) PurgeCacheEntries(ctx context.Context, prefix string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memoryStore) DeleteExpiredCacheEntries(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestTieredGetOrLoad(t *testing.T) {
+	store := newMemoryStore()
+	store.entries["shared"] = []byte("from store")
+	c := NewTiered(NewLRU(10), store)
+
+	var loads atomic.Int32
+	load := func(value string) func(ctx context.Context) ([]byte, error) {
+		return func(ctx context.Context) ([]byte, error) {
+			loads.Add(1)
+			return []byte(value), nil
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		key       string
+		load      string
+		want      string
+		wantLoads int32
+	}{
+		{name: "miss loads and stores", ctx: context.Background(), key: "k", load: "loaded", want: "loaded", wantLoads: 1},
+		{name: "lru hit", ctx: context.Background(), key: "k", load: "other", want: "loaded", wantLoads: 1},
+		{name: "store hit", ctx: context.Background(), key: "shared", load: "other", want: "from store", wantLoads: 1},
+		{name: "refresh bypasses cache", ctx: WithRefresh(context.Background()), key: "k", load: "fresh", want: "fresh", wantLoads: 2},
+		{name: "refresh overwrites cache", ctx: context.Background(), key: "k", load: "other", want: "fresh", wantLoads: 2},
+	}
+	for _, tt := range tests {
+		value, err := c.GetOrLoad(tt.ctx, tt.key, time.Hour, load(tt.load))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if string(value) != tt.want {
+			t.Errorf("%s: value = %q, want %q", tt.name, value, tt.want)
+		}
+		if got := loads.Load(); got != tt.wantLoads {
+			t.Errorf("%s: loads = %d, want %d", tt.name, got, tt.wantLoads)
+		}
+	}
+	if string(store.entries["k"]) != "fresh" {
+		t.Fatalf("store entry = %q, want the refreshed value", store.entries["k"])
+	}
+}
+
+func TestTieredCoalescesConcurrentLoads(t *testing.T) {
+	c := NewTiered(NewLRU(10), nil)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "k", time.Hour, func(ctx context.Context) ([]byte, error) {
+				loads.Add(1)
+				<-release
+				return []byte("v"), nil
+			})
+			if err != nil || string(value) != "v" {
+				t.Errorf("GetOrLoad() = %q, %v", value, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("loads = %d, want 1", got)
+	}
+}
+
+func TestTieredDoesNotCacheErrors(t *testing.T) {
+	c := NewTiered(NewLRU(10), nil)
+	failure := errors.New("upstream failed")
+
+	if _, err := c.GetOrLoad(context.Background(), "k", time.Hour, func(ctx context.Context) ([]byte, error) {
+		return nil, failure
+	}); !errors.Is(err, failure) {
+		t.Fatalf("error = %v, want %v", err, failure)
+	}
+	value, err := c.GetOrLoad(context.Background(), "k", time.Hour, func(ctx context.Context) ([]byte, error) {
+		return []byte("v"), nil
+	})
+	if err != nil || string(value) != "v" {
+		t.Fatalf("GetOrLoad() after an error = %q, %v", value, err)
+	}
+}
+
+func TestTieredIgnoresStoreErrors(t *testing.T) {
+	store := newMemoryStore()
+	store.failing = true
+	c := NewTiered(NewLRU(10), store)
+
+	value, err := c.GetOrLoad(context.Background(), "k", time.Hour, func(ctx context.Context) ([]byte, error) {
+		return []byte("v"), nil
+	})
+	if err != nil || string(value) != "v" {
+		t.Fatalf("GetOrLoad() with a failing store = %q, %v", value, err)
+	}
+}
+
+func TestTieredPurge(t *testing.T) {
+	store := newMemoryStore()
+	c := NewTiered(NewLRU(10), store)
+	for _, key := range []string{"genius:search:a", "genius:lyrics:a"} {
+		c.GetOrLoad(context.Background(), key, time.Hour, func(ctx context.Context) ([]byte, error) {
+			return []byte(key), nil
+		})
+	}
+
+	n, err := c.Purge(context.Background(), "genius:search:")
+	if err != nil || n != 1 {
+		t.Fatalf("Purge() = %d, %v; want 1", n, err)
+	}
+	if _, ok := store.entries["genius:search:a"]; ok {
+		t.Fatal("Purge left the entry in the store")
+	}
+	if _, ok := store.entries["genius:lyrics:a"]; !ok {
+		t.Fatal("Purge removed an entry outside the prefix")
+	}
+}
@@ -1,50 +1,172 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
 	"musPlayer/models"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v2"
 )
 
+const redacted = "[REDACTED]"
+
 type Config struct {
 	Database     models.DatabaseConfig
 	API          models.APIConfig
 	Logging      models.LoggingConfig
 	App          models.AppConfig
 	GeniusConfig models.GeniusConfig
+	Cache        models.CacheConfig
+	Sync         models.SyncConfig
+	Similarity   models.SimilarityConfig
+	Listens      models.ListensConfig
+	Webhooks     models.WebhooksConfig
+	Stream       models.StreamConfig
+	Changes      models.ChangesConfig
+	GraphQL      models.GraphQLConfig
+	GRPC         models.GRPCConfig
 }
 
-func MustLoad() (*Config, error) {
-	err := godotenv.Load()
-	if err != nil {
+// Load собирает конфигурацию из нескольких источников. Приоритет (от низшего к высшему):
+// значения по умолчанию, файл YAML/TOML (флаг -config или CONFIG_FILE), переменные окружения
+// (включая необязательный .env и переменные *_FILE с путем к секрету), флаги командной строки.
+// Ошибки всех источников и валидации возвращаются вместе; при ошибках валидации
+// возвращается и собранная конфигурация, чтобы ее можно было показать
+func Load(args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+
+	cfg := defaults()
+	fields := cfg.fields()
+
+	flagSet := flag.NewFlagSet("musplayer", flag.ContinueOnError)
+	configPath := flagSet.String("config", os.Getenv("CONFIG_FILE"), "path to YAML or TOML config file (env CONFIG_FILE)")
+	flagValues := make(map[string]string)
+	for _, f := range fields {
+		flagSet.Var(flagRecorder{name: f.flagName(), values: flagValues}, f.flagName(), fmt.Sprintf("%s (env %s)", f.usage, f.env))
+	}
+	if err := flagSet.Parse(args); err != nil {
 		return nil, err
 	}
 
-	cfg := Config{
-		Database: models.DatabaseConfig{
-			Host:     os.Getenv("DB_HOST"),
-			Port:     os.Getenv("DB_PORT"),
-			User:     os.Getenv("DB_USER"),
-			Password: os.Getenv("DB_PASSWORD"),
-			Name:     os.Getenv("DB_NAME"),
-		},
-		API: models.APIConfig{
-			BaseURL: os.Getenv("API_BASE_URL"),
-			Key:     os.Getenv("API_KEY"),
-		},
-		Logging: models.LoggingConfig{
-			Level: os.Getenv("LOG_LEVEL"),
-		},
-		App: models.AppConfig{
-			Port: os.Getenv("APP_PORT"),
-		},
-		GeniusConfig: models.GeniusConfig{
-			ID:          os.Getenv("CLIENT_ID"),
-			Secret:      os.Getenv("CLIENT_SECRET"),
-			RedirectURI: os.Getenv("REDIRECT_URI"),
-		},
-	}
-
-	return &cfg, nil
+	var errs []error
+
+	if *configPath != "" {
+		fileValues, err := readFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		known := make(map[string]bool, len(fields))
+		for _, f := range fields {
+			known[f.key] = true
+			if v, ok := fileValues[f.key]; ok {
+				if err := f.value.Set(v); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %s: %w", *configPath, f.key, err))
+				}
+			}
+		}
+		for key := range fileValues {
+			if !known[key] {
+				errs = append(errs, fmt.Errorf("%s: unknown key %s", *configPath, key))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		v, ok, err := lookupEnv(f.env)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			if err := f.value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", f.env, err))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if v, ok := flagValues[f.flagName()]; ok {
+			if err := f.value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", f.flagName(), err))
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return &cfg, errors.Join(errs...)
+}
+
+// lookupEnv читает переменную окружения name или, если она не задана, файл из name_FILE (Docker secrets)
+func lookupEnv(name string) (string, bool, error) {
+	v, ok := os.LookupEnv(name)
+	path, fileOk := os.LookupEnv(name + "_FILE")
+	if ok && fileOk {
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+	if ok {
+		return v, true, nil
+	}
+	if !fileOk {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("env %s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// flagRecorder запоминает значения флагов, чтобы применить их после файла и окружения
+type flagRecorder struct {
+	name   string
+	values map[string]string
+}
+
+func (f flagRecorder) Set(s string) error { f.values[f.name] = s; return nil }
+func (f flagRecorder) String() string     { return "" }
+
+// Summary возвращает действующую конфигурацию в виде вложенной карты с замаскированными секретами
+func (c *Config) Summary() map[string]interface{} {
+	summary := make(map[string]interface{})
+	for _, f := range c.fields() {
+		v := f.value.Get()
+		if f.secret && f.value.String() != "" {
+			v = redacted
+		}
+
+		parts := strings.Split(f.key, ".")
+		node := summary
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := node[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				node[part] = next
+			}
+			node = next
+		}
+		node[parts[len(parts)-1]] = v
+	}
+	return summary
+}
+
+// Print выводит действующую конфигурацию в формате YAML с замаскированными секретами
+func (c *Config) Print(w io.Writer) error {
+	data, err := yaml.Marshal(c.Summary())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
 }
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv убирает из окружения все переменные конфигурации на время теста
+func clearEnv(t *testing.T) {
+	t.Helper()
+	names := []string{"CONFIG_FILE"}
+	for _, f := range (&Config{}).fields() {
+		names = append(names, f.env, f.env+"_FILE")
+	}
+	for _, name := range names {
+		// t.Setenv восстановит прежнее значение после теста
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// validEnv — минимальное окружение, с которым конфигурация проходит валидацию
+func validEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_DRIVER", "memory")
+	t.Setenv("GENIUS_TOKEN", "token")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		env       map[string]string
+		secret    string // содержимое файла из APP_PORT_FILE
+		args      []string
+		wantPort  string
+		wantLevel string
+	}{
+		{name: "defaults", wantPort: "8080", wantLevel: "info"},
+		{
+			name:      "file overrides defaults",
+			file:      "app:\n  port: \"8081\"\nlogging:\n  level: debug\n",
+			wantPort:  "8081",
+			wantLevel: "debug",
+		},
+		{
+			name:      "env overrides file",
+			file:      "app:\n  port: \"8081\"\nlogging:\n  level: debug\n",
+			env:       map[string]string{"APP_PORT": "8082"},
+			wantPort:  "8082",
+			wantLevel: "debug",
+		},
+		{
+			name:      "env file overrides file",
+			file:      "app:\n  port: \"8081\"\n",
+			secret:    "8083\n",
+			wantPort:  "8083",
+			wantLevel: "info",
+		},
+		{
+			name:      "flags override env",
+			file:      "app:\n  port: \"8081\"\nlogging:\n  level: debug\n",
+			env:       map[string]string{"APP_PORT": "8082", "LOG_LEVEL": "warn"},
+			args:      []string{"-app-port", "8084"},
+			wantPort:  "8084",
+			wantLevel: "warn",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			validEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if tt.secret != "" {
+				t.Setenv("APP_PORT_FILE", writeFile(t, "port", tt.secret))
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "config.yaml", tt.file)}, args...)
+			}
+
+			cfg, err := Load(args)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.App.Port != tt.wantPort {
+				t.Errorf("app.port = %q, want %q", cfg.App.Port, tt.wantPort)
+			}
+			if cfg.Logging.Level != tt.wantLevel {
+				t.Errorf("logging.level = %q, want %q", cfg.Logging.Level, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileFromEnv(t *testing.T) {
+	clearEnv(t)
+	validEnv(t)
+	path := writeFile(t, "config.toml", "[app]\nshutdown_timeout = \"1m\"\n\n[database]\nmax_open_conns = 3\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.App.ShutdownTimeout != time.Minute || cfg.Database.MaxOpenConns != 3 {
+		t.Fatalf("shutdown_timeout = %s, max_open_conns = %d, want 1m and 3", cfg.App.ShutdownTimeout, cfg.Database.MaxOpenConns)
+	}
+}
+
+func TestLookupEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *string
+		file    *string
+		want    string
+		wantOk  bool
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "value", value: ptr("secret"), want: "secret", wantOk: true},
+		{name: "value is not trimmed", value: ptr("secret\n"), want: "secret\n", wantOk: true},
+		{name: "file trailing newline", file: ptr("secret\n"), want: "secret", wantOk: true},
+		{name: "file crlf", file: ptr("secret\r\n"), want: "secret", wantOk: true},
+		{name: "file keeps inner newlines and spaces", file: ptr(" line1\nline2 \n\n"), want: " line1\nline2 ", wantOk: true},
+		{name: "both set", value: ptr("a"), file: ptr("b"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SECRET", "")
+			os.Unsetenv("TEST_SECRET")
+			t.Setenv("TEST_SECRET_FILE", "")
+			os.Unsetenv("TEST_SECRET_FILE")
+			if tt.value != nil {
+				t.Setenv("TEST_SECRET", *tt.value)
+			}
+			if tt.file != nil {
+				t.Setenv("TEST_SECRET_FILE", writeFile(t, "secret", *tt.file))
+			}
+
+			got, ok, err := lookupEnv("TEST_SECRET")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("lookupEnv() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want || ok != tt.wantOk {
+				t.Fatalf("lookupEnv() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("TEST_SECRET_FILE", filepath.Join(t.TempDir(), "missing"))
+		if _, _, err := lookupEnv("TEST_SECRET"); err == nil || !strings.Contains(err.Error(), "TEST_SECRET_FILE") {
+			t.Fatalf("lookupEnv() error = %v, want error naming TEST_SECRET_FILE", err)
+		}
+	})
+}
+
+func ptr(s string) *string { return &s }
+
+func TestLoadCollectsAllErrors(t *testing.T) {
+	clearEnv(t)
+	validEnv(t)
+	path := writeFile(t, "config.yaml", "app:\n  port: \"8081\"\n  colour: blue\nlogging:\n  format: xml\n")
+	t.Setenv("SHUTDOWN_TIMEOUT", "soon")
+	t.Setenv("CLIENT_SECRET", "a")
+	t.Setenv("CLIENT_SECRET_FILE", writeFile(t, "secret", "b"))
+
+	cfg, err := Load([]string{"-config", path, "-database-max-open-conns", "many", "-cache-lru-size", "-1"})
+	if err == nil {
+		t.Fatal("Load() error = nil, want errors")
+	}
+	if cfg == nil {
+		t.Fatal("Load() returned no config together with validation errors")
+	}
+	for _, want := range []string{
+		"unknown key app.colour",
+		"env SHUTDOWN_TIMEOUT",
+		"both CLIENT_SECRET and CLIENT_SECRET_FILE are set",
+		"flag -database-max-open-conns",
+		"logging.format",
+		"cache.lru_size",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidateCollectsAllErrors(t *testing.T) {
+	cfg := defaults()
+	cfg.GeniusConfig.Token = "token"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "database.host") {
+		t.Fatalf("Validate() of defaults = %v, want missing Postgres host", err)
+	}
+
+	cfg.Database.Host, cfg.Database.User, cfg.Database.Name = "db", "musplayer", "music"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+
+	cfg.App.Port = "0"
+	cfg.Logging.Level = "loud"
+	cfg.GraphQL.MaxDepth = 0
+	cfg.Database.Host = ""
+	err := cfg.Validate()
+	for _, want := range []string{"app.port", "logging.level", "graphql", "database.host"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() error does not mention %q: %v", want, err)
+		}
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	clearEnv(t)
+	stderr := os.Stderr
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	// Справку FlagSet печатает в os.Stderr
+	os.Stderr = devNull
+	defer func() { os.Stderr = stderr }()
+
+	if _, err := Load([]string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("Load(-h) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestUserTokensValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantStr string
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: map[string]string{}},
+		{
+			name:    "pairs with spaces",
+			in:      " bob = bob-token , alice=alice-token,",
+			want:    map[string]string{"alice-token": "alice", "bob-token": "bob"},
+			wantStr: "alice=alice-token,bob=bob-token",
+		},
+		{name: "missing token", in: "alice=", wantErr: true},
+		{name: "missing separator", in: "alice-token", wantErr: true},
+		{name: "duplicate token", in: "alice=same,bob=same", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			v := userTokensValue{&got}
+			err := v.Set(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Set(%q) = nil, want error", tt.in)
+				}
+				if strings.Contains(err.Error(), "alice-token") || strings.Contains(err.Error(), "same") {
+					t.Fatalf("Set(%q) error %q leaks the token", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q) error = %v", tt.in, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Set(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			for token, user := range tt.want {
+				if got[token] != user {
+					t.Fatalf("Set(%q) = %v, want %v", tt.in, got, tt.want)
+				}
+			}
+			if s := v.String(); s != tt.wantStr {
+				t.Fatalf("String() = %q, want %q", s, tt.wantStr)
+			}
+		})
+	}
+
+	t.Run("from env", func(t *testing.T) {
+		clearEnv(t)
+		validEnv(t)
+		t.Setenv("LISTENS_USER_TOKENS", "alice=alice-token")
+		cfg, err := Load(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Listens.UserTokens["alice-token"] != "alice" {
+			t.Fatalf("Listens.UserTokens = %v, want alice-token mapped to alice", cfg.Listens.UserTokens)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"musPlayer/models"
+	"strings"
+	"time"
+)
+
+// field описывает один параметр конфигурации и все источники, из которых он может быть задан
+type field struct {
+	key    string // путь в файле конфигурации, например "database.host"
+	env    string // имя переменной окружения; переменная с суффиксом _FILE содержит путь к файлу со значением
+	usage  string
+	secret bool
+	value  value
+}
+
+// flagName возвращает имя флага командной строки: "database.host" -> "database-host"
+func (f field) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(f.key)
+}
+
+// fields возвращает таблицу параметров, привязанную к полям c
+func (c *Config) fields() []field {
+	return []field{
+		{key: "app.port", env: "APP_PORT", usage: "HTTP port", value: stringValue{&c.App.Port}},
+		{key: "app.readiness_timeout", env: "READINESS_TIMEOUT", usage: "timeout of a single readiness check", value: durationValue{&c.App.ReadinessTimeout}},
+		{key: "app.shutdown_timeout", env: "SHUTDOWN_TIMEOUT", usage: "deadline for graceful shutdown", value: durationValue{&c.App.ShutdownTimeout}},
+		{key: "app.drain_delay", env: "DRAIN_DELAY", usage: "how long /readyz fails before the listeners close on shutdown; part of the shutdown deadline", value: durationValue{&c.App.DrainDelay}},
+
+		{key: "database.driver", env: "DB_DRIVER", usage: "storage: postgres, sqlite or memory; sqlite and memory keep only the song catalog", value: stringValue{&c.Database.Driver}},
+		{key: "database.path", env: "DB_PATH", usage: "SQLite database file, :memory: for a temporary database", value: stringValue{&c.Database.Path}},
+		{key: "database.host", env: "DB_HOST", usage: "Postgres host", value: stringValue{&c.Database.Host}},
+		{key: "database.port", env: "DB_PORT", usage: "Postgres port", value: stringValue{&c.Database.Port}},
+		{key: "database.user", env: "DB_USER", usage: "Postgres user", value: stringValue{&c.Database.User}},
+		{key: "database.password", env: "DB_PASSWORD", usage: "Postgres password", secret: true, value: stringValue{&c.Database.Password}},
+		{key: "database.name", env: "DB_NAME", usage: "Postgres database name", value: stringValue{&c.Database.Name}},
+		{key: "database.url", env: "DB_URL", usage: "Postgres connection string or postgres:// URL; overrides host, port, user, password, name and TLS settings", secret: true, value: stringValue{&c.Database.URL}},
+		{key: "database.sslmode", env: "DB_SSLMODE", usage: "Postgres TLS mode: disable, require, verify-ca or verify-full", value: stringValue{&c.Database.SSLMode}},
+		{key: "database.sslrootcert", env: "DB_SSLROOTCERT", usage: "CA certificate file for verify-ca and verify-full", value: stringValue{&c.Database.SSLRootCert}},
+		{key: "database.sslcert", env: "DB_SSLCERT", usage: "client certificate file", value: stringValue{&c.Database.SSLCert}},
+		{key: "database.sslkey", env: "DB_SSLKEY", usage: "client private key file", value: stringValue{&c.Database.SSLKey}},
+		{key: "database.pg_driver", env: "DB_PG_DRIVER", usage: "Postgres driver: pq or pgx", value: stringValue{&c.Database.PgDriver}},
+		{key: "database.statement_cache_size", env: "DB_STATEMENT_CACHE_SIZE", usage: "prepared statements cached per connection by pgx, 0 disables the cache", value: intValue{&c.Database.StatementCacheSize}},
+		{key: "database.statement_timeout", env: "DB_STATEMENT_TIMEOUT", usage: "Postgres statement_timeout for every connection, 0 disables it", value: durationValue{&c.Database.StatementTimeout}},
+		{key: "database.connect_timeout", env: "DB_CONNECT_TIMEOUT", usage: "timeout of a single connection attempt", value: durationValue{&c.Database.ConnectTimeout}},
+		{key: "database.max_open_conns", env: "DB_MAX_OPEN_CONNS", usage: "maximum open connections, 0 for unlimited", value: intValue{&c.Database.MaxOpenConns}},
+		{key: "database.max_idle_conns", env: "DB_MAX_IDLE_CONNS", usage: "maximum idle connections kept in the pool", value: intValue{&c.Database.MaxIdleConns}},
+		{key: "database.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", usage: "close connections older than this, 0 keeps them forever", value: durationValue{&c.Database.ConnMaxLifetime}},
+		{key: "database.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", usage: "close connections idle longer than this, 0 keeps them", value: durationValue{&c.Database.ConnMaxIdleTime}},
+		{key: "database.connect_attempts", env: "DB_CONNECT_ATTEMPTS", usage: "connection attempts at startup", value: intValue{&c.Database.ConnectAttempts}},
+		{key: "database.connect_retry_delay", env: "DB_CONNECT_RETRY_DELAY", usage: "delay after the first failed connection attempt, doubled after each next one", value: durationValue{&c.Database.ConnectRetryDelay}},
+
+		{key: "api.base_url", env: "API_BASE_URL", usage: "Genius API base URL", value: stringValue{&c.API.BaseURL}},
+		{key: "api.key", env: "API_KEY", usage: "key for admin and debug endpoints (X-API-Key header); the endpoints answer 404 while it is empty", secret: true, value: stringValue{&c.API.Key}},
+
+		{key: "logging.level", env: "LOG_LEVEL", usage: "log level", value: stringValue{&c.Logging.Level}},
+		{key: "logging.format", env: "LOG_FORMAT", usage: "log format: text or json", value: stringValue{&c.Logging.Format}},
+		{key: "logging.file", env: "LOG_FILE", usage: "log file path, empty to log to stdout only", value: stringValue{&c.Logging.File}},
+		{key: "logging.max_size_mb", env: "LOG_MAX_SIZE_MB", usage: "rotate the log file after this size, 0 to disable", value: intValue{&c.Logging.MaxSizeMB}},
+		{key: "logging.rotate_interval", env: "LOG_ROTATE_INTERVAL", usage: "rotate the log file after this interval, 0 to disable", value: durationValue{&c.Logging.RotateInterval}},
+		{key: "logging.max_backups", env: "LOG_MAX_BACKUPS", usage: "number of rotated log files to keep, 0 to keep all", value: intValue{&c.Logging.MaxBackups}},
+
+		{key: "genius.client_id", env: "CLIENT_ID", usage: "Genius OAuth client ID", value: stringValue{&c.GeniusConfig.ID}},
+		{key: "genius.client_secret", env: "CLIENT_SECRET", usage: "Genius OAuth client secret", secret: true, value: stringValue{&c.GeniusConfig.Secret}},
+		{key: "genius.token", env: "GENIUS_TOKEN", usage: "Genius access token used until OAuth authorization", secret: true, value: stringValue{&c.GeniusConfig.Token}},
+		{key: "genius.redirect_uri", env: "REDIRECT_URI", usage: "Genius OAuth redirect URI", value: stringValue{&c.GeniusConfig.RedirectURI}},
+		{key: "genius.auth_url", env: "GENIUS_AUTH_URL", usage: "Genius OAuth authorization URL", value: stringValue{&c.GeniusConfig.AuthURL}},
+		{key: "genius.token_url", env: "GENIUS_TOKEN_URL", usage: "Genius OAuth token URL", value: stringValue{&c.GeniusConfig.TokenURL}},
+		{key: "genius.scope", env: "GENIUS_SCOPE", usage: "Genius OAuth scope", value: stringValue{&c.GeniusConfig.Scope}},
+		{key: "genius.connect_timeout", env: "GENIUS_CONNECT_TIMEOUT", usage: "Genius connect and TLS handshake timeout", value: durationValue{&c.GeniusConfig.ConnectTimeout}},
+		{key: "genius.read_timeout", env: "GENIUS_READ_TIMEOUT", usage: "Genius response headers timeout", value: durationValue{&c.GeniusConfig.ReadTimeout}},
+		{key: "genius.request_timeout", env: "GENIUS_REQUEST_TIMEOUT", usage: "timeout of a single Genius request attempt including the body", value: durationValue{&c.GeniusConfig.RequestTimeout}},
+		{key: "genius.max_retries", env: "GENIUS_MAX_RETRIES", usage: "retries of idempotent Genius requests", value: intValue{&c.GeniusConfig.MaxRetries}},
+		{key: "genius.retry_base_delay", env: "GENIUS_RETRY_BASE_DELAY", usage: "initial retry backoff", value: durationValue{&c.GeniusConfig.RetryBaseDelay}},
+		{key: "genius.retry_max_delay", env: "GENIUS_RETRY_MAX_DELAY", usage: "maximum retry backoff and Retry-After wait", value: durationValue{&c.GeniusConfig.RetryMaxDelay}},
+		{key: "genius.breaker_threshold", env: "GENIUS_BREAKER_THRESHOLD", usage: "consecutive failures that open the circuit breaker, 0 to disable", value: intValue{&c.GeniusConfig.BreakerThreshold}},
+		{key: "genius.match_threshold", env: "GENIUS_MATCH_THRESHOLD", usage: "minimum score (0..1) to pick the best search candidate automatically", value: floatValue{&c.GeniusConfig.MatchThreshold}},
+		{key: "genius.breaker_cooldown", env: "GENIUS_BREAKER_COOLDOWN", usage: "how long the circuit breaker stays open", value: durationValue{&c.GeniusConfig.BreakerCooldown}},
+
+		{key: "cache.lru_size", env: "CACHE_LRU_SIZE", usage: "in-process cache size in entries, 0 to disable", value: intValue{&c.Cache.LRUSize}},
+		{key: "cache.search_ttl", env: "CACHE_SEARCH_TTL", usage: "TTL of cached Genius search results", value: durationValue{&c.Cache.SearchTTL}},
+		{key: "cache.lyrics_ttl", env: "CACHE_LYRICS_TTL", usage: "TTL of cached Genius lyrics pages", value: durationValue{&c.Cache.LyricsTTL}},
+		{key: "cache.analysis_ttl", env: "CACHE_ANALYSIS_TTL", usage: "TTL of cached lyrics analysis", value: durationValue{&c.Cache.AnalysisTTL}},
+		{key: "cache.cleanup_interval", env: "CACHE_CLEANUP_INTERVAL", usage: "how often expired cache rows are deleted", value: durationValue{&c.Cache.CleanupInterval}},
+
+		{key: "sync.enabled", env: "RESYNC_ENABLED", usage: "periodically resync stored songs with their source pages", value: boolValue{&c.Sync.Enabled}},
+		{key: "sync.interval", env: "RESYNC_INTERVAL", usage: "how often the refresher looks for outdated songs", value: durationValue{&c.Sync.Interval}},
+		{key: "sync.max_age", env: "RESYNC_MAX_AGE", usage: "songs not synced for this long are refreshed", value: durationValue{&c.Sync.MaxAge}},
+		{key: "sync.batch_size", env: "RESYNC_BATCH_SIZE", usage: "songs refreshed per cycle", value: intValue{&c.Sync.BatchSize}},
+		{key: "sync.request_delay", env: "RESYNC_REQUEST_DELAY", usage: "minimum delay between source requests", value: durationValue{&c.Sync.RequestDelay}},
+		{key: "similarity.refresh_interval", env: "SIMILARITY_REFRESH_INTERVAL", usage: "how often the similarity index picks up songs changed elsewhere", value: durationValue{&c.Similarity.RefreshInterval}},
+
+		{key: "listens.ingest_enabled", env: "LISTENS_INGEST_ENABLED", usage: "look up unmatched listened tracks on Genius and add them to the catalog", value: boolValue{&c.Listens.IngestEnabled}},
+		{key: "listens.ingest_interval", env: "LISTENS_INGEST_INTERVAL", usage: "how often the ingester processes unmatched tracks", value: durationValue{&c.Listens.IngestInterval}},
+		{key: "listens.ingest_batch_size", env: "LISTENS_INGEST_BATCH_SIZE", usage: "unmatched tracks processed per cycle", value: intValue{&c.Listens.IngestBatchSize}},
+		{key: "listens.ingest_request_delay", env: "LISTENS_INGEST_REQUEST_DELAY", usage: "minimum delay between Genius searches of the ingester", value: durationValue{&c.Listens.IngestRequestDelay}},
+		{key: "listens.user_tokens", env: "LISTENS_USER_TOKENS", usage: "ListenBrainz user tokens as user=token pairs separated by commas; clients send them in the Authorization: Token header", secret: true, value: userTokensValue{&c.Listens.UserTokens}},
+		{key: "listens.playing_now_ttl", env: "LISTENS_PLAYING_NOW_TTL", usage: "how long a playing_now track is shown when its duration is unknown", value: durationValue{&c.Listens.PlayingNowTTL}},
+
+		{key: "webhooks.enabled", env: "WEBHOOKS_ENABLED", usage: "deliver catalog events to webhook subscriptions", value: boolValue{&c.Webhooks.Enabled}},
+		{key: "webhooks.dispatch_interval", env: "WEBHOOKS_DISPATCH_INTERVAL", usage: "how often the dispatcher looks for due deliveries", value: durationValue{&c.Webhooks.DispatchInterval}},
+		{key: "webhooks.batch_size", env: "WEBHOOKS_BATCH_SIZE", usage: "deliveries sent per cycle", value: intValue{&c.Webhooks.BatchSize}},
+		{key: "webhooks.timeout", env: "WEBHOOKS_TIMEOUT", usage: "timeout of a single delivery request", value: durationValue{&c.Webhooks.Timeout}},
+		{key: "webhooks.max_attempts", env: "WEBHOOKS_MAX_ATTEMPTS", usage: "delivery attempts before a delivery is marked dead", value: intValue{&c.Webhooks.MaxAttempts}},
+		{key: "webhooks.retry_base_delay", env: "WEBHOOKS_RETRY_BASE_DELAY", usage: "delay before the first retry, doubled on every next one", value: durationValue{&c.Webhooks.RetryBaseDelay}},
+		{key: "webhooks.retry_max_delay", env: "WEBHOOKS_RETRY_MAX_DELAY", usage: "maximum delay between retries", value: durationValue{&c.Webhooks.RetryMaxDelay}},
+		{key: "webhooks.retention", env: "WEBHOOKS_RETENTION", usage: "how long events with finished deliveries are kept", value: durationValue{&c.Webhooks.Retention}},
+		{key: "webhooks.allow_private_targets", env: "WEBHOOKS_ALLOW_PRIVATE_TARGETS", usage: "allow webhook URLs on loopback, private and link-local addresses; for local development only", value: boolValue{&c.Webhooks.AllowPrivateTargets}},
+
+		{key: "stream.heartbeat_interval", env: "STREAM_HEARTBEAT_INTERVAL", usage: "how often idle live streams send a keep-alive", value: durationValue{&c.Stream.HeartbeatInterval}},
+		{key: "stream.buffer_size", env: "STREAM_BUFFER_SIZE", usage: "events buffered per live stream client before it is disconnected as too slow", value: intValue{&c.Stream.BufferSize}},
+		{key: "stream.resume_limit", env: "STREAM_RESUME_LIMIT", usage: "missed events replayed on reconnect with Last-Event-ID; beyond it the client gets a reset event", value: intValue{&c.Stream.ResumeLimit}},
+
+		{key: "changes.retention", env: "CHANGES_RETENTION", usage: "how long deleted songs stay in the change feed; older cursors must resync", value: durationValue{&c.Changes.Retention}},
+
+		{key: "graphql.introspection", env: "GRAPHQL_INTROSPECTION", usage: "allow schema introspection queries on /graphql", value: boolValue{&c.GraphQL.Introspection}},
+		{key: "graphql.max_depth", env: "GRAPHQL_MAX_DEPTH", usage: "maximum selection depth of a GraphQL query", value: intValue{&c.GraphQL.MaxDepth}},
+		{key: "graphql.max_complexity", env: "GRAPHQL_MAX_COMPLEXITY", usage: "maximum estimated GraphQL query cost: one per field, nested fields multiplied by list page size", value: intValue{&c.GraphQL.MaxComplexity}},
+
+		{key: "grpc.enabled", env: "GRPC_ENABLED", usage: "serve the gRPC API for internal consumers", value: boolValue{&c.GRPC.Enabled}},
+		{key: "grpc.port", env: "GRPC_PORT", usage: "gRPC port", value: stringValue{&c.GRPC.Port}},
+		{key: "grpc.reflection", env: "GRPC_REFLECTION", usage: "register the gRPC server reflection service", value: boolValue{&c.GRPC.Reflection}},
+	}
+}
+
+// defaults возвращает конфигурацию со значениями по умолчанию
+func defaults() Config {
+	var c Config
+	c.App.Port = "8080"
+	c.App.ReadinessTimeout = 2 * time.Second
+	c.App.ShutdownTimeout = 30 * time.Second
+	c.App.DrainDelay = 5 * time.Second
+	c.Database.Driver = models.DriverPostgres
+	c.Database.Path = "musplayer.db"
+	c.Database.Port = "5432"
+	c.Database.SSLMode = "disable"
+	c.Database.PgDriver = models.PgDriverPQ
+	c.Database.StatementCacheSize = 512
+	c.Database.StatementTimeout = 30 * time.Second
+	c.Database.ConnectTimeout = 5 * time.Second
+	c.Database.MaxOpenConns = 25
+	c.Database.MaxIdleConns = 10
+	c.Database.ConnMaxLifetime = 30 * time.Minute
+	c.Database.ConnMaxIdleTime = 5 * time.Minute
+	c.Database.ConnectAttempts = 5
+	c.Database.ConnectRetryDelay = time.Second
+	c.API.BaseURL = "https://api.genius.com"
+	c.Logging.Level = "info"
+	c.Logging.Format = "text"
+	c.Logging.File = "app.log"
+	c.Logging.MaxSizeMB = 100
+	c.Logging.RotateInterval = 24 * time.Hour
+	c.Logging.MaxBackups = 7
+	c.GeniusConfig.AuthURL = "https://api.genius.com/oauth/authorize"
+	c.GeniusConfig.TokenURL = "https://api.genius.com/oauth/token"
+	c.GeniusConfig.ConnectTimeout = 5 * time.Second
+	c.GeniusConfig.ReadTimeout = 10 * time.Second
+	c.GeniusConfig.RequestTimeout = 30 * time.Second
+	c.GeniusConfig.MaxRetries = 3
+	c.GeniusConfig.RetryBaseDelay = 200 * time.Millisecond
+	c.GeniusConfig.RetryMaxDelay = 5 * time.Second
+	c.GeniusConfig.BreakerThreshold = 5
+	c.GeniusConfig.BreakerCooldown = 30 * time.Second
+	c.GeniusConfig.MatchThreshold = 0.6
+	c.Cache.LRUSize = 1000
+	c.Cache.SearchTTL = 24 * time.Hour
+	c.Cache.LyricsTTL = 7 * 24 * time.Hour
+	c.Cache.AnalysisTTL = time.Hour
+	c.Cache.CleanupInterval = time.Hour
+	c.Sync.Enabled = true
+	c.Sync.Interval = time.Hour
+	c.Sync.MaxAge = 7 * 24 * time.Hour
+	c.Sync.BatchSize = 50
+	c.Sync.RequestDelay = 2 * time.Second
+	c.Similarity.RefreshInterval = time.Minute
+	c.Listens.IngestEnabled = true
+	c.Listens.IngestInterval = 5 * time.Minute
+	c.Listens.IngestBatchSize = 50
+	c.Listens.IngestRequestDelay = 2 * time.Second
+	c.Listens.PlayingNowTTL = 10 * time.Minute
+	c.Webhooks.Enabled = true
+	c.Webhooks.DispatchInterval = 5 * time.Second
+	c.Webhooks.BatchSize = 50
+	c.Webhooks.Timeout = 10 * time.Second
+	c.Webhooks.MaxAttempts = 10
+	c.Webhooks.RetryBaseDelay = 30 * time.Second
+	c.Webhooks.RetryMaxDelay = 6 * time.Hour
+	c.Webhooks.Retention = 7 * 24 * time.Hour
+	c.Stream.HeartbeatInterval = 15 * time.Second
+	c.Stream.BufferSize = 256
+	c.Stream.ResumeLimit = 1000
+	c.Changes.Retention = 30 * 24 * time.Hour
+	c.GraphQL.Introspection = true
+	c.GraphQL.MaxDepth = 10
+	c.GraphQL.MaxComplexity = 5000
+	c.GRPC.Enabled = true
+	c.GRPC.Port = "9090"
+	c.GRPC.Reflection = true
+	return c
+}
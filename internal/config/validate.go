@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	"net/url"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки разом
+func (c *Config) Validate() error {
+	var errs []error
+
+	if err := validatePort(c.App.Port); err != nil {
+		errs = append(errs, fmt.Errorf("app.port: %w", err))
+	}
+	if c.App.ReadinessTimeout <= 0 {
+		errs = append(errs, errors.New("app.readiness_timeout: must be positive"))
+	}
+	if c.App.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("app.shutdown_timeout: must be positive"))
+	}
+	if c.App.DrainDelay < 0 || c.App.DrainDelay >= c.App.ShutdownTimeout {
+		errs = append(errs, errors.New("app.drain_delay: must be non-negative and less than app.shutdown_timeout"))
+	}
+
+	switch c.Database.Driver {
+	case models.DriverPostgres:
+		errs = append(errs, validatePostgres(c.Database)...)
+	case models.DriverSQLite:
+		if c.Database.Path == "" {
+			errs = append(errs, errors.New("database.path: is required for the sqlite driver"))
+		}
+	case models.DriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("database.driver: unknown driver %q, want postgres, sqlite or memory", c.Database.Driver))
+	}
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, fmt.Errorf("logging.level: %w", err))
+	}
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		errs = append(errs, fmt.Errorf("logging.format: %q must be text or json", c.Logging.Format))
+	}
+	if c.Logging.MaxSizeMB < 0 || c.Logging.MaxBackups < 0 || c.Logging.RotateInterval < 0 {
+		errs = append(errs, errors.New("logging: max_size_mb, rotate_interval and max_backups must not be negative"))
+	}
+
+	if err := validateURL(c.API.BaseURL, true); err != nil {
+		errs = append(errs, fmt.Errorf("api.base_url: %w", err))
+	}
+	if err := validateURL(c.GeniusConfig.AuthURL, true); err != nil {
+		errs = append(errs, fmt.Errorf("genius.auth_url: %w", err))
+	}
+	if err := validateURL(c.GeniusConfig.TokenURL, true); err != nil {
+		errs = append(errs, fmt.Errorf("genius.token_url: %w", err))
+	}
+	if err := validateURL(c.GeniusConfig.RedirectURI, false); err != nil {
+		errs = append(errs, fmt.Errorf("genius.redirect_uri: %w", err))
+	}
+	g := c.GeniusConfig
+	if g.ConnectTimeout <= 0 || g.ReadTimeout <= 0 || g.RequestTimeout <= 0 {
+		errs = append(errs, errors.New("genius: connect_timeout, read_timeout and request_timeout must be positive"))
+	}
+	if g.MaxRetries < 0 || g.BreakerThreshold < 0 {
+		errs = append(errs, errors.New("genius: max_retries and breaker_threshold must not be negative"))
+	}
+	if g.RetryBaseDelay <= 0 || g.RetryMaxDelay < g.RetryBaseDelay {
+		errs = append(errs, errors.New("genius: retry_base_delay must be positive and not exceed retry_max_delay"))
+	}
+	if g.MatchThreshold < 0 || g.MatchThreshold > 1 {
+		errs = append(errs, errors.New("genius.match_threshold: must be between 0 and 1"))
+	}
+	// Без готового токена сервис должен уметь пройти OAuth-авторизацию
+	if c.GeniusConfig.Token == "" {
+		if c.GeniusConfig.ID == "" || c.GeniusConfig.Secret == "" || c.GeniusConfig.RedirectURI == "" {
+			errs = append(errs, errors.New("genius: either token or client_id, client_secret and redirect_uri are required"))
+		}
+	}
+
+	if c.Cache.LRUSize < 0 {
+		errs = append(errs, errors.New("cache.lru_size: must not be negative"))
+	}
+	if c.Cache.SearchTTL <= 0 || c.Cache.LyricsTTL <= 0 || c.Cache.AnalysisTTL <= 0 || c.Cache.CleanupInterval <= 0 {
+		errs = append(errs, errors.New("cache: search_ttl, lyrics_ttl, analysis_ttl and cleanup_interval must be positive"))
+	}
+	if c.Sync.Interval <= 0 || c.Sync.MaxAge <= 0 || c.Sync.RequestDelay <= 0 || c.Sync.BatchSize <= 0 {
+		errs = append(errs, errors.New("sync: interval, max_age, request_delay and batch_size must be positive"))
+	}
+	if c.Similarity.RefreshInterval <= 0 {
+		errs = append(errs, errors.New("similarity: refresh_interval must be positive"))
+	}
+	if c.Listens.IngestInterval <= 0 || c.Listens.IngestBatchSize <= 0 || c.Listens.IngestRequestDelay <= 0 || c.Listens.PlayingNowTTL <= 0 {
+		errs = append(errs, errors.New("listens: ingest_interval, ingest_batch_size, ingest_request_delay and playing_now_ttl must be positive"))
+	}
+	if c.Webhooks.DispatchInterval <= 0 || c.Webhooks.BatchSize <= 0 || c.Webhooks.Timeout <= 0 || c.Webhooks.MaxAttempts <= 0 ||
+		c.Webhooks.RetryBaseDelay <= 0 || c.Webhooks.RetryMaxDelay < c.Webhooks.RetryBaseDelay || c.Webhooks.Retention <= 0 {
+		errs = append(errs, errors.New("webhooks: dispatch_interval, batch_size, timeout, max_attempts, retry_base_delay and retention must be positive, retry_max_delay not less than retry_base_delay"))
+	}
+	if c.Stream.HeartbeatInterval <= 0 || c.Stream.BufferSize <= 0 || c.Stream.ResumeLimit <= 0 {
+		errs = append(errs, errors.New("stream: heartbeat_interval, buffer_size and resume_limit must be positive"))
+	}
+	if c.Changes.Retention <= 0 {
+		errs = append(errs, errors.New("changes: retention must be positive"))
+	}
+	if c.GraphQL.MaxDepth <= 0 || c.GraphQL.MaxComplexity <= 0 {
+		errs = append(errs, errors.New("graphql: max_depth and max_complexity must be positive"))
+	}
+	if c.GRPC.Enabled {
+		if err := validatePort(c.GRPC.Port); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.port: %w", err))
+		} else if c.GRPC.Port == c.App.Port {
+			errs = append(errs, errors.New("grpc.port: must differ from app.port"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validatePostgres проверяет параметры подключения к Postgres. Если задан database.url, адрес и TLS берутся из него
+func validatePostgres(db models.DatabaseConfig) []error {
+	var errs []error
+	if db.URL == "" {
+		if db.Host == "" {
+			errs = append(errs, errors.New("database.host: is required"))
+		}
+		if err := validatePort(db.Port); err != nil {
+			errs = append(errs, fmt.Errorf("database.port: %w", err))
+		}
+		if db.User == "" {
+			errs = append(errs, errors.New("database.user: is required"))
+		}
+		if db.Name == "" {
+			errs = append(errs, errors.New("database.name: is required"))
+		}
+		// lib/pq, через который работает и LISTEN, поддерживает только эти режимы
+		switch db.SSLMode {
+		case "disable", "require", "verify-ca", "verify-full":
+		default:
+			errs = append(errs, fmt.Errorf("database.sslmode: %q must be disable, require, verify-ca or verify-full", db.SSLMode))
+		}
+		if (db.SSLCert == "") != (db.SSLKey == "") {
+			errs = append(errs, errors.New("database.sslcert and database.sslkey: must be set together"))
+		}
+	}
+
+	if db.PgDriver != models.PgDriverPQ && db.PgDriver != models.PgDriverPgx {
+		errs = append(errs, fmt.Errorf("database.pg_driver: %q must be pq or pgx", db.PgDriver))
+	}
+	if db.StatementCacheSize < 0 || db.MaxOpenConns < 0 || db.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database: statement_cache_size, max_open_conns and max_idle_conns must not be negative"))
+	}
+	if db.MaxOpenConns > 0 && db.MaxIdleConns > db.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns: must not exceed max_open_conns"))
+	}
+	if db.StatementTimeout < 0 || db.ConnMaxLifetime < 0 || db.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("database: statement_timeout, conn_max_lifetime and conn_max_idle_time must not be negative"))
+	}
+	if db.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("database.connect_timeout: must be positive"))
+	}
+	if db.ConnectAttempts < 1 {
+		errs = append(errs, errors.New("database.connect_attempts: must be at least 1"))
+	}
+	if db.ConnectRetryDelay <= 0 {
+		errs = append(errs, errors.New("database.connect_retry_delay: must be positive"))
+	}
+	return errs
+}
+
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%q is not a valid port", port)
+	}
+	return nil
+}
+
+func validateURL(raw string, required bool) error {
+	if raw == "" {
+		if required {
+			return errors.New("is required")
+		}
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute URL", raw)
+	}
+	return nil
+}
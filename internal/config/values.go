@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// value — типизированное значение параметра конфигурации, которое задается из строки
+type value interface {
+	Set(s string) error
+	String() string
+	Get() interface{}
+}
+
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(s string) error { *v.p = s; return nil }
+func (v stringValue) String() string     { return *v.p }
+func (v stringValue) Get() interface{}   { return *v.p }
+
+type intValue struct{ p *int }
+
+func (v intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v.p = n
+	return nil
+}
+func (v intValue) String() string   { return strconv.Itoa(*v.p) }
+func (v intValue) Get() interface{} { return *v.p }
+
+type floatValue struct{ p *float64 }
+
+func (v floatValue) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", s)
+	}
+	*v.p = f
+	return nil
+}
+func (v floatValue) String() string   { return strconv.FormatFloat(*v.p, 'g', -1, 64) }
+func (v floatValue) Get() interface{} { return *v.p }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", s)
+	}
+	*v.p = b
+	return nil
+}
+func (v boolValue) String() string   { return strconv.FormatBool(*v.p) }
+func (v boolValue) Get() interface{} { return *v.p }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration", s)
+	}
+	*v.p = d
+	return nil
+}
+func (v durationValue) String() string   { return v.p.String() }
+func (v durationValue) Get() interface{} { return v.p.String() }
+
+// userTokensValue — список пар «пользователь=токен» через запятую; в карте ключ — токен
+type userTokensValue struct{ p *map[string]string }
+
+func (v userTokensValue) Set(s string) error {
+	tokens := make(map[string]string)
+	for i, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		user, token, ok := strings.Cut(pair, "=")
+		user, token = strings.TrimSpace(user), strings.TrimSpace(token)
+		if !ok || user == "" || token == "" {
+			// Сама запись может содержать токен, поэтому в ошибку попадает только ее номер
+			return fmt.Errorf("entry %d is not user=token", i+1)
+		}
+		if _, dup := tokens[token]; dup {
+			return fmt.Errorf("token of user %q is already used by another user", user)
+		}
+		tokens[token] = user
+	}
+	*v.p = tokens
+	return nil
+}
+
+func (v userTokensValue) String() string {
+	pairs := make([]string, 0, len(*v.p))
+	for token, user := range *v.p {
+		pairs = append(pairs, user+"="+token)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+func (v userTokensValue) Get() interface{} { return v.String() }
@@ -0,0 +1,570 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Сложность запроса оценивается до выполнения: каждое поле стоит 1, стоимость вложенных полей умножается
+// на размер страницы списка (аргумент first или значение по умолчанию из listSizes).
+// graphql-go такой оценки не дает, а его парсер запросов внутренний, поэтому здесь свой небольшой разбор.
+// Запрос к этому моменту уже прошел валидацию схемой, так что разбор не проверяет типы и не сообщает подробностей.
+// Заодно считается глубина: проверка глубины в graphql-go обходит каждый фрагмент только при первом упоминании
+// и пропускает более глубокое повторное
+
+var errUnsupportedQuery = errors.New("query cannot be analyzed")
+
+// queryComplexity возвращает оценку сложности и глубину операции operationName
+func queryComplexity(query, operationName string, variables map[string]interface{}, listSizes map[string]int) (complexity, depth int, err error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return 0, 0, err
+	}
+	p := &queryParser{tokens: tokens}
+	doc, err := p.document()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	op, ok := doc.operation(operationName)
+	if !ok {
+		// Неизвестную операцию отклонит graphql-go
+		return 0, 0, nil
+	}
+	a := &complexityAnalyzer{
+		doc:       doc,
+		op:        op,
+		variables: variables,
+		listSizes: listSizes,
+		visiting:  map[string]bool{},
+		fragments: map[string]selectionCost{},
+	}
+	c, err := a.cost(op.selections)
+	if err != nil {
+		return 0, 0, err
+	}
+	if c.cost > math.MaxInt32 {
+		return math.MaxInt32, c.depth, nil
+	}
+	return int(c.cost), c.depth, nil
+}
+
+// selectionCost — оценка набора полей: стоимость и глубина самой длинной цепочки вложенных полей
+type selectionCost struct {
+	cost  float64
+	depth int
+}
+
+type complexityAnalyzer struct {
+	doc       *queryDocument
+	op        *queryOperation
+	variables map[string]interface{}
+	listSizes map[string]int
+	visiting  map[string]bool // фрагменты на текущем пути, защита от циклов
+	// Оценки фрагментов: без них цепочка фрагментов, каждый из которых дважды ссылается на следующий,
+	// разбиралась бы экспоненциально долго
+	fragments map[string]selectionCost
+}
+
+func (a *complexityAnalyzer) cost(sels []querySelection) (selectionCost, error) {
+	var total selectionCost
+	for _, sel := range sels {
+		var c selectionCost
+		var err error
+		switch {
+		case sel.spread != "":
+			c, err = a.fragment(sel.spread)
+		case sel.field == "":
+			// Встроенный фрагмент
+			c, err = a.cost(sel.children)
+		default:
+			c, err = a.cost(sel.children)
+			c = selectionCost{cost: 1 + a.multiplier(sel)*c.cost, depth: 1 + c.depth}
+		}
+		if err != nil {
+			return selectionCost{}, err
+		}
+		total.cost += c.cost
+		if c.depth > total.depth {
+			total.depth = c.depth
+		}
+	}
+	return total, nil
+}
+
+// fragment оценивает фрагмент один раз на операцию; first из переменных в пределах операции не меняется
+func (a *complexityAnalyzer) fragment(name string) (selectionCost, error) {
+	if c, ok := a.fragments[name]; ok {
+		return c, nil
+	}
+	frag, ok := a.doc.fragments[name]
+	if !ok || a.visiting[name] {
+		return selectionCost{}, errUnsupportedQuery
+	}
+	a.visiting[name] = true
+	c, err := a.cost(frag)
+	delete(a.visiting, name)
+	if err != nil {
+		return selectionCost{}, err
+	}
+	a.fragments[name] = c
+	return c, nil
+}
+
+// multiplier — сколько раз будут разрешены вложенные поля списка
+func (a *complexityAnalyzer) multiplier(sel querySelection) float64 {
+	if len(sel.children) == 0 {
+		return 1
+	}
+	// Без значения first действует значение по умолчанию из схемы
+	if arg, ok := sel.args["first"]; ok {
+		if n, ok := a.number(arg); ok {
+			return math.Max(n, 1)
+		}
+	}
+	if n, ok := a.listSizes[sel.field]; ok {
+		return float64(n)
+	}
+	return 1
+}
+
+func (a *complexityAnalyzer) number(arg queryValue) (float64, bool) {
+	if arg.variable == "" {
+		return arg.number, arg.isNumber
+	}
+	v, ok := a.variables[arg.variable]
+	if !ok {
+		if d, ok := a.op.defaults[arg.variable]; ok {
+			return d.number, d.isNumber
+		}
+		return 0, false
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+type queryDocument struct {
+	operations []*queryOperation
+	fragments  map[string][]querySelection
+}
+
+func (d *queryDocument) operation(name string) (*queryOperation, bool) {
+	if name == "" {
+		if len(d.operations) == 1 {
+			return d.operations[0], true
+		}
+		return nil, false
+	}
+	for _, op := range d.operations {
+		if op.name == name {
+			return op, true
+		}
+	}
+	return nil, false
+}
+
+type queryOperation struct {
+	name       string
+	defaults   map[string]*queryValue // значения переменных по умолчанию
+	selections []querySelection
+}
+
+// querySelection — поле, встроенный фрагмент (field пустое) или ссылка на фрагмент (spread)
+type querySelection struct {
+	field    string
+	spread   string
+	args     map[string]queryValue
+	children []querySelection
+}
+
+// queryValue хранит только то, что нужно для оценки: число или имя переменной
+type queryValue struct {
+	variable string
+	number   float64
+	isNumber bool
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenNumber
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// tokenize разбивает запрос на лексемы GraphQL; запятые, пробелы и комментарии пропускаются
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "\ufeff"):
+			i += len("\ufeff")
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, token{tokenPunct, "..."})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, token{tokenPunct, string(c)})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || isLetter(src[i]) || isDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenName, src[start:i]})
+		case c == '-' || isDigit(c):
+			start := i
+			i++
+			for i < len(src) && (isDigit(src[i]) || isLetter(src[i]) || strings.IndexByte(".+-", src[i]) >= 0) {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i]})
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(strings.ReplaceAll(src[i+3:], `\"""`, "    "), `"""`)
+			if end < 0 {
+				return nil, errUnsupportedQuery
+			}
+			tokens = append(tokens, token{tokenString, ""})
+			i += 3 + end + 3
+		case c == '"':
+			i++
+			for i < len(src) && src[i] != '"' {
+				if src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(src) {
+				return nil, errUnsupportedQuery
+			}
+			tokens = append(tokens, token{tokenString, ""})
+			i++
+		default:
+			return nil, errUnsupportedQuery
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token { return p.tokens[p.pos] }
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *queryParser) is(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.value == value
+}
+
+func (p *queryParser) expect(value string) error {
+	if !p.is(value) {
+		return fmt.Errorf("%w: expected %q", errUnsupportedQuery, value)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *queryParser) name() (string, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return "", errUnsupportedQuery
+	}
+	return t.value, nil
+}
+
+func (p *queryParser) document() (*queryDocument, error) {
+	doc := &queryDocument{fragments: map[string][]querySelection{}}
+	for p.peek().kind != tokenEOF {
+		if p.is("{") {
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &queryOperation{selections: sels})
+			continue
+		}
+
+		keyword, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		switch keyword {
+		case "query", "mutation", "subscription":
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case "fragment":
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			// on Type
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if err := p.directives(); err != nil {
+				return nil, err
+			}
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.fragments[name] = sels
+		default:
+			return nil, errUnsupportedQuery
+		}
+	}
+	return doc, nil
+}
+
+func (p *queryParser) operation() (*queryOperation, error) {
+	op := &queryOperation{defaults: map[string]*queryValue{}}
+	if p.peek().kind == tokenName {
+		op.name = p.next().value
+	}
+	if p.is("(") {
+		p.next()
+		for !p.is(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if err := p.typeRef(); err != nil {
+				return nil, err
+			}
+			if p.is("=") {
+				p.next()
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				op.defaults[name] = &v
+			}
+			if err := p.directives(); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+	}
+	if err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = sels
+	return op, nil
+}
+
+func (p *queryParser) typeRef() error {
+	if p.is("[") {
+		p.next()
+		if err := p.typeRef(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("!") {
+		p.next()
+	}
+	return nil
+}
+
+func (p *queryParser) selectionSet() ([]querySelection, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []querySelection
+	for !p.is("}") {
+		if p.peek().kind == tokenEOF {
+			return nil, errUnsupportedQuery
+		}
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	p.next()
+	return sels, nil
+}
+
+func (p *queryParser) selection() (querySelection, error) {
+	var sel querySelection
+	if p.is("...") {
+		p.next()
+		if t := p.peek(); t.kind == tokenName && t.value != "on" {
+			sel.spread = p.next().value
+			return sel, p.directives()
+		}
+		if t := p.peek(); t.kind == tokenName && t.value == "on" {
+			p.next()
+			if _, err := p.name(); err != nil {
+				return sel, err
+			}
+		}
+		if err := p.directives(); err != nil {
+			return sel, err
+		}
+		children, err := p.selectionSet()
+		sel.children = children
+		return sel, err
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return sel, err
+	}
+	if p.is(":") {
+		p.next()
+		if name, err = p.name(); err != nil {
+			return sel, err
+		}
+	}
+	sel.field = name
+	if p.is("(") {
+		if sel.args, err = p.arguments(); err != nil {
+			return sel, err
+		}
+	}
+	if err := p.directives(); err != nil {
+		return sel, err
+	}
+	if p.is("{") {
+		if sel.children, err = p.selectionSet(); err != nil {
+			return sel, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *queryParser) arguments() (map[string]queryValue, error) {
+	args := map[string]queryValue{}
+	p.next()
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = v
+	}
+	p.next()
+	return args, nil
+}
+
+func (p *queryParser) directives() error {
+	for p.is("@") {
+		p.next()
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if p.is("(") {
+			if _, err := p.arguments(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value разбирает значение аргумента; списки и объекты пропускаются целиком
+func (p *queryParser) value() (queryValue, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenPunct && t.value == "$":
+		name, err := p.name()
+		return queryValue{variable: name}, err
+	case t.kind == tokenNumber:
+		n, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return queryValue{}, errUnsupportedQuery
+		}
+		return queryValue{number: n, isNumber: true}, nil
+	case t.kind == tokenName || t.kind == tokenString:
+		return queryValue{}, nil
+	case t.kind == tokenPunct && t.value == "[":
+		for !p.is("]") {
+			if _, err := p.value(); err != nil {
+				return queryValue{}, err
+			}
+		}
+		p.next()
+		return queryValue{}, nil
+	case t.kind == tokenPunct && t.value == "{":
+		for !p.is("}") {
+			if _, err := p.name(); err != nil {
+				return queryValue{}, err
+			}
+			if err := p.expect(":"); err != nil {
+				return queryValue{}, err
+			}
+			if _, err := p.value(); err != nil {
+				return queryValue{}, err
+			}
+		}
+		p.next()
+		return queryValue{}, nil
+	}
+	return queryValue{}, errUnsupportedQuery
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// fragmentChain — n фрагментов, каждый из которых дважды ссылается на следующий
+func fragmentChain(n int) string {
+	var b strings.Builder
+	b.WriteString("{ song(id: 1) { ...F0 } }\n")
+	for i := 0; i < n-1; i++ {
+		fmt.Fprintf(&b, "fragment F%d on Song { ...F%d ...F%d }\n", i, i+1, i+1)
+	}
+	fmt.Fprintf(&b, "fragment F%d on Song { id }\n", n-1)
+	return b.String()
+}
+
+func TestQueryComplexity(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]interface{}
+		want      int
+		wantDepth int
+		wantErr   bool
+	}{
+		{name: "scalar fields", query: `{ song(id: 1) { id group } }`, want: 3, wantDepth: 2},
+		{name: "default list size", query: `{ songs { nodes { id } } }`, want: 41, wantDepth: 3},
+		{name: "nested lists", query: `{ songs(first: 5) { nodes { id similar { score } } } }`, want: 66, wantDepth: 4},
+		{name: "negative first", query: `{ songs(first: -5) { nodes { id } } }`, want: 3, wantDepth: 3},
+		{name: "exponent", query: `{ songs(first: 1e3) { nodes { id } } }`, want: 2001, wantDepth: 3},
+		{name: "fraction with exponent", query: `{ songs(first: 2.5E1) { nodes { id } } }`, want: 51, wantDepth: 3},
+		{
+			name:  "cost is capped",
+			query: `{ songs(first: 1e300) { nodes { similar(first: 1e300) { score } } } }`,
+			want:  math.MaxInt32, wantDepth: 4,
+		},
+		{
+			name:  "block string with quotes, braces and escaped delimiter",
+			query: `{ songs(filter: {group: """say "hi" { \""" } # not a comment"""}, first: 2) { nodes { id } } }`,
+			want:  5, wantDepth: 3,
+		},
+		{
+			name:  "string with escapes and comments",
+			query: "# { songs { nodes { id } } }\n{ songs(filter: {song: \"a \\\" } # b\"}, first: 1) { nodes { id } } } # }",
+			want:  3, wantDepth: 3,
+		},
+		{
+			name:  "aliases are counted separately",
+			query: `{ a: songs(first: 10) { nodes { id } } b: songs { nodes { id } } c: song(id: 1) { id } }`,
+			want:  64, wantDepth: 3,
+		},
+		{
+			name:      "first from variables",
+			query:     `query Q($n: Int) { songs(first: $n) { nodes { id } } }`,
+			variables: map[string]interface{}{"n": float64(50)},
+			want:      101, wantDepth: 3,
+		},
+		{name: "variable not passed", query: `query Q($n: Int) { songs(first: $n) { nodes { id } } }`, want: 41, wantDepth: 3},
+		{
+			name:      "variable is null",
+			query:     `query Q($n: Int) { songs(first: $n) { nodes { id } } }`,
+			variables: map[string]interface{}{"n": nil},
+			want:      41, wantDepth: 3,
+		},
+		{name: "variable default", query: `query Q($n: Int = 3) { songs(first: $n) { nodes { id } } }`, want: 7, wantDepth: 3},
+		{
+			name:      "variable overrides default",
+			query:     `query Q($n: Int = 3) { songs(first: $n) { nodes { id } } }`,
+			variables: map[string]interface{}{"n": 4},
+			want:      9, wantDepth: 3,
+		},
+		{name: "fragment", query: `{ songs { nodes { ...F } } } fragment F on Song { id group }`, want: 61, wantDepth: 3},
+		{
+			name:      "fragment before operation uses operation variables",
+			query:     `fragment S on Song { similar(first: $k) { song { id } } } query Q($k: Int) { song(id: 1) { ...S } }`,
+			variables: map[string]interface{}{"k": float64(2)},
+			want:      6, wantDepth: 4,
+		},
+		{
+			name:  "inline fragments",
+			query: `{ songs { nodes { ... on Song { id } ... @include(if: true) { group } } } }`,
+			want:  61, wantDepth: 3,
+		},
+		{
+			name:  "fragment reused deeper",
+			query: `{ song(id: 1) { ...A artist { songs(first: 1) { nodes { ...A } } } } } fragment A on Song { artist { name } }`,
+			want:  8, wantDepth: 6,
+		},
+		{name: "fragment chain is analyzed once per fragment", query: fragmentChain(40), want: math.MaxInt32, wantDepth: 2},
+		{
+			name:    "fragment cycle",
+			query:   `{ song(id: 1) { ...A } } fragment A on Song { id ...B } fragment B on Song { ...A }`,
+			wantErr: true,
+		},
+		{name: "fragment spreads itself", query: `{ song(id: 1) { ...A } } fragment A on Song { id ...A }`, wantErr: true},
+		{name: "unknown fragment", query: `{ song(id: 1) { ...Missing } }`, wantErr: true},
+		{
+			name:      "named operation",
+			query:     `query A { song(id: 1) { id } } query B { songs { nodes { id } } }`,
+			operation: "B",
+			want:      41, wantDepth: 3,
+		},
+		{name: "ambiguous operation", query: `query A { song(id: 1) { id } } query B { songs { nodes { id } } }`},
+		{name: "unknown operation", query: `query A { song(id: 1) { id } }`, operation: "C"},
+		{name: "mutation", query: `mutation { deleteSong(id: 1) }`, want: 1, wantDepth: 1},
+		{name: "unterminated string", query: `{ song(id: "1) { id } }`, wantErr: true},
+		{name: "unterminated block string", query: `{ songs(filter: {group: """x}) { nodes { id } } }`, wantErr: true},
+		{name: "unclosed selection set", query: `{ songs { nodes { id } }`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, depth, err := queryComplexity(tt.query, tt.operation, tt.variables, listSizes)
+			if tt.wantErr {
+				if !errors.Is(err, errUnsupportedQuery) {
+					t.Fatalf("queryComplexity() error = %v, want errUnsupportedQuery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("queryComplexity() error = %v", err)
+			}
+			if got != tt.want || depth != tt.wantDepth {
+				t.Fatalf("queryComplexity() = %d, depth %d, want %d, depth %d", got, depth, tt.want, tt.wantDepth)
+			}
+		})
+	}
+}
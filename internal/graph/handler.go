@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Размеры списков по умолчанию для оценки сложности, совпадают со значениями first по умолчанию в схеме
+var listSizes = map[string]int{"songs": 20, "similar": 10}
+
+const (
+	maxRequestSize = 1 << 20
+	// Резолверы, ждущие загрузчик, занимают слот параллельности, поэтому слотов должно хватать на всю страницу списка
+	maxParallelism = maxPageSize
+)
+
+// Handler выполняет запросы GraphQL, присланные POST с телом {query, operationName, variables}
+type Handler struct {
+	schema        *graphql.Schema
+	services      *servicePostgres.Service
+	maxDepth      int
+	maxComplexity int
+}
+
+func NewHandler(services *servicePostgres.Service, cfg models.GraphQLConfig) *Handler {
+	opts := []graphql.SchemaOpt{
+		graphql.MaxDepth(cfg.MaxDepth),
+		graphql.MaxParallelism(maxParallelism),
+		graphql.Logger(panicReporter{}),
+		graphql.PanicHandler(panicReporter{}),
+	}
+	if !cfg.Introspection {
+		opts = append(opts, graphql.DisableIntrospection())
+	}
+	return &Handler{
+		schema:        graphql.MustParseSchema(schema, NewResolver(services), opts...),
+		services:      services,
+		maxDepth:      cfg.MaxDepth,
+		maxComplexity: cfg.MaxComplexity,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeResponse(w, http.StatusBadRequest, errorResponse(&gqlError{message: "invalid request body", code: "BAD_REQUEST"}))
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeResponse(w, http.StatusBadRequest, errorResponse(&gqlError{message: "query is required", code: "BAD_REQUEST"}))
+		return
+	}
+
+	// Валидация проверяет и глубину запроса
+	if errs := h.schema.ValidateWithVariables(req.Query, req.Variables); len(errs) > 0 {
+		writeResponse(w, http.StatusOK, &graphql.Response{Errors: errs})
+		return
+	}
+
+	complexity, depth, err := queryComplexity(req.Query, req.OperationName, req.Variables, listSizes)
+	if err != nil {
+		logger.FromContext(r.Context()).Warnf("GraphQL query complexity analysis failed: %v", err)
+		writeResponse(w, http.StatusOK, errorResponse(&gqlError{message: "query cannot be analyzed", code: "QUERY_TOO_COMPLEX"}))
+		return
+	}
+	// Валидация graphql-go не замечает фрагмент, повторно использованный глубже первого упоминания
+	if h.maxDepth > 0 && depth > h.maxDepth {
+		writeResponse(w, http.StatusOK, errorResponse(&gqlError{
+			message: fmt.Sprintf("query depth %d exceeds the limit of %d", depth, h.maxDepth),
+			code:    "QUERY_TOO_DEEP",
+		}))
+		return
+	}
+	if complexity > h.maxComplexity {
+		writeResponse(w, http.StatusOK, errorResponse(&gqlError{
+			message: fmt.Sprintf("query complexity %d exceeds the limit of %d", complexity, h.maxComplexity),
+			code:    "QUERY_TOO_COMPLEX",
+		}))
+		return
+	}
+	logger.FromContext(r.Context()).Debugf("GraphQL operation %q, complexity %d, depth %d", req.OperationName, complexity, depth)
+
+	ctx := withLoaders(r.Context(), newLoaders(r.Context(), h.services))
+	writeResponse(w, http.StatusOK, h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
+
+func errorResponse(err *gqlError) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{{Message: err.message, Extensions: err.Extensions()}}}
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, resp *graphql.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// panicReporter пишет паники резолверов в лог запроса, а клиенту отдает только internal error
+type panicReporter struct{}
+
+func (panicReporter) LogPanic(ctx context.Context, value interface{}) {
+	logger.FromContext(ctx).Errorf("GraphQL resolver panicked: %v\n%s", value, debug.Stack())
+}
+
+func (panicReporter) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	return &gqlerrors.QueryError{Message: errInternal.message, Extensions: errInternal.Extensions()}
+}
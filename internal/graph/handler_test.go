@@ -0,0 +1,225 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"musPlayer/pkg/tfidf"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// songServiceStub отдает песни 1..10 и запоминает идентификаторы каждого вызова GetSongs; nil — список без фильтра
+type songServiceStub struct {
+	servicePostgres.SongService
+
+	mu    sync.Mutex
+	calls [][]int
+}
+
+func (s *songServiceStub) GetSongs(_ context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, filter.IDs)
+	s.mu.Unlock()
+
+	var songs []models.Song
+	if filter.IDs != nil {
+		for _, id := range filter.IDs {
+			if id <= 10 {
+				songs = append(songs, testSong(id))
+			}
+		}
+		return songs, nil
+	}
+	for id := offset + 1; id <= 10 && len(songs) < limit; id++ {
+		songs = append(songs, testSong(id))
+	}
+	return songs, nil
+}
+
+// GetSongsByArtists отдает каждому исполнителю песню 1
+func (s *songServiceStub) GetSongsByArtists(_ context.Context, names []string, limit, offset int) (map[string][]models.Song, error) {
+	result := make(map[string][]models.Song, len(names))
+	for _, name := range names {
+		result[name] = []models.Song{testSong(1)}
+	}
+	return result, nil
+}
+
+func testSong(id int) models.Song {
+	return models.Song{ID: id, GroupName: "Group " + strconv.Itoa(id), SongName: "Song " + strconv.Itoa(id)}
+}
+
+// similarityStub считает пакетные вызовы; песне i похожи песни i+5 и i%5+6
+type similarityStub struct {
+	servicePostgres.SimilarityService
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *similarityStub) SimilarSongsBatch(_ context.Context, songIDs []int, limit int, _ tfidf.GroupFilter) (map[int][]models.SimilarSong, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+
+	result := make(map[int][]models.SimilarSong, len(songIDs))
+	for _, id := range songIDs {
+		similar := []models.SimilarSong{{Song: models.Song{ID: id + 5}, Score: 0.9}, {Song: models.Song{ID: id%5 + 6}, Score: 0.5}}
+		if len(similar) > limit {
+			similar = similar[:limit]
+		}
+		result[id] = similar
+	}
+	return result, nil
+}
+
+func newTestHandler(cfg models.GraphQLConfig) (*Handler, *songServiceStub, *similarityStub) {
+	songs, similarity := &songServiceStub{}, &similarityStub{}
+	return NewHandler(&servicePostgres.Service{SongService: songs, SimilarityService: similarity}, cfg), songs, similarity
+}
+
+type testResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func execQuery(t *testing.T, h http.Handler, query string) testResponse {
+	t.Helper()
+	body, err := json.Marshal(request{Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+
+	var resp testResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHandlerDepthLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantErr  bool
+		wantCode string
+	}{
+		{name: "within limit", query: `{ song(id: 1) { artist { songs(first: 1) { nodes { id } } } } }`},
+		{name: "too deep", query: `{ song(id: 1) { artist { songs { nodes { artist { name } } } } } }`, wantErr: true},
+		{
+			name:    "too deep through fragment",
+			query:   `{ song(id: 1) { ...Deep } } fragment Deep on Song { artist { songs { nodes { artist { name } } } } }`,
+			wantErr: true,
+		},
+		{
+			// graphql-go проверяет фрагмент только при первом упоминании, более глубокое ловит анализ сложности
+			name: "fragment reused deeper",
+			query: `{ song(id: 1) { ...A artist { songs(first: 1) { nodes { ...A } } } } }
+                    fragment A on Song { artist { name } }`,
+			wantErr:  true,
+			wantCode: "QUERY_TOO_DEEP",
+		},
+	}
+	h, _, _ := newTestHandler(models.GraphQLConfig{MaxDepth: 5, MaxComplexity: 5000})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := execQuery(t, h, tt.query)
+			if !tt.wantErr {
+				if len(resp.Errors) > 0 || resp.Data["song"] == nil {
+					t.Fatalf("response = %+v, want data without errors", resp)
+				}
+				return
+			}
+			if len(resp.Errors) == 0 || resp.Data != nil {
+				t.Fatalf("response = %+v, want only errors", resp)
+			}
+			if tt.wantCode != "" && resp.Errors[0].Extensions["code"] != tt.wantCode {
+				t.Fatalf("error = %+v, want code %s", resp.Errors[0], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandlerIntrospection(t *testing.T) {
+	const query = `{ __schema { queryType { name } } }`
+	for _, enabled := range []bool{true, false} {
+		t.Run(strconv.FormatBool(enabled), func(t *testing.T) {
+			h, _, _ := newTestHandler(models.GraphQLConfig{Introspection: enabled, MaxDepth: 10, MaxComplexity: 5000})
+			resp := execQuery(t, h, query)
+			schema, _ := resp.Data["__schema"].(map[string]interface{})
+			if enabled != (schema != nil) {
+				t.Fatalf("introspection enabled %v: response = %+v", enabled, resp)
+			}
+		})
+	}
+}
+
+func TestHandlerBatchesLoads(t *testing.T) {
+	// Широкое окно, чтобы ключи всех элементов списка успели попасть в один пакет на любой машине
+	wait := batchWait
+	batchWait = 50 * time.Millisecond
+	t.Cleanup(func() { batchWait = wait })
+
+	t.Run("similar songs of a list", func(t *testing.T) {
+		h, songs, similarity := newTestHandler(models.GraphQLConfig{MaxDepth: 10, MaxComplexity: 5000})
+		resp := execQuery(t, h, `{ songs(first: 5) { nodes { id similar(first: 2) { song { id group } } } } }`)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("errors: %+v", resp.Errors)
+		}
+
+		// Список песен и один пакет карточек похожих песен; песни списка уже в кеше
+		if len(songs.calls) != 2 || songs.calls[0] != nil {
+			t.Fatalf("GetSongs calls = %v, want the list and one batch", songs.calls)
+		}
+		batch := append([]int(nil), songs.calls[1]...)
+		sort.Ints(batch)
+		if !reflect.DeepEqual(batch, []int{6, 7, 8, 9, 10}) {
+			t.Fatalf("batch = %v, want similar songs 6..10 once each", batch)
+		}
+		if similarity.calls != 1 {
+			t.Fatalf("SimilarSongsBatch calls = %d, want 1", similarity.calls)
+		}
+
+		nodes := resp.Data["songs"].(map[string]interface{})["nodes"].([]interface{})
+		first := nodes[0].(map[string]interface{})["similar"].([]interface{})
+		if len(nodes) != 5 || len(first) != 2 {
+			t.Fatalf("songs = %+v, want 5 songs with 2 similar each", resp.Data["songs"])
+		}
+	})
+
+	t.Run("aliased songs", func(t *testing.T) {
+		h, songs, _ := newTestHandler(models.GraphQLConfig{MaxDepth: 10, MaxComplexity: 5000})
+		resp := execQuery(t, h, `{ a: song(id: 1) { id } b: song(id: 2) { id } missing: song(id: 404) { id } }`)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("errors: %+v", resp.Errors)
+		}
+		if len(songs.calls) != 1 {
+			t.Fatalf("GetSongs calls = %v, want one batch", songs.calls)
+		}
+		batch := append([]int(nil), songs.calls[0]...)
+		sort.Ints(batch)
+		if !reflect.DeepEqual(batch, []int{1, 2, 404}) {
+			t.Fatalf("batch = %v, want 1, 2, 404", batch)
+		}
+		if resp.Data["a"] == nil || resp.Data["b"] == nil || resp.Data["missing"] != nil {
+			t.Fatalf("data = %+v, want a and b, missing = null", resp.Data)
+		}
+	})
+}
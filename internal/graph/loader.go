@@ -0,0 +1,264 @@
+package graph
+
+import (
+	"context"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"musPlayer/pkg/tfidf"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// batchWait — сколько загрузчик ждет остальные ключи, прежде чем сходить в сервис.
+// Резолверы полей списка graphql-go запускает параллельно, так что за это время успевают прийти ключи всех элементов.
+// Тесты увеличивают окно, чтобы пакеты не разваливались на загруженной машине
+var batchWait = 2 * time.Millisecond
+
+// maxBatchSize ограничивает число ключей в одном вызове сервиса
+const maxBatchSize = 500
+
+// loader собирает ключи, запрошенные резолверами почти одновременно, и загружает их одним вызовом fetch.
+// Результаты, включая ошибки, кешируются до конца запроса
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	entries map[K]*loaderEntry[V]
+	pending []K
+}
+
+type loaderEntry[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		ctx:     ctx,
+		fetch:   fetch,
+		entries: make(map[K]*loaderEntry[V]),
+	}
+}
+
+// load возвращает значение ключа; found ложно, если fetch его не вернул
+func (l *loader[K, V]) load(key K) (V, bool, error) {
+	e := l.loadMany([]K{key})[0]
+	<-e.done
+	return e.value, e.found, e.err
+}
+
+// loadAll загружает несколько ключей одним пакетом; отсутствующие ключи пропускаются
+func (l *loader[K, V]) loadAll(keys []K) ([]V, error) {
+	values := make([]V, 0, len(keys))
+	for _, e := range l.loadMany(keys) {
+		<-e.done
+		if e.err != nil {
+			return nil, e.err
+		}
+		if e.found {
+			values = append(values, e.value)
+		}
+	}
+	return values, nil
+}
+
+func (l *loader[K, V]) loadMany(keys []K) []*loaderEntry[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]*loaderEntry[V], len(keys))
+	for i, key := range keys {
+		e, ok := l.entries[key]
+		if !ok {
+			e = &loaderEntry[V]{done: make(chan struct{})}
+			l.entries[key] = e
+			l.pending = append(l.pending, key)
+			switch {
+			case len(l.pending) >= maxBatchSize:
+				go l.dispatch()
+			case len(l.pending) == 1:
+				time.AfterFunc(batchWait, l.dispatch)
+			}
+		}
+		entries[i] = e
+	}
+	return entries
+}
+
+// prime кладет в кеш уже известное значение
+func (l *loader[K, V]) prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.entries[key]; ok {
+		return
+	}
+	e := &loaderEntry[V]{done: make(chan struct{}), value: value, found: true}
+	close(e.done)
+	l.entries[key] = e
+}
+
+// forget убирает ключ из кеша, следующая загрузка снова обратится к сервису
+func (l *loader[K, V]) forget(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.entries[key]; ok {
+		select {
+		case <-e.done:
+			delete(l.entries, key)
+		default:
+			// Ключ ждет загрузки, его результат понадобится уже ожидающим резолверам
+		}
+	}
+}
+
+func (l *loader[K, V]) dispatch() {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	entries := make([]*loaderEntry[V], len(keys))
+	for i, key := range keys {
+		entries[i] = l.entries[key]
+	}
+	l.mu.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+	defer func() {
+		// Резолверы ждут закрытия done, поэтому паника в fetch не должна оставить их висеть
+		if p := recover(); p != nil {
+			logger.FromContext(l.ctx).Errorf("GraphQL batch load panicked: %v\n%s", p, debug.Stack())
+			for _, e := range entries {
+				e.err = errInternal
+				close(e.done)
+			}
+		}
+	}()
+	values, err := l.fetch(l.ctx, keys)
+	for i, key := range keys {
+		e := entries[i]
+		e.value, e.found = values[key]
+		e.err = err
+		close(e.done)
+	}
+}
+
+type lyricsKey struct {
+	songID   int
+	pageSize int
+	query    servicePostgres.LyricsQuery
+}
+
+type artistSongsKey struct {
+	name          string
+	limit, offset int
+}
+
+type similarKey struct {
+	songID int
+	limit  int
+	filter tfidf.GroupFilter
+}
+
+// loaders — загрузчики одного запроса GraphQL
+type loaders struct {
+	songs       *loader[int, models.Song]
+	lyrics      *loader[lyricsKey, []string]
+	artistSongs *loader[artistSongsKey, []models.Song]
+	similar     *loader[similarKey, []models.SimilarSong]
+}
+
+func newLoaders(ctx context.Context, services *servicePostgres.Service) *loaders {
+	return &loaders{
+		songs: newLoader(ctx, func(ctx context.Context, ids []int) (map[int]models.Song, error) {
+			songs, err := services.GetSongs(ctx, models.SongFilter{IDs: ids}, len(ids), 0)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int]models.Song, len(songs))
+			for _, song := range songs {
+				byID[song.ID] = song
+			}
+			return byID, nil
+		}),
+
+		// Ключи с одинаковыми параметрами уходят в сервис одним вызовом
+		lyrics: newLoader(ctx, func(ctx context.Context, keys []lyricsKey) (map[lyricsKey][]string, error) {
+			groups := make(map[lyricsKey][]int)
+			for _, k := range keys {
+				group := lyricsKey{pageSize: k.pageSize, query: k.query}
+				groups[group] = append(groups[group], k.songID)
+			}
+			result := make(map[lyricsKey][]string, len(keys))
+			for group, ids := range groups {
+				pages, err := services.GetSongTextPages(ctx, ids, group.pageSize, group.query)
+				if err != nil {
+					return nil, err
+				}
+				for id, p := range pages {
+					key := group
+					key.songID = id
+					result[key] = p
+				}
+			}
+			return result, nil
+		}),
+
+		artistSongs: newLoader(ctx, func(ctx context.Context, keys []artistSongsKey) (map[artistSongsKey][]models.Song, error) {
+			groups := make(map[artistSongsKey][]string)
+			for _, k := range keys {
+				group := artistSongsKey{limit: k.limit, offset: k.offset}
+				groups[group] = append(groups[group], k.name)
+			}
+			result := make(map[artistSongsKey][]models.Song, len(keys))
+			for group, names := range groups {
+				songs, err := services.GetSongsByArtists(ctx, names, group.limit, group.offset)
+				if err != nil {
+					return nil, err
+				}
+				for name, list := range songs {
+					key := group
+					key.name = name
+					result[key] = list
+				}
+			}
+			return result, nil
+		}),
+
+		similar: newLoader(ctx, func(ctx context.Context, keys []similarKey) (map[similarKey][]models.SimilarSong, error) {
+			groups := make(map[similarKey][]int)
+			for _, k := range keys {
+				group := similarKey{limit: k.limit, filter: k.filter}
+				groups[group] = append(groups[group], k.songID)
+			}
+			result := make(map[similarKey][]models.SimilarSong, len(keys))
+			for group, ids := range groups {
+				similar, err := services.SimilarSongsBatch(ctx, ids, group.limit, group.filter)
+				if err != nil {
+					return nil, err
+				}
+				for id, list := range similar {
+					key := group
+					key.songID = id
+					result[key] = list
+				}
+			}
+			return result, nil
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
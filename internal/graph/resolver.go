@@ -0,0 +1,522 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/tfidf"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+const (
+	// maxPageSize ограничивает first у списков песен и похожих песен
+	maxPageSize = 100
+	// maxLyricsPageSize ограничивает размер страницы текста
+	maxLyricsPageSize = 10000
+)
+
+// gqlError — ошибка, которую клиент видит как есть; код попадает в extensions
+type gqlError struct {
+	message string
+	code    string
+}
+
+func (e *gqlError) Error() string { return e.message }
+
+func (e *gqlError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+var (
+	errInternal     = &gqlError{message: "internal error", code: "INTERNAL"}
+	errSongNotFound = &gqlError{message: "song not found", code: "NOT_FOUND"}
+)
+
+func badInput(format string, args ...interface{}) error {
+	return &gqlError{message: fmt.Sprintf(format, args...), code: "BAD_USER_INPUT"}
+}
+
+// publicError переводит ошибку сервиса в ответ клиенту; неожиданные ошибки пишутся в лог и не раскрываются
+func publicError(ctx context.Context, err error) error {
+	var gerr *gqlError
+	switch {
+	case errors.As(err, &gerr):
+		return gerr
+	case errors.Is(err, servicePostgres.ErrInvalidSort), errors.Is(err, servicePostgres.ErrInvalidTag):
+		return badInput("%s", err.Error())
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		return errSongNotFound
+	case errors.Is(err, servicePostgres.ErrIndexNotReady):
+		return &gqlError{message: "similarity index is loading, try again later", code: "UNAVAILABLE"}
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+	logger.FromContext(ctx).Errorf("GraphQL resolver failed: %v", err)
+	return errInternal
+}
+
+func parseID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil || n <= 0 {
+		return 0, badInput("invalid song id %q", string(id))
+	}
+	return n, nil
+}
+
+func songID(id int) graphql.ID {
+	return graphql.ID(strconv.Itoa(id))
+}
+
+// Курсор — непрозрачная для клиента позиция в списке
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor *string) (int, error) {
+	if cursor == nil {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return 0, badInput("invalid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "offset:"))
+	if err != nil || offset < 0 || !strings.HasPrefix(string(raw), "offset:") {
+		return 0, badInput("invalid cursor")
+	}
+	return offset + 1, nil
+}
+
+func pageSize(first int32) (int, error) {
+	if first < 0 || first > maxPageSize {
+		return 0, badInput("first must be between 0 and %d", maxPageSize)
+	}
+	return int(first), nil
+}
+
+// Resolver — корень схемы: запросы и мутации поверх servicePostgres.Service
+type Resolver struct {
+	services *servicePostgres.Service
+}
+
+func NewResolver(services *servicePostgres.Service) *Resolver {
+	return &Resolver{services: services}
+}
+
+func (r *Resolver) Song(ctx context.Context, args struct{ ID graphql.ID }) (*songResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	song, found, err := loadersFrom(ctx).songs.load(id)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &songResolver{song: song}, nil
+}
+
+type songFilterInput struct {
+	Group    *string
+	Song     *string
+	TagsAll  *[]string
+	TagsAny  *[]string
+	TagsNone *[]string
+}
+
+func (f *songFilterInput) filter() models.SongFilter {
+	var filter models.SongFilter
+	if f == nil {
+		return filter
+	}
+	if f.Group != nil {
+		filter.Group = *f.Group
+	}
+	if f.Song != nil {
+		filter.Song = *f.Song
+	}
+	if f.TagsAll != nil {
+		filter.TagsAll = *f.TagsAll
+	}
+	if f.TagsAny != nil {
+		filter.TagsAny = *f.TagsAny
+	}
+	if f.TagsNone != nil {
+		filter.TagsNone = *f.TagsNone
+	}
+	return filter
+}
+
+func (r *Resolver) Songs(ctx context.Context, args struct {
+	Filter *songFilterInput
+	Sort   *string
+	First  int32
+	After  *string
+}) (*songConnectionResolver, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+	filter := args.Filter.filter()
+	if args.Sort != nil {
+		filter.Sort = *args.Sort
+	}
+
+	// Лишняя песня показывает, есть ли следующая страница
+	songs, err := r.services.GetSongs(ctx, filter, limit+1, offset)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return newSongConnection(ctx, songs, limit, offset), nil
+}
+
+func (r *Resolver) Artist(args struct{ Name string }) (*artistResolver, error) {
+	name := strings.TrimSpace(args.Name)
+	if name == "" {
+		return nil, badInput("artist name is required")
+	}
+	return &artistResolver{name: name}, nil
+}
+
+type addSongInput struct {
+	Group       string
+	Song        string
+	Text        *string
+	ReleaseDate *string
+	Album       *string
+	Link        *string
+}
+
+func (r *Resolver) AddSong(ctx context.Context, args struct{ Input addSongInput }) (*songResolver, error) {
+	in := args.Input
+	if strings.TrimSpace(in.Group) == "" || strings.TrimSpace(in.Song) == "" {
+		return nil, badInput("group and song are required")
+	}
+	id, err := r.services.AddSong(ctx, postgresrepo.AddSongParams{
+		GroupName:   in.Group,
+		SongName:    in.Song,
+		Text:        deref(in.Text),
+		ReleaseDate: deref(in.ReleaseDate),
+		Album:       deref(in.Album),
+		Link:        deref(in.Link),
+	})
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return r.reloadSong(ctx, id)
+}
+
+type updateSongInput struct {
+	Group       *string
+	Song        *string
+	Text        *string
+	ReleaseDate *string
+}
+
+func (r *Resolver) UpdateSong(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updateSongInput
+}) (*songResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Изменение собирается поверх текущего состояния песни, а не копии из кеша запроса
+	songs := loadersFrom(ctx).songs
+	songs.forget(id)
+	song, found, err := songs.load(id)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	if !found {
+		return nil, errSongNotFound
+	}
+
+	upd := models.SongUpdateParams{ID: id, GroupName: song.GroupName, SongName: song.SongName, ReleaseDate: song.ReleaseDate, Text: song.Text}
+	in := args.Input
+	if in.Group != nil {
+		upd.GroupName = *in.Group
+	}
+	if in.Song != nil {
+		upd.SongName = *in.Song
+	}
+	if in.Text != nil {
+		upd.Text = *in.Text
+	}
+	if in.ReleaseDate != nil {
+		upd.ReleaseDate = *in.ReleaseDate
+	}
+	if strings.TrimSpace(upd.GroupName) == "" || strings.TrimSpace(upd.SongName) == "" {
+		return nil, badInput("group and song must not be empty")
+	}
+
+	if err := r.services.UpdateSong(ctx, upd); err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return r.reloadSong(ctx, id)
+}
+
+func (r *Resolver) DeleteSong(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return "", err
+	}
+	songs := loadersFrom(ctx).songs
+	songs.forget(id)
+	if _, found, err := songs.load(id); err != nil {
+		return "", publicError(ctx, err)
+	} else if !found {
+		return "", errSongNotFound
+	}
+
+	if err := r.services.DeleteSong(ctx, int64(id)); err != nil {
+		return "", publicError(ctx, err)
+	}
+	songs.forget(id)
+	return args.ID, nil
+}
+
+// reloadSong читает песню заново после изменения, минуя кеш запроса
+func (r *Resolver) reloadSong(ctx context.Context, id int) (*songResolver, error) {
+	songs := loadersFrom(ctx).songs
+	songs.forget(id)
+	song, found, err := songs.load(id)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	if !found {
+		return nil, errSongNotFound
+	}
+	return &songResolver{song: song}, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type songResolver struct {
+	song models.Song
+}
+
+func (r *songResolver) ID() graphql.ID       { return songID(r.song.ID) }
+func (r *songResolver) Group() string        { return r.song.GroupName }
+func (r *songResolver) Song() string         { return r.song.SongName }
+func (r *songResolver) ReleaseDate() *string { return optional(r.song.ReleaseDate) }
+func (r *songResolver) Album() *string       { return optional(r.song.Album) }
+func (r *songResolver) Link() *string        { return optional(r.song.Link) }
+func (r *songResolver) RatingAvg() *float64  { return r.song.RatingAvg }
+func (r *songResolver) RatingCount() int32   { return int32(r.song.RatingCount) }
+
+func (r *songResolver) Tags() []string {
+	if r.song.Tags == nil {
+		return []string{}
+	}
+	return r.song.Tags
+}
+
+func (r *songResolver) Artist() *artistResolver {
+	return &artistResolver{name: r.song.GroupName}
+}
+
+func (r *songResolver) Lyrics(ctx context.Context, args struct {
+	Page     int32
+	PageSize int32
+	Lang     *string
+	Version  *string
+}) (*lyricsPageResolver, error) {
+	page, size := int(args.Page), int(args.PageSize)
+	if page <= 0 {
+		return nil, badInput("page must be positive")
+	}
+	if size <= 0 || size > maxLyricsPageSize {
+		return nil, badInput("pageSize must be between 1 and %d", maxLyricsPageSize)
+	}
+
+	key := lyricsKey{songID: r.song.ID, pageSize: size, query: servicePostgres.LyricsQuery{Lang: deref(args.Lang), Version: deref(args.Version)}}
+	pages, found, err := loadersFrom(ctx).lyrics.load(key)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	if !found || page > len(pages) {
+		return nil, nil
+	}
+	return &lyricsPageResolver{pages: pages, page: page, size: size}, nil
+}
+
+func (r *songResolver) Similar(ctx context.Context, args struct {
+	First  int32
+	Artist string
+}) ([]*similarSongResolver, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	filter := tfidf.AnyGroup
+	switch args.Artist {
+	case "SAME":
+		filter = tfidf.SameGroup
+	case "DIFFERENT":
+		filter = tfidf.OtherGroup
+	}
+
+	l := loadersFrom(ctx)
+	similar, _, err := l.similar.load(similarKey{songID: r.song.ID, limit: limit, filter: filter})
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+
+	// Похожие песни приходят без текстов и тегов, полные карточки загружаются пакетом
+	ids := make([]int, len(similar))
+	for i, s := range similar {
+		ids[i] = s.ID
+	}
+	songs, err := l.songs.loadAll(ids)
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	byID := make(map[int]models.Song, len(songs))
+	for _, song := range songs {
+		byID[song.ID] = song
+	}
+
+	result := make([]*similarSongResolver, 0, len(similar))
+	for _, s := range similar {
+		if song, ok := byID[s.ID]; ok {
+			result = append(result, &similarSongResolver{score: s.Score, song: song})
+		}
+	}
+	return result, nil
+}
+
+type similarSongResolver struct {
+	score float64
+	song  models.Song
+}
+
+func (r *similarSongResolver) Score() float64      { return r.score }
+func (r *similarSongResolver) Song() *songResolver { return &songResolver{song: r.song} }
+
+type lyricsPageResolver struct {
+	pages []string
+	page  int
+	size  int
+}
+
+func (r *lyricsPageResolver) Page() int32       { return int32(r.page) }
+func (r *lyricsPageResolver) PageSize() int32   { return int32(r.size) }
+func (r *lyricsPageResolver) TotalPages() int32 { return int32(len(r.pages)) }
+func (r *lyricsPageResolver) HasNextPage() bool { return r.page < len(r.pages) }
+func (r *lyricsPageResolver) Text() string      { return r.pages[r.page-1] }
+
+type artistResolver struct {
+	name string
+}
+
+func (r *artistResolver) Name() string { return r.name }
+
+func (r *artistResolver) Songs(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*songConnectionResolver, error) {
+	limit, err := pageSize(args.First)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	songs, _, err := loadersFrom(ctx).artistSongs.load(artistSongsKey{name: r.name, limit: limit + 1, offset: offset})
+	if err != nil {
+		return nil, publicError(ctx, err)
+	}
+	return newSongConnection(ctx, songs, limit, offset), nil
+}
+
+// songConnectionResolver — страница песен; songs может содержать одну лишнюю песню, признак следующей страницы
+type songConnectionResolver struct {
+	songs   []models.Song
+	offset  int
+	hasNext bool
+}
+
+func newSongConnection(ctx context.Context, songs []models.Song, limit, offset int) *songConnectionResolver {
+	c := &songConnectionResolver{offset: offset}
+	if len(songs) > limit {
+		songs, c.hasNext = songs[:limit], true
+	}
+	c.songs = songs
+
+	// Песни из списков сразу попадают в кеш, song(id) в том же запросе их не перечитывает
+	l := loadersFrom(ctx)
+	for _, song := range songs {
+		l.songs.prime(song.ID, song)
+	}
+	return c
+}
+
+func (c *songConnectionResolver) Edges() []*songEdgeResolver {
+	edges := make([]*songEdgeResolver, len(c.songs))
+	for i, song := range c.songs {
+		edges[i] = &songEdgeResolver{cursor: encodeCursor(c.offset + i), song: song}
+	}
+	return edges
+}
+
+func (c *songConnectionResolver) Nodes() []*songResolver {
+	nodes := make([]*songResolver, len(c.songs))
+	for i, song := range c.songs {
+		nodes[i] = &songResolver{song: song}
+	}
+	return nodes
+}
+
+func (c *songConnectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNext: c.hasNext}
+	if len(c.songs) > 0 {
+		cursor := encodeCursor(c.offset + len(c.songs) - 1)
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+type songEdgeResolver struct {
+	cursor string
+	song   models.Song
+}
+
+func (e *songEdgeResolver) Cursor() string      { return e.cursor }
+func (e *songEdgeResolver) Node() *songResolver { return &songResolver{song: e.song} }
+
+type pageInfoResolver struct {
+	hasNext   bool
+	endCursor *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool  { return p.hasNext }
+func (p *pageInfoResolver) EndCursor() *string { return p.endCursor }
@@ -0,0 +1,45 @@
+package grpcServer
+
+import (
+	"context"
+	"errors"
+	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	"musPlayer/internal/servicePostgres"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError переводит ошибку сервиса в статус gRPC с теми же сообщениями, что отдает REST.
+// Неожиданные ошибки пишутся в лог и не раскрываются
+func statusError(ctx context.Context, err error) error {
+	var upstreamErr *geniusService.UpstreamError
+	switch {
+	case errors.Is(err, servicePostgres.ErrInvalidSort), errors.Is(err, servicePostgres.ErrInvalidTag):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, servicePostgres.ErrInvalidPage):
+		return status.Error(codes.InvalidArgument, "Invalid page number")
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		return status.Error(codes.NotFound, "Song not found")
+	case errors.Is(err, servicePostgres.ErrLyricsVersionNotFound):
+		return status.Error(codes.NotFound, "Lyrics version not found")
+	case errors.Is(err, geniusService.ErrNotFound):
+		return status.Error(codes.NotFound, "Song not found")
+	case errors.Is(err, geniusService.ErrInvalidURL):
+		return status.Error(codes.InvalidArgument, "genius_url must be a genius.com song page")
+	case errors.Is(err, geniusService.ErrCircuitOpen):
+		return status.Error(codes.Unavailable, "Genius is temporarily unavailable, try again later")
+	case errors.Is(err, geniusService.ErrRateLimited):
+		return status.Error(codes.Unavailable, "Genius rate limit exceeded, try again later")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.As(err, &upstreamErr):
+		logger.FromContext(ctx).Errorf("Genius request failed: %v", err)
+		return status.Error(codes.Unavailable, "Genius request failed")
+	}
+	logger.FromContext(ctx).Errorf("gRPC handler failed: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
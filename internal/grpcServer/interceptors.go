@@ -0,0 +1,109 @@
+package grpcServer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"musPlayer/internal/logger"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestContext кладет в контекст вызова логгер с полями request_id, user и route, как requestLogger в REST.
+// request_id и user берутся из метаданных x-request-id и x-user-id; request_id возвращается клиенту в заголовке ответа
+func requestContext(ctx context.Context, method string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := firstValue(md, "x-request-id")
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	fields := logrus.Fields{
+		"request_id": requestID,
+		"route":      method,
+	}
+	if user := firstValue(md, "x-user-id"); user != "" {
+		fields["user"] = user
+	}
+	return logger.WithFields(ctx, fields)
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func logCall(ctx context.Context, startTime time.Time, err error) {
+	logger.FromContext(ctx).WithFields(logrus.Fields{
+		"code":     status.Code(err).String(),
+		"duration": time.Since(startTime).String(),
+	}).Info("Request handled")
+}
+
+func unaryLogger(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	startTime := time.Now()
+	ctx = requestContext(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	logCall(ctx, startTime, err)
+	return resp, err
+}
+
+// loggedStream подменяет контекст потока на контекст с логгером вызова
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamLogger(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	startTime := time.Now()
+	ctx := requestContext(ss.Context(), info.FullMethod)
+	err := handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, startTime, err)
+	return err
+}
+
+// unaryRecoverer превращает панику обработчика в codes.Internal, чтобы она не уронила процесс
+func unaryRecoverer(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ctx, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func streamRecoverer(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = recovered(ss.Context(), p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func recovered(ctx context.Context, p interface{}) error {
+	logger.FromContext(ctx).Errorf("gRPC handler panicked: %v\n%s", p, debug.Stack())
+	return status.Error(codes.Internal, "internal error")
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,78 @@
+package grpcServer
+
+import (
+	"context"
+	"errors"
+	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	musplayerv1 "musPlayer/pkg/api/musplayer/v1"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server отдает API musplayer.v1 по gRPC на отдельном порту.
+// Методы вызывают тот же сервисный слой, что и REST
+type Server struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+}
+
+func NewServer(services *servicePostgres.Service, serviceGenius *geniusService.GeniusService, cfg models.GRPCConfig) *Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLogger, unaryRecoverer),
+		grpc.ChainStreamInterceptor(streamLogger, streamRecoverer),
+	)
+	musplayerv1.RegisterSongServiceServer(srv, &songServer{services: services, serviceGenius: serviceGenius})
+
+	// Пустое имя сервиса означает состояние сервера целиком
+	hs := health.NewServer()
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus(musplayerv1.SongService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	if cfg.Reflection {
+		reflection.Register(srv)
+	}
+	return &Server{grpcServer: srv, health: hs}
+}
+
+// Run принимает соединения на заданном порту до вызова Shutdown
+func (s *Server) Run(port string) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	logger.Logger.Infof("gRPC server is running on port %s", port)
+
+	// grpc.ErrServerStopped означает, что Shutdown вызван раньше Serve
+	if err := s.grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown переводит проверки здоровья в NOT_SERVING, перестает принимать вызовы и ждет текущие.
+// Если ctx истекает раньше, оставшиеся вызовы и потоки обрываются
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,212 @@
+package grpcServer
+
+import (
+	"context"
+	"errors"
+	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	musplayerv1 "musPlayer/pkg/api/musplayer/v1"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// exportBatchSize — сколько песен ExportSongs читает одним запросом
+const exportBatchSize = 500
+
+type songServer struct {
+	musplayerv1.UnimplementedSongServiceServer
+	services      *servicePostgres.Service
+	serviceGenius *geniusService.GeniusService
+}
+
+func (s *songServer) AddSong(ctx context.Context, req *musplayerv1.AddSongRequest) (*musplayerv1.AddSongResponse, error) {
+	if req.GetSong() == "" && req.GetGeniusId() == 0 && req.GetGeniusUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing song title, genius_id or genius_url")
+	}
+
+	var song *models.Song
+	var err error
+	switch {
+	case req.GetGeniusId() != 0:
+		song, err = s.serviceGenius.GetSongByID(ctx, int(req.GetGeniusId()))
+	case req.GetGeniusUrl() != "":
+		song, err = s.serviceGenius.GetSongByURL(ctx, req.GetGeniusUrl())
+	default:
+		song, err = s.serviceGenius.SearchSong(ctx, req.GetSong(), req.GetGroup())
+	}
+	if err != nil {
+		var ambiguous *geniusService.AmbiguousMatchError
+		if errors.As(err, &ambiguous) {
+			logger.FromContext(ctx).Infof("Ambiguous match for %q by %q: %d candidates", req.GetSong(), req.GetGroup(), len(ambiguous.Candidates))
+			return &musplayerv1.AddSongResponse{Result: &musplayerv1.AddSongResponse_Ambiguous{
+				Ambiguous: &musplayerv1.AmbiguousMatch{Candidates: candidatesToProto(ambiguous.Candidates)},
+			}}, nil
+		}
+		return nil, statusError(ctx, err)
+	}
+
+	// Песня уже загружена с Genius: сохраняем ее, даже если клиент отменил вызов
+	if _, err := s.services.AddSong(context.WithoutCancel(ctx), postgresrepo.AddSongParams{
+		SongId:      song.ID,
+		GroupName:   song.GroupName,
+		SongName:    song.SongName,
+		Text:        song.Text,
+		Link:        song.Link,
+		ReleaseDate: song.ReleaseDate,
+		Album:       song.Album,
+	}); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return &musplayerv1.AddSongResponse{Result: &musplayerv1.AddSongResponse_Song{Song: songToProto(*song)}}, nil
+}
+
+func (s *songServer) SearchSongs(ctx context.Context, req *musplayerv1.SearchSongsRequest) (*musplayerv1.SearchSongsResponse, error) {
+	if req.GetSong() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Missing song title")
+	}
+	candidates, err := s.serviceGenius.SearchCandidates(ctx, req.GetSong(), req.GetGroup())
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &musplayerv1.SearchSongsResponse{Candidates: candidatesToProto(candidates)}, nil
+}
+
+func (s *songServer) FilterSongs(req *musplayerv1.FilterSongsRequest, stream grpc.ServerStreamingServer[musplayerv1.Song]) error {
+	ctx := stream.Context()
+	if req.GetLimit() <= 0 || req.GetOffset() < 0 {
+		return status.Error(codes.InvalidArgument, "limit must be positive and offset must not be negative")
+	}
+
+	filter := songFilterFromProto(req.GetFilter())
+	filter.Sort = req.GetSort()
+	songs, err := s.services.GetSongs(ctx, filter, int(req.GetLimit()), int(req.GetOffset()))
+	if err != nil {
+		return statusError(ctx, err)
+	}
+	for _, song := range songs {
+		if err := stream.Send(songToProto(song)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportSongs читает песни пачками по id, поэтому выгрузка каталога не держит одну долгую транзакцию
+// и не пропускает песни, если каталог меняется во время выгрузки
+func (s *songServer) ExportSongs(req *musplayerv1.ExportSongsRequest, stream grpc.ServerStreamingServer[musplayerv1.Song]) error {
+	ctx := stream.Context()
+	filter := songFilterFromProto(req.GetFilter())
+	filter.Sort = "id"
+
+	var sent int
+	for {
+		songs, err := s.services.GetSongs(ctx, filter, exportBatchSize, 0)
+		if err != nil {
+			return statusError(ctx, err)
+		}
+		for _, song := range songs {
+			if err := stream.Send(songToProto(song)); err != nil {
+				return err
+			}
+		}
+		sent += len(songs)
+		if len(songs) < exportBatchSize {
+			logger.FromContext(ctx).Infof("Exported %d songs", sent)
+			return nil
+		}
+		filter.AfterID = songs[len(songs)-1].ID
+	}
+}
+
+func (s *songServer) GetSongText(ctx context.Context, req *musplayerv1.GetSongTextRequest) (*musplayerv1.GetSongTextResponse, error) {
+	pageSize, page := int(req.GetPageSize()), int(req.GetPage())
+	if pageSize == 0 {
+		pageSize = 100 // значение по умолчанию, как в REST
+	}
+	if page == 0 {
+		page = 1
+	}
+
+	text, err := s.services.GetSongText(ctx, int(req.GetId()), pageSize, page, servicePostgres.LyricsQuery{Lang: req.GetLang(), Version: req.GetVersion()})
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &musplayerv1.GetSongTextResponse{Text: text}, nil
+}
+
+func (s *songServer) UpdateSong(ctx context.Context, req *musplayerv1.UpdateSongRequest) (*musplayerv1.Song, error) {
+	id := int(req.GetId())
+	if err := s.services.UpdateSong(ctx, models.SongUpdateParams{
+		ID:          id,
+		GroupName:   req.GetGroup(),
+		SongName:    req.GetSong(),
+		ReleaseDate: req.GetReleaseDate(),
+		Text:        req.GetText(),
+	}); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	songs, err := s.services.GetSongs(ctx, models.SongFilter{IDs: []int{id}}, 1, 0)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+	if len(songs) == 0 {
+		// Песню удалили сразу после обновления
+		return nil, statusError(ctx, servicePostgres.ErrSongNotFound)
+	}
+	return songToProto(songs[0]), nil
+}
+
+func (s *songServer) DeleteSong(ctx context.Context, req *musplayerv1.DeleteSongRequest) (*musplayerv1.DeleteSongResponse, error) {
+	if err := s.services.DeleteSong(ctx, req.GetId()); err != nil {
+		return nil, statusError(ctx, err)
+	}
+	return &musplayerv1.DeleteSongResponse{}, nil
+}
+
+func songToProto(song models.Song) *musplayerv1.Song {
+	return &musplayerv1.Song{
+		Id:          int64(song.ID),
+		Group:       song.GroupName,
+		Song:        song.SongName,
+		ReleaseDate: song.ReleaseDate,
+		Album:       song.Album,
+		Text:        song.Text,
+		Link:        song.Link,
+		Tags:        song.Tags,
+		RatingAvg:   song.RatingAvg,
+		RatingCount: int64(song.RatingCount),
+	}
+}
+
+func songFilterFromProto(f *musplayerv1.SongFilter) models.SongFilter {
+	return models.SongFilter{
+		Group:    f.GetGroup(),
+		Song:     f.GetSong(),
+		TagsAll:  f.GetTagsAll(),
+		TagsAny:  f.GetTagsAny(),
+		TagsNone: f.GetTagsNone(),
+	}
+}
+
+func candidatesToProto(candidates []geniusService.Candidate) []*musplayerv1.Candidate {
+	result := make([]*musplayerv1.Candidate, len(candidates))
+	for i, c := range candidates {
+		result[i] = &musplayerv1.Candidate{
+			GeniusId:    int64(c.GeniusID),
+			Title:       c.Title,
+			Artist:      c.Artist,
+			ReleaseDate: c.ReleaseDate,
+			Url:         c.URL,
+			Score:       c.Score,
+			Penalties:   c.Penalties,
+		}
+	}
+	return result
+}
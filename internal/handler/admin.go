@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	"net/http"
+	"strings"
+)
+
+// LogLevelRequest задает уровень логирования
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// @Summary Текущий уровень логирования
+// @Tags admin
+// @Produce json
+// @Param X-API-Key header string false "Ключ администратора"
+// @Success 200 {object} LogLevelRequest
+// @Failure 401 {object} map[string]string
+// @Router /admin/log-level [get]
+func (h *Handler) getLogLevel(w http.ResponseWriter, r *http.Request) {
+	sendSuccessResponse(w, http.StatusOK, LogLevelRequest{Level: logger.Level()})
+}
+
+// @Summary Изменить уровень логирования
+// @Description Меняет уровень логирования без перезапуска сервиса
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string false "Ключ администратора"
+// @Param params body LogLevelRequest true "Новый уровень: trace, debug, info, warn, error"
+// @Success 200 {object} LogLevelRequest
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/log-level [put]
+func (h *Handler) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	var params LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := logger.SetLevel(params.Level); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid log level")
+		return
+	}
+
+	logger.FromContext(r.Context()).Infof("Log level set to %s", logger.Level())
+	sendSuccessResponse(w, http.StatusOK, LogLevelRequest{Level: logger.Level()})
+}
+
+// @Summary Очистить кеш Genius
+// @Description Удаляет записи кеша поиска и текстов Genius из памяти и из Postgres. Без prefix очищает весь кеш Genius
+// @Tags admin
+// @Produce json
+// @Param X-API-Key header string false "Ключ администратора"
+// @Param prefix query string false "Префикс ключа, например genius:search: или genius:lyrics:v2:https://genius.com/..."
+// @Success 200 {object} map[string]int64
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/cache [delete]
+func (h *Handler) purgeCache(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		prefix = geniusService.CachePrefix
+	}
+	if !strings.HasPrefix(prefix, geniusService.CachePrefix) {
+		h.handleError(w, r, fmt.Errorf("unsupported cache prefix %q", prefix), http.StatusBadRequest, "Prefix must start with "+geniusService.CachePrefix)
+		return
+	}
+
+	purged, err := h.serviceGenius.PurgeCache(r.Context(), prefix)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to purge cache")
+		return
+	}
+
+	logger.FromContext(r.Context()).Infof("Purged %d cache entries with prefix %s", purged, prefix)
+	sendSuccessResponse(w, http.StatusOK, map[string]int64{"purged": purged})
+}
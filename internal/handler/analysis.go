@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"musPlayer/internal/servicePostgres"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary Анализ текста песни
+// @Description Число слов и уникальных слов, лексическое разнообразие, топ слов без стоп-слов, кандидаты в припев и оценка времени чтения и исполнения
+// @Tags analysis
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} lyricstats.Analysis
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/analysis [get]
+func (h *Handler) analyzeSong(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	analysis, err := h.services.AnalyzeSong(r.Context(), songID)
+	if err != nil {
+		h.handleAnalysisError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, analysis)
+}
+
+// @Summary Анализ текстов исполнителя
+// @Description Та же статистика, что и для песни, по всем сохраненным песням исполнителя. Имя сравнивается без учета регистра
+// @Tags analysis
+// @Produce  json
+// @Param name path string true "Исполнитель (group)"
+// @Success 200 {object} lyricstats.Analysis
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/artists/{name}/analysis [get]
+func (h *Handler) analyzeArtist(w http.ResponseWriter, r *http.Request) {
+	analysis, err := h.services.AnalyzeArtist(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		h.handleAnalysisError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, analysis)
+}
+
+func (h *Handler) handleAnalysisError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+	case errors.Is(err, servicePostgres.ErrArtistNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Artist not found")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to analyze lyrics")
+	}
+}
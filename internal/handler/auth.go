@@ -6,34 +6,29 @@ import (
 	"net/http"
 )
 
-// @Summary Добавить новую песню
-// @Description Добавляет новую песню в базу данных
-// @Tags songs
-// @Accept json
+// @Summary OAuth callback Genius
+// @Description Обменивает код авторизации Genius на токен доступа. Токен сохраняется в сервисе и не возвращается клиенту
+// @Tags auth
 // @Produce json
-// @Param song body SongRequest true "Данные о песне"
-// @Success 200 {object} models.Song
-// @Failure 400 {string} string "Invalid request body"
-// @Failure 500 {string} string "Internal Server Error"
-// @Router /api/songs [post]
+// @Param code query string true "Код авторизации"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string "Code is missing"
+// @Failure 500 {object} map[string]string "Failed to obtain access token"
+// @Router /callback [get]
 func (h *Handler) callbackHandler(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to callbackHandler with method: %s", r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to callbackHandler with method: %s", r.Method)
 	code := r.URL.Query().Get("code")
 	if code == "" {
-		h.handleError(w, fmt.Errorf("code is missing"), http.StatusBadRequest, "Code is missing")
+		h.handleError(w, r, fmt.Errorf("code is missing"), http.StatusBadRequest, "Code is missing")
 		return
 	}
 
 	// Получение токена доступа
-	if err := h.serviceGenius.GetAccessToken(code); err != nil {
-		h.handleError(w, err, http.StatusInternalServerError, "Failed to obtain access token")
+	if err := h.serviceGenius.GetAccessToken(r.Context(), code); err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to obtain access token")
 		return
 	}
-	logger.Logger.Debugf("Received code: %s", code)
+	logger.FromContext(r.Context()).Info("Genius authorization completed")
 
-	// Вывод токена на экран
-	response := map[string]string{
-		"access_token": h.serviceGenius.AccessToken,
-	}
-	sendSuccessResponse(w, http.StatusOK, response)
+	sendSuccessResponse(w, http.StatusOK, map[string]string{"status": "authorized"})
 }
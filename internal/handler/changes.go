@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 1000
+)
+
+// @Summary Лента изменений песен
+// @Description Изменения после курсора since по возрастанию номера: upsert с текущим состоянием песни или delete с идентификатором удаленной песни.
+// @Description У каждой песни в ленте только последнее изменение. Без since (или с 0) возвращаются все песни — это первая синхронизация.
+// @Description Пока has_more, запрашивайте следующую страницу с since=cursor. Если курсор старше changes.retention, ответ 410 с resync_required:
+// @Description клиенту нужно заново загрузить список песен и продолжить с cursor из этого ответа
+// @Tags changes
+// @Produce  json
+// @Param since query int false "Курсор из предыдущего ответа"
+// @Param limit query int false "Размер страницы (по умолчанию 500, не больше 1000)"
+// @Success 200 {object} models.ChangeFeedPage
+// @Failure 400 {object} map[string]string
+// @Failure 410 {object} models.ChangeFeedPage
+// @Failure 500 {object} map[string]string
+// @Router /api/changes [get]
+func (h *Handler) listChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var since int64
+	if v := q.Get("since"); v != "" {
+		var err error
+		if since, err = strconv.ParseInt(v, 10, 64); err != nil || since < 0 {
+			h.handleError(w, r, err, http.StatusBadRequest, "since must be a cursor from a previous response")
+			return
+		}
+	}
+	limit, err := limitParam("limit", q.Get("limit"), defaultChangesLimit, maxChangesLimit)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.services.ListChanges(r.Context(), since, limit)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to list changes")
+		return
+	}
+
+	status := http.StatusOK
+	if page.ResyncRequired {
+		status = http.StatusGone
+	}
+	sendSuccessResponse(w, status, page)
+}
@@ -0,0 +1,17 @@
+package handler
+
+import "net/http"
+
+// @Summary GraphQL
+// @Description Запросы song, songs и artist и мутации addSong, updateSong и deleteSong. Схема — internal/graph/schema.graphql.
+// @Description Ошибки выполнения возвращаются в поле errors с кодом 200, как принято в GraphQL
+// @Tags graphql
+// @Accept  json
+// @Produce  json
+// @Param request body object true "{query, operationName, variables}"
+// @Success 200 {object} object "{data, errors}"
+// @Failure 400 {object} object "Invalid request body"
+// @Router /graphql [post]
+func (h *Handler) graphQL(w http.ResponseWriter, r *http.Request) {
+	h.graph.ServeHTTP(w, r)
+}
@@ -1,6 +1,10 @@
 package handler
 
 import (
+	"musPlayer/internal/config"
+	"musPlayer/internal/graph"
+	"musPlayer/internal/health"
+	"musPlayer/internal/metrics"
 	geniusService "musPlayer/internal/serviceGenius"
 	"musPlayer/internal/servicePostgres"
 	"net/http"
@@ -15,12 +19,18 @@ import (
 type Handler struct {
 	services      *servicePostgres.Service
 	serviceGenius *geniusService.GeniusService
+	checks        *health.Registry
+	cfg           *config.Config
+	graph         *graph.Handler
 }
 
-func NewHandler(services *servicePostgres.Service, serviceGenius *geniusService.GeniusService) *Handler {
+func NewHandler(services *servicePostgres.Service, serviceGenius *geniusService.GeniusService, checks *health.Registry, cfg *config.Config) *Handler {
 	return &Handler{
 		services:      services,
 		serviceGenius: serviceGenius,
+		checks:        checks,
+		cfg:           cfg,
+		graph:         graph.NewHandler(services, cfg.GraphQL),
 	}
 }
 
@@ -35,6 +45,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // @Router /api [get]
 func (h *Handler) InitRoutes() *mux.Router {
 	router := mux.NewRouter()
+	router.Use(requestLogger)
+
 	api := router.PathPrefix("/api").Subrouter()
 	{
 		songs := api.PathPrefix("/songs").Subrouter()
@@ -42,16 +54,83 @@ func (h *Handler) InitRoutes() *mux.Router {
 			songs.HandleFunc("/", h.addSong).Methods(http.MethodPost)
 			songs.HandleFunc("/search", h.searchSong).Methods(http.MethodPost)
 			songs.HandleFunc("/filter", h.getFilteredSongs).Methods(http.MethodPost)
+			songs.HandleFunc("/filter/facets", h.tagFacets).Methods(http.MethodPost)
 			songs.HandleFunc("/text", h.getTextWithPagination).Methods(http.MethodGet)
 			songs.HandleFunc("/{id:[0-9]+}", h.updateSong).Methods(http.MethodPut)
 			songs.HandleFunc("/{id:[0-9]+}", h.deleteSong).Methods(http.MethodDelete)
+			songs.HandleFunc("/{id:[0-9]+}/resync", h.resyncSong).Methods(http.MethodPost)
+			songs.HandleFunc("/{id:[0-9]+}/analysis", h.analyzeSong).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/similar", h.similarSongs).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/rating", h.getRating).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/rating", h.rateSong).Methods(http.MethodPut)
+			songs.HandleFunc("/{id:[0-9]+}/rating", h.deleteRating).Methods(http.MethodDelete)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics", h.listLyricsVersions).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics", h.addLyricsVersion).Methods(http.MethodPost)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics/at", h.syncedLinesAt).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics.lrc", h.exportLRC).Methods(http.MethodGet)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics.lrc", h.importLRC).Methods(http.MethodPut)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics.lrc/offset", h.shiftSyncedLyrics).Methods(http.MethodPost)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics/{versionId:[0-9]+}/default", h.setDefaultLyricsVersion).Methods(http.MethodPut)
+			songs.HandleFunc("/{id:[0-9]+}/lyrics/{versionId:[0-9]+}", h.deleteLyricsVersion).Methods(http.MethodDelete)
+		}
+		me := api.PathPrefix("/me").Subrouter()
+		{
+			me.HandleFunc("/library", h.listLibrary).Methods(http.MethodGet)
+			me.HandleFunc("/library/{id:[0-9]+}", h.addToLibrary).Methods(http.MethodPut)
+			me.HandleFunc("/library/{id:[0-9]+}", h.removeFromLibrary).Methods(http.MethodDelete)
+			me.HandleFunc("/favorites", h.listFavorites).Methods(http.MethodGet)
+			me.HandleFunc("/favorites/{id:[0-9]+}", h.addFavorite).Methods(http.MethodPut)
+			me.HandleFunc("/favorites/{id:[0-9]+}", h.removeFavorite).Methods(http.MethodDelete)
+			me.HandleFunc("/listens", h.listListens).Methods(http.MethodGet)
+			me.HandleFunc("/listens/stats/songs", h.songPlayCounts).Methods(http.MethodGet)
+			me.HandleFunc("/listens/stats/artists", h.artistPlayCounts).Methods(http.MethodGet)
+			me.HandleFunc("/listens/streaks", h.listenStreaks).Methods(http.MethodGet)
+			me.HandleFunc("/playing-now", h.playingNow).Methods(http.MethodGet)
 		}
+		api.HandleFunc("/listens", h.submitListens).Methods(http.MethodPost)
+		api.HandleFunc("/listens/unmatched", h.listUnmatchedListens).Methods(http.MethodGet)
+		api.HandleFunc("/listens/unmatched/{id:[0-9]+}/resolve", h.resolveUnmatchedListens).Methods(http.MethodPost)
+		api.HandleFunc("/tags", h.listTags).Methods(http.MethodGet)
+		api.HandleFunc("/tags", h.createTag).Methods(http.MethodPost)
+		api.HandleFunc("/tags/assign", h.assignTags).Methods(http.MethodPost)
+		api.HandleFunc("/tags/unassign", h.unassignTags).Methods(http.MethodPost)
+		api.HandleFunc("/tags/{id:[0-9]+}", h.updateTag).Methods(http.MethodPut)
+		api.HandleFunc("/tags/{id:[0-9]+}", h.deleteTag).Methods(http.MethodDelete)
+		// Подписка заставляет сервер отправлять запросы на произвольный адрес, поэтому управление — только с ключом
+		api.HandleFunc("/webhooks", h.requireAPIKey(h.listWebhooks)).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks", h.requireAPIKey(h.createWebhook)).Methods(http.MethodPost)
+		api.HandleFunc("/webhooks/{id:[0-9]+}", h.requireAPIKey(h.getWebhook)).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks/{id:[0-9]+}", h.requireAPIKey(h.updateWebhook)).Methods(http.MethodPut)
+		api.HandleFunc("/webhooks/{id:[0-9]+}", h.requireAPIKey(h.deleteWebhook)).Methods(http.MethodDelete)
+		api.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", h.requireAPIKey(h.listWebhookDeliveries)).Methods(http.MethodGet)
+		api.HandleFunc("/webhooks/deliveries/{id:[0-9]+}/redeliver", h.requireAPIKey(h.redeliverWebhook)).Methods(http.MethodPost)
+		api.HandleFunc("/changes", h.listChanges).Methods(http.MethodGet)
+		api.HandleFunc("/stream/songs", h.streamSongs).Methods(http.MethodGet)
+		api.HandleFunc("/stream/songs/ws", h.streamSongsWebSocket).Methods(http.MethodGet)
+		// Имя исполнителя может содержать «/» (AC/DC)
+		api.HandleFunc("/artists/{name:.+}/analysis", h.analyzeArtist).Methods(http.MethodGet)
+		router.HandleFunc("/graphql", h.graphQL).Methods(http.MethodPost)
+		// Путь ListenBrainz: клиенты настраиваются на musPlayer сменой адреса сервера
+		router.HandleFunc("/1/submit-listens", h.submitListens).Methods(http.MethodPost)
+		router.HandleFunc("/1/validate-token", h.validateToken).Methods(http.MethodGet)
 		router.HandleFunc("/callback", h.callbackHandler).Methods(http.MethodGet)
 		router.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
 			h.serviceGenius.RedirectUser(w, r)
 		}).Methods(http.MethodGet)
 	}
 
+	router.HandleFunc("/healthz", h.healthz).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", h.readyz).Methods(http.MethodGet)
+	router.HandleFunc("/debug/info", h.requireAPIKey(h.debugInfo)).Methods(http.MethodGet)
+	router.Handle("/debug/vars", h.requireAPIKey(metrics.Handler().ServeHTTP)).Methods(http.MethodGet)
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	{
+		admin.HandleFunc("/log-level", h.requireAPIKey(h.getLogLevel)).Methods(http.MethodGet)
+		admin.HandleFunc("/log-level", h.requireAPIKey(h.setLogLevel)).Methods(http.MethodPut)
+		admin.HandleFunc("/cache", h.requireAPIKey(h.purgeCache)).Methods(http.MethodDelete)
+	}
+
 	// Добавляем маршрут для Swagger-документации
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
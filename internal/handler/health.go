@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"musPlayer/internal/buildinfo"
+	"musPlayer/internal/health"
+	"musPlayer/internal/logger"
+	"net/http"
+)
+
+// DebugInfo описывает сборку, время работы и действующую конфигурацию сервиса
+type DebugInfo struct {
+	buildinfo.Info
+	Config map[string]interface{} `json:"config"`
+}
+
+// @Summary Проверка живости
+// @Description Возвращает 200, если процесс запущен и обрабатывает запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *Handler) healthz(w http.ResponseWriter, r *http.Request) {
+	sendSuccessResponse(w, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+// @Summary Проверка готовности
+// @Description Выполняет зарегистрированные проверки зависимостей (база данных, миграции, токен Genius)
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *Handler) readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.checks.Check(r.Context())
+
+	statusCode := http.StatusOK
+	if report.Status != health.StatusOK {
+		statusCode = http.StatusServiceUnavailable
+		logger.Logger.Warnf("Readiness check failed: %+v", report.Checks)
+	}
+
+	sendSuccessResponse(w, statusCode, report)
+}
+
+// @Summary Диагностическая информация
+// @Description Возвращает версию сборки, коммит, время работы и конфигурацию без секретов
+// @Tags health
+// @Produce json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Success 200 {object} DebugInfo
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /debug/info [get]
+func (h *Handler) debugInfo(w http.ResponseWriter, r *http.Request) {
+	sendSuccessResponse(w, http.StatusOK, DebugInfo{
+		Info:   buildinfo.Get(),
+		Config: h.cfg.Summary(),
+	})
+}
@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultLibraryLimit = 50
+	maxLibraryLimit     = 500
+	maxUserIDLength     = 255
+)
+
+// LibraryAddRequest — необязательная личная заметка к песне
+type LibraryAddRequest struct {
+	Note *string `json:"note,omitempty"`
+}
+
+// RatingRequest — оценка песни от 1 до 5
+type RatingRequest struct {
+	Rating int `json:"rating"`
+}
+
+// @Summary Моя библиотека
+// @Description Песни личной библиотеки пользователя X-User-ID. Параметры фильтра и сортировки те же, что у /api/songs/filter;
+// @Description теги перечисляются через запятую. Дополнительно доступна сортировка по added_at и my_rating
+// @Tags library
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param filter query string false "Подстрока имени исполнителя"
+// @Param song query string false "Подстрока названия"
+// @Param tags_all query string false "Теги через запятую: все"
+// @Param tags_any query string false "Теги через запятую: хотя бы один"
+// @Param tags_none query string false "Теги через запятую: ни одного"
+// @Param sort query string false "Поле сортировки, например -added_at"
+// @Param limit query int false "Сколько песен вернуть (по умолчанию 50, не больше 500)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.LibrarySong
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/library [get]
+func (h *Handler) listLibrary(w http.ResponseWriter, r *http.Request) {
+	h.sendLibrary(w, r, false)
+}
+
+// @Summary Мое избранное
+// @Description Избранные песни пользователя X-User-ID; параметры те же, что у /api/me/library
+// @Tags library
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param filter query string false "Подстрока имени исполнителя"
+// @Param song query string false "Подстрока названия"
+// @Param tags_all query string false "Теги через запятую: все"
+// @Param tags_any query string false "Теги через запятую: хотя бы один"
+// @Param tags_none query string false "Теги через запятую: ни одного"
+// @Param sort query string false "Поле сортировки, например -added_at"
+// @Param limit query int false "Сколько песен вернуть (по умолчанию 50, не больше 500)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.LibrarySong
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/favorites [get]
+func (h *Handler) listFavorites(w http.ResponseWriter, r *http.Request) {
+	h.sendLibrary(w, r, true)
+}
+
+func (h *Handler) sendLibrary(w http.ResponseWriter, r *http.Request, favoritesOnly bool) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	params, err := filterParamsFromQuery(r.URL.Query())
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	songs, err := h.services.ListLibrary(r.Context(), userID, params.songFilter(), favoritesOnly, params.Limit, params.Offset)
+	if err != nil {
+		h.handleLibraryError(w, r, err, "Failed to retrieve library")
+		return
+	}
+	if songs == nil {
+		songs = []models.LibrarySong{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, songs)
+}
+
+// @Summary Добавить песню в библиотеку
+// @Description Добавляет песню в библиотеку пользователя. Повторный запрос с note меняет личную заметку
+// @Tags library
+// @Accept  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Param request body LibraryAddRequest false "Заметка"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/library/{id} [put]
+func (h *Handler) addToLibrary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	// Тело необязательно: без него песня просто добавляется в библиотеку
+	var req LibraryAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.services.AddToLibrary(r.Context(), userID, songID, req.Note); err != nil {
+		h.handleLibraryError(w, r, err, "Failed to add song to library")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Удалить песню из библиотеки
+// @Description Удаляет песню из библиотеки вместе с отметкой избранного и заметкой. Оценка сохраняется
+// @Tags library
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/library/{id} [delete]
+func (h *Handler) removeFromLibrary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	if err := h.services.RemoveFromLibrary(r.Context(), userID, songID); err != nil {
+		h.handleLibraryError(w, r, err, "Failed to remove song from library")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Добавить в избранное
+// @Description Отмечает песню звездой; песня при этом добавляется в библиотеку
+// @Tags library
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/favorites/{id} [put]
+func (h *Handler) addFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setFavorite(w, r, true)
+}
+
+// @Summary Убрать из избранного
+// @Description Снимает звезду; песня остается в библиотеке
+// @Tags library
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/favorites/{id} [delete]
+func (h *Handler) removeFavorite(w http.ResponseWriter, r *http.Request) {
+	h.setFavorite(w, r, false)
+}
+
+func (h *Handler) setFavorite(w http.ResponseWriter, r *http.Request, favorite bool) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	if err := h.services.SetFavorite(r.Context(), userID, songID, favorite); err != nil {
+		h.handleLibraryError(w, r, err, "Failed to update favorites")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Оценка песни
+// @Description Средняя оценка песни и оценка пользователя X-User-ID, если заголовок передан
+// @Tags library
+// @Produce  json
+// @Param X-User-ID header string false "Пользователь"
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} models.SongRating
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/rating [get]
+func (h *Handler) getRating(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	rating, err := h.services.GetRating(r.Context(), strings.TrimSpace(r.Header.Get("X-User-ID")), songID)
+	if err != nil {
+		h.handleLibraryError(w, r, err, "Failed to retrieve rating")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, rating)
+}
+
+// @Summary Оценить песню
+// @Description Сохраняет оценку пользователя от 1 до 5 и возвращает пересчитанную среднюю оценку
+// @Tags library
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Param request body RatingRequest true "Оценка"
+// @Success 200 {object} models.SongRating
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/rating [put]
+func (h *Handler) rateSong(w http.ResponseWriter, r *http.Request) {
+	var req RatingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	h.setRating(w, r, &req.Rating)
+}
+
+// @Summary Снять оценку
+// @Tags library
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} models.SongRating
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/rating [delete]
+func (h *Handler) deleteRating(w http.ResponseWriter, r *http.Request) {
+	h.setRating(w, r, nil)
+}
+
+func (h *Handler) setRating(w http.ResponseWriter, r *http.Request, rating *int) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	result, err := h.services.RateSong(r.Context(), userID, songID, rating)
+	if err != nil {
+		h.handleLibraryError(w, r, err, "Failed to save rating")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, result)
+}
+
+// currentUser возвращает пользователя запроса. Клиенты ListenBrainz передают токен из listens.user_tokens
+// в заголовке Authorization: Token <токен>; без него пользователь берется из X-User-ID, который проставляет
+// шлюз авторизации. Неизвестный токен или запрос без пользователя — 401
+func (h *Handler) currentUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if token, ok := authToken(r); ok {
+		userID, ok := h.userByToken(token)
+		if !ok {
+			h.handleError(w, r, fmt.Errorf("unknown user token for %s", r.URL.Path), http.StatusUnauthorized, "Invalid authorization token")
+			return "", false
+		}
+		return userID, true
+	}
+
+	userID := strings.TrimSpace(r.Header.Get("X-User-ID"))
+	if userID == "" || len(userID) > maxUserIDLength {
+		h.handleError(w, r, fmt.Errorf("missing or invalid X-User-ID for %s", r.URL.Path), http.StatusUnauthorized, "Authorization token or X-User-ID header is required")
+		return "", false
+	}
+	return userID, true
+}
+
+// authToken возвращает токен из заголовка Authorization: Token <токен>. Другие схемы не относятся
+// к токенам пользователей и пропускаются
+func authToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !ok || !strings.EqualFold(scheme, "Token") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// userByToken находит пользователя по токену. Токен сравнивается со всеми настроенными за постоянное время
+func (h *Handler) userByToken(token string) (string, bool) {
+	var userID string
+	for known, user := range h.cfg.Listens.UserTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+			userID = user
+		}
+	}
+	return userID, userID != ""
+}
+
+// filterParamsFromQuery читает параметры фильтра песен из строки запроса; теги можно перечислить через запятую или повторить параметр
+func filterParamsFromQuery(q url.Values) (FilterParams, error) {
+	params := FilterParams{
+		Filter:   q.Get("filter"),
+		Song:     q.Get("song"),
+		TagsAll:  splitList(q["tags_all"]),
+		TagsAny:  splitList(q["tags_any"]),
+		TagsNone: splitList(q["tags_none"]),
+		Sort:     q.Get("sort"),
+		Limit:    defaultLibraryLimit,
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxLibraryLimit {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxLibraryLimit)
+		}
+		params.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return params, fmt.Errorf("offset must be a non-negative integer")
+		}
+		params.Offset = offset
+	}
+
+	return params, nil
+}
+
+func splitList(values []string) []string {
+	var items []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// handleLibraryError переводит ошибки библиотеки и оценок в ответ клиенту
+func (h *Handler) handleLibraryError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+	case errors.Is(err, servicePostgres.ErrNotInLibrary):
+		h.handleError(w, r, err, http.StatusNotFound, "Song is not in library")
+	case errors.Is(err, servicePostgres.ErrInvalidRating),
+		errors.Is(err, servicePostgres.ErrNoteTooLong),
+		errors.Is(err, servicePostgres.ErrInvalidTag),
+		errors.Is(err, servicePostgres.ErrInvalidSort):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,442 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// 1000 прослушиваний по 10 КБ и запас на обертку
+	maxListensBodySize = 10<<20 + 64<<10
+
+	defaultListensCount = 25
+	maxListensCount     = 1000
+	defaultStatsLimit   = 25
+	maxStatsLimit       = 1000
+)
+
+// Периоды статистики прослушиваний
+var statsRanges = map[string]time.Duration{
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+}
+
+// ResolveIngestRequest — песня каталога, с которой сопоставляется трек из очереди
+type ResolveIngestRequest struct {
+	SongID int `json:"song_id"`
+}
+
+// ResolveIngestResponse — число прослушиваний, сопоставленных с песней
+type ResolveIngestResponse struct {
+	Linked int64 `json:"linked"`
+}
+
+// ValidateTokenResponse — результат проверки токена в формате ListenBrainz
+type ValidateTokenResponse struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	Valid    bool   `json:"valid"`
+	UserName string `json:"user_name,omitempty"`
+}
+
+// @Summary Проверить токен ListenBrainz
+// @Description Проверяет токен из заголовка Authorization: Token <токен> или параметра token и возвращает имя его пользователя.
+// @Description Клиенты ListenBrainz вызывают этот метод при настройке
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя>"
+// @Param token query string false "Токен пользователя, если нет заголовка"
+// @Success 200 {object} ValidateTokenResponse
+// @Failure 400 {object} map[string]string
+// @Router /1/validate-token [get]
+func (h *Handler) validateToken(w http.ResponseWriter, r *http.Request) {
+	token, ok := authToken(r)
+	if !ok {
+		token = strings.TrimSpace(r.URL.Query().Get("token"))
+	}
+	if token == "" {
+		h.handleError(w, r, nil, http.StatusBadRequest, "You need to provide an Authorization token.")
+		return
+	}
+
+	userID, ok := h.userByToken(token)
+	if !ok {
+		sendSuccessResponse(w, http.StatusOK, ValidateTokenResponse{Code: http.StatusOK, Message: "Token invalid."})
+		return
+	}
+	sendSuccessResponse(w, http.StatusOK, ValidateTokenResponse{Code: http.StatusOK, Message: "Token valid.", Valid: true, UserName: userID})
+}
+
+// @Summary Отправить прослушивания
+// @Description Принимает тело submit-listens ListenBrainz: listen_type single или import с listened_at, playing_now без него.
+// @Description Прослушивания сопоставляются с песнями каталога по исполнителю и названию, остальные треки ставятся в очередь поиска на Genius.
+// @Description Тот же обработчик доступен по пути /1/submit-listens для клиентов ListenBrainz
+// @Tags listens
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param listens body models.ListenSubmission true "Прослушивания"
+// @Success 200 {object} servicePostgres.ListenSubmitResult
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/listens [post]
+func (h *Handler) submitListens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var sub models.ListenSubmission
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxListensBodySize)).Decode(&sub); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.handleError(w, r, err, http.StatusRequestEntityTooLarge, "Request body is too large")
+			return
+		}
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid JSON document submitted")
+		return
+	}
+
+	result, err := h.services.SubmitListens(r.Context(), userID, sub)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to save listens")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, result)
+}
+
+// @Summary История прослушиваний
+// @Description Последние прослушивания пользователя от новых к старым. max_ts — прослушивания до этого момента, min_ts — ближайшие после него
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param min_ts query int false "Unix-время, после которого искать прослушивания"
+// @Param max_ts query int false "Unix-время, до которого искать прослушивания"
+// @Param count query int false "Сколько прослушиваний вернуть (по умолчанию 25, не больше 1000)"
+// @Success 200 {array} models.Listen
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/listens [get]
+func (h *Handler) listListens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	q := r.URL.Query()
+	minTS, err := unixParam(q.Get("min_ts"))
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "min_ts must be a unix timestamp")
+		return
+	}
+	maxTS, err := unixParam(q.Get("max_ts"))
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "max_ts must be a unix timestamp")
+		return
+	}
+	count, err := limitParam("count", q.Get("count"), defaultListensCount, maxListensCount)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	listens, err := h.services.ListListens(r.Context(), userID, minTS, maxTS, count)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to retrieve listens")
+		return
+	}
+	if listens == nil {
+		listens = []models.Listen{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, listens)
+}
+
+// @Summary Сейчас играет
+// @Description Трек из последней отправки playing_now, пока не истекла его длительность
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Success 200 {object} models.PlayingNow
+// @Success 204 {string} string "Nothing is playing"
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/playing-now [get]
+func (h *Handler) playingNow(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	playing, err := h.services.PlayingNow(r.Context(), userID)
+	if errors.Is(err, servicePostgres.ErrNothingPlaying) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to retrieve playing now")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, playing)
+}
+
+// @Summary Прослушивания по трекам
+// @Description Самые прослушиваемые треки пользователя за период
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param range query string false "Период" Enums(week, month, year, all_time)
+// @Param limit query int false "Сколько треков вернуть (по умолчанию 25, не больше 1000)"
+// @Success 200 {array} models.SongPlayCount
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/listens/stats/songs [get]
+func (h *Handler) songPlayCounts(w http.ResponseWriter, r *http.Request) {
+	userID, period, ok := h.statsParams(w, r)
+	if !ok {
+		return
+	}
+
+	counts, err := h.services.SongPlayCounts(r.Context(), userID, period)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to count listens")
+		return
+	}
+	if counts == nil {
+		counts = []models.SongPlayCount{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, counts)
+}
+
+// @Summary Прослушивания по исполнителям
+// @Description Самые прослушиваемые исполнители пользователя за период и число разных треков каждого
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param range query string false "Период" Enums(week, month, year, all_time)
+// @Param limit query int false "Сколько исполнителей вернуть (по умолчанию 25, не больше 1000)"
+// @Success 200 {array} models.ArtistPlayCount
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/listens/stats/artists [get]
+func (h *Handler) artistPlayCounts(w http.ResponseWriter, r *http.Request) {
+	userID, period, ok := h.statsParams(w, r)
+	if !ok {
+		return
+	}
+
+	counts, err := h.services.ArtistPlayCounts(r.Context(), userID, period)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to count listens")
+		return
+	}
+	if counts == nil {
+		counts = []models.ArtistPlayCount{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, counts)
+}
+
+// @Summary Серии прослушиваний
+// @Description Текущая и самая длинная серии дней подряд, в каждый из которых было прослушивание. Дни считаются в часовом поясе tz
+// @Tags listens
+// @Produce  json
+// @Param Authorization header string false "Token <токен пользователя из listens.user_tokens>"
+// @Param X-User-ID header string false "Пользователь, если нет токена"
+// @Param tz query string false "Часовой пояс IANA, например Europe/Moscow (по умолчанию UTC)"
+// @Success 200 {object} models.ListenStreaks
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/me/listens/streaks [get]
+func (h *Handler) listenStreaks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		var err error
+		if loc, err = time.LoadLocation(tz); err != nil || tz == "Local" {
+			h.handleError(w, r, err, http.StatusBadRequest, "tz must be an IANA time zone name")
+			return
+		}
+	}
+
+	streaks, err := h.services.ListenStreaks(r.Context(), userID, loc)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to count listen streaks")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, streaks)
+}
+
+// @Summary Несопоставленные треки
+// @Description Треки из прослушиваний, которых нет в каталоге, и состояние их поиска на Genius
+// @Tags listens
+// @Produce  json
+// @Param status query string false "Статус" Enums(pending, matched, not_found, ambiguous, failed)
+// @Param limit query int false "Сколько записей вернуть (по умолчанию 25, не больше 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.IngestQueueItem
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/listens/unmatched [get]
+func (h *Handler) listUnmatchedListens(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "", models.IngestStatusPending, models.IngestStatusMatched, models.IngestStatusNotFound, models.IngestStatusAmbiguous, models.IngestStatusFailed:
+	default:
+		h.handleError(w, r, nil, http.StatusBadRequest, "Unknown status")
+		return
+	}
+	limit, err := limitParam("limit", q.Get("limit"), defaultStatsLimit, maxStatsLimit)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := strconv.Atoi(q.Get("offset"))
+	if q.Get("offset") == "" {
+		offset, err = 0, nil
+	}
+	if err != nil || offset < 0 {
+		h.handleError(w, r, err, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	items, err := h.services.IngestQueue(r.Context(), status, limit, offset)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to list unmatched tracks")
+		return
+	}
+	if items == nil {
+		items = []models.IngestQueueItem{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, items)
+}
+
+// @Summary Сопоставить трек вручную
+// @Description Привязывает трек из очереди к песне каталога и сопоставляет с ней все его прослушивания
+// @Tags listens
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Идентификатор записи очереди"
+// @Param request body ResolveIngestRequest true "Песня каталога"
+// @Success 200 {object} ResolveIngestResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/listens/unmatched/{id}/resolve [post]
+func (h *Handler) resolveUnmatchedListens(w http.ResponseWriter, r *http.Request) {
+	queueID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid queue item ID")
+		return
+	}
+
+	var req ResolveIngestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SongID <= 0 {
+		h.handleError(w, r, err, http.StatusBadRequest, "song_id is required")
+		return
+	}
+
+	linked, err := h.services.ResolveIngest(r.Context(), queueID, req.SongID)
+	if err != nil {
+		h.handleListenError(w, r, err, "Failed to resolve track")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, ResolveIngestResponse{Linked: linked})
+}
+
+func (h *Handler) statsParams(w http.ResponseWriter, r *http.Request) (string, models.ListenStatsRange, bool) {
+	userID, ok := h.currentUser(w, r)
+	if !ok {
+		return "", models.ListenStatsRange{}, false
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	period := models.ListenStatsRange{From: time.Unix(0, 0), To: now.Add(24 * time.Hour)}
+	if name := q.Get("range"); name != "" && name != "all_time" {
+		d, ok := statsRanges[name]
+		if !ok {
+			h.handleError(w, r, nil, http.StatusBadRequest, "range must be week, month, year or all_time")
+			return "", period, false
+		}
+		period.From = now.Add(-d)
+	}
+
+	limit, err := limitParam("limit", q.Get("limit"), defaultStatsLimit, maxStatsLimit)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return "", period, false
+	}
+	period.Limit = limit
+
+	return userID, period, true
+}
+
+func unixParam(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	ts, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	t := time.Unix(ts, 0)
+	return &t, nil
+}
+
+func limitParam(name, v string, def, max int) (int, error) {
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 || n > max {
+		return 0, fmt.Errorf("%s must be between 1 and %d", name, max)
+	}
+	return n, nil
+}
+
+// handleListenError переводит ошибки прослушиваний в ответ клиенту
+func (h *Handler) handleListenError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, servicePostgres.ErrInvalidListens):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	case errors.Is(err, servicePostgres.ErrIngestItemNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Queue item not found")
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"musPlayer/internal/config"
+	"musPlayer/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTokenHandler() *Handler {
+	return &Handler{cfg: &config.Config{Listens: models.ListensConfig{
+		UserTokens: map[string]string{"alice-token": "alice", "bob-token": "bob"},
+	}}}
+}
+
+func TestCurrentUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "listenbrainz token", headers: map[string]string{"Authorization": "Token alice-token"}, want: "alice"},
+		{name: "scheme is case insensitive", headers: map[string]string{"Authorization": "token  bob-token "}, want: "bob"},
+		{
+			name:    "token wins over gateway header",
+			headers: map[string]string{"Authorization": "Token bob-token", "X-User-ID": "alice"},
+			want:    "bob",
+		},
+		{
+			name:    "unknown token is not replaced by gateway header",
+			headers: map[string]string{"Authorization": "Token stolen", "X-User-ID": "alice"},
+			wantErr: true,
+		},
+		{name: "gateway header", headers: map[string]string{"X-User-ID": " carol "}, want: "carol"},
+		{
+			name:    "other schemes fall back to gateway header",
+			headers: map[string]string{"Authorization": "Bearer jwt", "X-User-ID": "carol"},
+			want:    "carol",
+		},
+		{name: "empty token", headers: map[string]string{"Authorization": "Token "}, wantErr: true},
+		{name: "no user", wantErr: true},
+	}
+	h := newTokenHandler()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/1/submit-listens", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			got, ok := h.currentUser(w, r)
+			if tt.wantErr {
+				if ok || w.Code != http.StatusUnauthorized {
+					t.Fatalf("currentUser() = %q, %v with status %d, want 401", got, ok, w.Code)
+				}
+				return
+			}
+			if !ok || got != tt.want {
+				t.Fatalf("currentUser() = %q, %v, want %q (response %d %s)", got, ok, tt.want, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		query      string
+		wantStatus int
+		want       ValidateTokenResponse
+	}{
+		{
+			name:       "valid header",
+			header:     "Token alice-token",
+			wantStatus: http.StatusOK,
+			want:       ValidateTokenResponse{Code: 200, Message: "Token valid.", Valid: true, UserName: "alice"},
+		},
+		{
+			name:       "valid query parameter",
+			query:      "?token=bob-token",
+			wantStatus: http.StatusOK,
+			want:       ValidateTokenResponse{Code: 200, Message: "Token valid.", Valid: true, UserName: "bob"},
+		},
+		{
+			name:       "invalid token",
+			header:     "Token nope",
+			wantStatus: http.StatusOK,
+			want:       ValidateTokenResponse{Code: 200, Message: "Token invalid."},
+		},
+		{name: "missing token", wantStatus: http.StatusBadRequest},
+	}
+	router := newTokenHandler().InitRoutes()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/1/validate-token"+tt.query, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var got ValidateTokenResponse
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"musPlayer/internal/servicePostgres"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Максимальный размер загружаемого LRC-файла
+const maxLRCSize = 1 << 20
+
+// ShiftRequest — сдвиг всех строк синхронизированного текста
+type ShiftRequest struct {
+	ShiftMs int `json:"shift_ms"` // положительное значение показывает строки позже
+}
+
+// @Summary Загрузить синхронизированный текст
+// @Description Принимает LRC или enhanced LRC (метки слов <mm:ss.xx>, заголовок [offset:]) и заменяет синхронизированный текст песни
+// @Tags lyrics
+// @Accept  plain
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Param lrc body string true "Содержимое файла LRC"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics.lrc [put]
+func (h *Handler) importLRC(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	synced, err := h.services.ImportLRC(r.Context(), songID, http.MaxBytesReader(w, r.Body, maxLRCSize))
+	if err != nil {
+		h.handleSyncedLyricsError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, map[string]int{"lines": len(synced.Lines), "offset_ms": synced.OffsetMs})
+}
+
+// @Summary Получить синхронизированный текст
+// @Description Возвращает синхронизированный текст песни в формате LRC. По умолчанию с метками слов (enhanced LRC), если они были загружены
+// @Tags lyrics
+// @Produce  plain
+// @Param id path int true "Идентификатор песни"
+// @Param enhanced query bool false "Записывать метки слов (по умолчанию true)"
+// @Success 200 {string} string "LRC"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics.lrc [get]
+func (h *Handler) exportLRC(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	enhanced := true
+	if v := r.URL.Query().Get("enhanced"); v != "" {
+		if enhanced, err = strconv.ParseBool(v); err != nil {
+			h.handleError(w, r, err, http.StatusBadRequest, "enhanced must be a boolean")
+			return
+		}
+	}
+
+	text, err := h.services.ExportLRC(r.Context(), songID, enhanced)
+	if err != nil {
+		h.handleSyncedLyricsError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+strconv.Itoa(songID)+`.lrc"`)
+	_, _ = w.Write([]byte(text))
+}
+
+// @Summary Строка по времени воспроизведения
+// @Description Возвращает строку, которая звучит в момент t, и следующую за ней. Время строк указано с учетом смещения
+// @Tags lyrics
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Param t query number true "Время воспроизведения в секундах, например 83.2"
+// @Success 200 {object} servicePostgres.SyncedPosition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics/at [get]
+func (h *Handler) syncedLinesAt(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	t, err := strconv.ParseFloat(r.URL.Query().Get("t"), 64)
+	if err != nil || t < 0 || math.IsInf(t, 0) || math.IsNaN(t) {
+		h.handleError(w, r, err, http.StatusBadRequest, "t must be a non-negative number of seconds")
+		return
+	}
+
+	pos, err := h.services.LinesAt(r.Context(), songID, time.Duration(t*float64(time.Second)))
+	if err != nil {
+		h.handleSyncedLyricsError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, pos)
+}
+
+// @Summary Сдвинуть синхронизированный текст
+// @Description Сдвигает время всех строк и слов. Положительный shift_ms показывает строки позже. Сдвиг сохраняется в заголовке [offset:] при выгрузке
+// @Tags lyrics
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Param shift body ShiftRequest true "Сдвиг в миллисекундах"
+// @Success 200 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics.lrc/offset [post]
+func (h *Handler) shiftSyncedLyrics(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	var req ShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	offsetMs, err := h.services.ShiftSyncedLyrics(r.Context(), songID, time.Duration(req.ShiftMs)*time.Millisecond)
+	if err != nil {
+		h.handleSyncedLyricsError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, map[string]int{"offset_ms": offsetMs})
+}
+
+func (h *Handler) handleSyncedLyricsError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		h.handleError(w, r, err, http.StatusRequestEntityTooLarge, "LRC file is too large")
+	case errors.Is(err, servicePostgres.ErrInvalidLRC):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	case errors.Is(err, servicePostgres.ErrSongNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+	case errors.Is(err, servicePostgres.ErrSyncedLyricsNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Synced lyrics not found")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to process synced lyrics")
+	}
+}
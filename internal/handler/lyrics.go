@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// LyricsVersionRequest — пользовательская версия текста песни
+type LyricsVersionRequest struct {
+	Lang      string `json:"lang"`
+	Kind      string `json:"kind"` // translation, transliteration или user
+	Text      string `json:"text"`
+	SourceURL string `json:"source_url,omitempty"`
+}
+
+// @Summary Версии текста песни
+// @Description Возвращает оригинал, переводы и транслитерации песни без самих текстов
+// @Tags lyrics
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {array} models.LyricsVersion
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics [get]
+func (h *Handler) listLyricsVersions(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	versions, err := h.services.ListLyricsVersions(r.Context(), songID)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to list lyrics versions")
+		return
+	}
+	if versions == nil {
+		versions = []models.LyricsVersion{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, versions)
+}
+
+// @Summary Добавить версию текста
+// @Description Сохраняет перевод или транслитерацию от пользователя (заголовок X-User-ID). Повторная отправка того же языка и вида заменяет версию
+// @Tags lyrics
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Param X-User-ID header string false "Автор версии"
+// @Param version body LyricsVersionRequest true "Версия текста"
+// @Success 201 {object} map[string]int
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics [post]
+func (h *Handler) addLyricsVersion(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	var req LyricsVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	id, err := h.services.AddLyricsVersion(r.Context(), models.LyricsVersion{
+		SongID:      songID,
+		Lang:        req.Lang,
+		Kind:        req.Kind,
+		Text:        req.Text,
+		SourceURL:   req.SourceURL,
+		SubmittedBy: r.Header.Get("X-User-ID"),
+	})
+	if errors.Is(err, servicePostgres.ErrInvalidLyricsVersion) {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, servicePostgres.ErrSongNotFound) {
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+		return
+	}
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to save lyrics version")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+// @Summary Выбрать версию текста по умолчанию
+// @Description Версия по умолчанию отдается, когда язык и версия в запросе текста не указаны
+// @Tags lyrics
+// @Param id path int true "Идентификатор песни"
+// @Param versionId path int true "Идентификатор версии"
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics/{versionId}/default [put]
+func (h *Handler) setDefaultLyricsVersion(w http.ResponseWriter, r *http.Request) {
+	songID, versionID, ok := h.lyricsVersionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.SetDefaultLyricsVersion(r.Context(), songID, versionID); err != nil {
+		h.handleLyricsError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Удалить версию текста
+// @Description Удаляет перевод или транслитерацию. Оригинал удалить нельзя
+// @Tags lyrics
+// @Param id path int true "Идентификатор песни"
+// @Param versionId path int true "Идентификатор версии"
+// @Success 204 {string} string "No Content"
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/lyrics/{versionId} [delete]
+func (h *Handler) deleteLyricsVersion(w http.ResponseWriter, r *http.Request) {
+	songID, versionID, ok := h.lyricsVersionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.services.DeleteLyricsVersion(r.Context(), songID, versionID); err != nil {
+		h.handleLyricsError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) lyricsVersionVars(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	songID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return 0, 0, false
+	}
+	versionID, err := strconv.Atoi(vars["versionId"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid version ID")
+		return 0, 0, false
+	}
+	return songID, versionID, true
+}
+
+func (h *Handler) handleLyricsError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, servicePostgres.ErrLyricsVersionNotFound) {
+		h.handleError(w, r, err, http.StatusNotFound, "Lyrics version not found")
+		return
+	}
+	h.handleError(w, r, err, http.StatusInternalServerError, "Failed to update lyrics version")
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"musPlayer/internal/logger"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// requireAPIKey пропускает запрос, только если заголовок X-API-Key совпадает с api.key.
+// Без ключа в конфигурации защищенные маршруты недоступны и отвечают 404
+func (h *Handler) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := h.cfg.API.Key
+		if key == "" {
+			h.handleError(w, r, fmt.Errorf("api key is not configured, %s is disabled", r.URL.Path), http.StatusNotFound, "Not found")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-API-Key")), []byte(key)) != 1 {
+			h.handleError(w, r, fmt.Errorf("invalid api key for %s", r.URL.Path), http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// statusRecorder запоминает код ответа для журнала запросов
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack нужен WebSocket: http.ResponseController не подходит библиотеке, которая проверяет http.Hijacker
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap позволяет http.ResponseController добраться до исходного ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestLogger кладет в контекст запроса логгер с полями request_id, user и route
+// и пишет строку журнала по завершении запроса
+func requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		fields := logrus.Fields{
+			"request_id": requestID,
+			"route":      route,
+		}
+		if user := r.Header.Get("X-User-ID"); user != "" {
+			fields["user"] = user
+		}
+		ctx := logger.WithFields(r.Context(), fields)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		logger.FromContext(ctx).WithFields(logrus.Fields{
+			"method":   r.Method,
+			"status":   rec.status,
+			"duration": time.Since(startTime).String(),
+		}).Info("Request handled")
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
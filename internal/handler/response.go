@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	postgresrepo "musPlayer/pkg/postgresRepo"
 	"net/http"
 )
 
 func sendSuccessResponse(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.WriteHeader(statusCode)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 	if err := json.NewEncoder(w).Encode(data); err != nil {
 		newErrorResponse(w, http.StatusInternalServerError, "failed to encode response")
 	}
@@ -16,14 +20,41 @@ func sendSuccessResponse(w http.ResponseWriter, statusCode int, data interface{}
 
 // Обработчик для ошибок
 func newErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.WriteHeader(statusCode)
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
 // Функция для обработки ошибок
-func (h *Handler) handleError(w http.ResponseWriter, err error, statusCode int, message string) {
+func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error, statusCode int, message string) {
+	// Операцию не поддерживает хранилище из database.driver: это не сбой сервера
+	if errors.Is(err, postgresrepo.ErrNotSupported) {
+		statusCode, message = http.StatusNotImplemented, "Not supported by the configured database driver"
+	}
 	// Записываем ошибку в лог
-	logger.Logger.WithError(err).Error(message)
+	logger.FromContext(r.Context()).WithError(err).Error(message)
 	newErrorResponse(w, statusCode, message)
 }
+
+// handleGeniusError переводит ошибку обращения к Genius в ответ клиенту, не раскрывая внутренних деталей
+func (h *Handler) handleGeniusError(w http.ResponseWriter, r *http.Request, err error) {
+	var upstreamErr *geniusService.UpstreamError
+	switch {
+	case errors.Is(err, geniusService.ErrNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+	case errors.Is(err, geniusService.ErrInvalidURL):
+		h.handleError(w, r, err, http.StatusBadRequest, "genius_url must be a genius.com song page")
+	case errors.Is(err, geniusService.ErrCircuitOpen):
+		w.Header().Set("Retry-After", "30")
+		h.handleError(w, r, err, http.StatusServiceUnavailable, "Genius is temporarily unavailable, try again later")
+	case errors.Is(err, geniusService.ErrRateLimited):
+		w.Header().Set("Retry-After", "60")
+		h.handleError(w, r, err, http.StatusServiceUnavailable, "Genius rate limit exceeded, try again later")
+	case errors.Is(err, context.DeadlineExceeded):
+		h.handleError(w, r, err, http.StatusGatewayTimeout, "Genius did not respond in time")
+	case errors.As(err, &upstreamErr):
+		h.handleError(w, r, err, http.StatusBadGateway, "Genius request failed")
+	default:
+		h.handleError(w, r, err, http.StatusBadGateway, "Failed to fetch song from Genius")
+	}
+}
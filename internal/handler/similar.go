@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/pkg/tfidf"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultSimilarLimit = 10
+	maxSimilarLimit     = 100
+)
+
+// @Summary Похожие песни
+// @Description Песни библиотеки, ранжированные по косинусной близости векторов TF-IDF текстов
+// @Tags songs
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Param limit query int false "Сколько песен вернуть (по умолчанию 10, не больше 100)"
+// @Param artist query string false "same — только того же исполнителя, different — только других" Enums(same, different)
+// @Success 200 {array} models.SimilarSong
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/{id}/similar [get]
+func (h *Handler) similarSongs(w http.ResponseWriter, r *http.Request) {
+	songID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	limit := defaultSimilarLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxSimilarLimit {
+			h.handleError(w, r, err, http.StatusBadRequest, "limit must be between 1 and 100")
+			return
+		}
+	}
+
+	var filter tfidf.GroupFilter
+	switch r.URL.Query().Get("artist") {
+	case "":
+		filter = tfidf.AnyGroup
+	case "same":
+		filter = tfidf.SameGroup
+	case "different":
+		filter = tfidf.OtherGroup
+	default:
+		h.handleError(w, r, nil, http.StatusBadRequest, "artist must be same or different")
+		return
+	}
+
+	songs, err := h.services.SimilarSongs(r.Context(), songID, limit, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, servicePostgres.ErrSongNotFound):
+			h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+		case errors.Is(err, servicePostgres.ErrIndexNotReady):
+			w.Header().Set("Retry-After", "10")
+			h.handleError(w, r, err, http.StatusServiceUnavailable, "Similarity index is loading, try again later")
+		default:
+			h.handleError(w, r, err, http.StatusInternalServerError, "Failed to find similar songs")
+		}
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, songs)
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"musPlayer/internal/logger"
+	geniusService "musPlayer/internal/serviceGenius"
+	"musPlayer/internal/servicePostgres"
 	"musPlayer/models"
 	postgresrepo "musPlayer/pkg/postgresRepo"
 	"net/http"
@@ -13,75 +15,112 @@ import (
 )
 
 // SongRequest представляет запрос на добавление новой песни.
+// Вместо названия можно указать точную песню Genius: GeniusID (например, из ответа 409) или адрес страницы GeniusURL
 type SongRequest struct {
-	Title  string `json:"song"`
-	Artist string `json:"group"`
+	Title     string `json:"song"`
+	Artist    string `json:"group"`
+	GeniusID  int    `json:"genius_id,omitempty"`
+	GeniusURL string `json:"genius_url,omitempty"`
+}
+
+// SearchResponse содержит кандидатов Genius по убыванию оценки соответствия
+type SearchResponse struct {
+	Candidates []geniusService.Candidate `json:"candidates"`
+}
+
+// AmbiguousMatchResponse возвращается, когда ни один кандидат не набрал порог уверенности
+type AmbiguousMatchResponse struct {
+	Error      string                    `json:"error"`
+	Candidates []geniusService.Candidate `json:"candidates"`
 }
 
 // @Summary Добавить новую песню
-// @Description Добавляет новую песню в базу данных
+// @Description Ищет песню на Genius и добавляет лучшего кандидата в базу данных. Если ни один кандидат
+// @Description не набрал порог уверенности, возвращает 409 со списком кандидатов; клиент повторяет запрос с genius_id.
+// @Description С genius_id или genius_url песня загружается напрямую, без поиска
 // @Tags songs
 // @Accept  json
 // @Produce  json
 // @Param song body SongRequest true "Данные о песне"
 // @Success 200 {object} models.Song
 // @Failure 400 {string} string "Invalid request body"
-// @Failure 500 {string} string "Internal Server Error"
+// @Failure 404 {object} map[string]string "Song not found"
+// @Failure 409 {object} AmbiguousMatchResponse "No confident match"
+// @Failure 500 {object} map[string]string "Failed to save song"
+// @Failure 502 {object} map[string]string "Genius request failed"
+// @Failure 503 {object} map[string]string "Genius is temporarily unavailable"
 // @Router /api/songs [post]
 func (h *Handler) addSong(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
 
 	var songRequest SongRequest
 	if err := json.NewDecoder(r.Body).Decode(&songRequest); err != nil {
-		logger.Logger.Errorf("Failed to decode request body: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to decode request body: %v", err)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if songRequest.Title == "" {
-		http.Error(w, "Missing song title", http.StatusBadRequest)
+	if songRequest.Title == "" && songRequest.GeniusID == 0 && songRequest.GeniusURL == "" {
+		http.Error(w, "Missing song title, genius_id or genius_url", http.StatusBadRequest)
 		return
 	}
 
-	logger.Logger.Debugf("Received song request: %+v", songRequest)
-
-	song, err := h.serviceGenius.SearchSong(songRequest.Title, songRequest.Artist)
-	if err != nil {
-		logger.Logger.Errorf("Error searching song: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	logger.FromContext(r.Context()).Debugf("Received song request: %+v", songRequest)
+
+	var song *models.Song
+	var err error
+	switch {
+	case songRequest.GeniusID != 0:
+		song, err = h.serviceGenius.GetSongByID(r.Context(), songRequest.GeniusID)
+	case songRequest.GeniusURL != "":
+		song, err = h.serviceGenius.GetSongByURL(r.Context(), songRequest.GeniusURL)
+	default:
+		song, err = h.serviceGenius.SearchSong(r.Context(), songRequest.Title, songRequest.Artist)
 	}
-
-	if song == nil {
-		http.Error(w, "Song not found", http.StatusNotFound)
+	if err != nil {
+		var ambiguous *geniusService.AmbiguousMatchError
+		if errors.As(err, &ambiguous) {
+			logger.FromContext(r.Context()).Infof("Ambiguous match for %q by %q: %d candidates", songRequest.Title, songRequest.Artist, len(ambiguous.Candidates))
+			sendSuccessResponse(w, http.StatusConflict, AmbiguousMatchResponse{
+				Error:      "No confident match, choose a candidate and repeat the request with genius_id",
+				Candidates: ambiguous.Candidates,
+			})
+			return
+		}
+		h.handleGeniusError(w, r, err)
 		return
 	}
 
-	h.services.AddSong(context.Background(), postgresrepo.AddSongParams{
+	if _, err := h.services.AddSong(r.Context(), postgresrepo.AddSongParams{
 		SongId:      song.ID,
 		GroupName:   song.GroupName,
 		SongName:    song.SongName,
 		Text:        song.Text,
 		Link:        song.Link,
 		ReleaseDate: song.ReleaseDate,
-	})
+		Album:       song.Album,
+	}); err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to save song")
+		return
+	}
 
-	logger.Logger.Debugf("Song added successfully: %s by %s", songRequest.Title, songRequest.Artist)
+	logger.FromContext(r.Context()).Debugf("Song added successfully: %s by %s", song.SongName, song.GroupName)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(song)
 }
 
 // @Summary Найти песню
-// @Description Ищет песню по заголовку и исполнителю
+// @Description Ищет песню по заголовку и исполнителю и возвращает кандидатов Genius с оценкой соответствия
 // @Tags songs
 // @Accept  json
 // @Produce  json
 // @Param song body SongRequest true "Данные о песне"
-// @Success 200 {object} models.Song
+// @Success 200 {object} SearchResponse
 // @Failure 400 {string} string "Invalid request body"
-// @Failure 404 {string} string "Song not found"
-// @Failure 500 {string} string "Internal Server Error"
+// @Failure 404 {object} map[string]string "Song not found"
+// @Failure 502 {object} map[string]string "Genius request failed"
+// @Failure 503 {object} map[string]string "Genius is temporarily unavailable"
 // @Router /api/songs/search [post]
 func (h *Handler) searchSong(w http.ResponseWriter, r *http.Request) {
 	var songRequest SongRequest
@@ -95,31 +134,42 @@ func (h *Handler) searchSong(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing song title", http.StatusBadRequest)
 		return
 	}
-	song, err := h.serviceGenius.SearchSong(songRequest.Title, songRequest.Artist)
+	candidates, err := h.serviceGenius.SearchCandidates(r.Context(), songRequest.Title, songRequest.Artist)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	if song == nil {
-		http.Error(w, "Song not found", http.StatusNotFound)
+		h.handleGeniusError(w, r, err)
 		return
 	}
 
-	// Возвращаем найденную песню
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(song)
+	// Возвращаем кандидатов по убыванию оценки
+	sendSuccessResponse(w, http.StatusOK, SearchResponse{Candidates: candidates})
 }
 
 // FilterParams представляет параметры фильтрации для получения песен.
+// Filter и Song — подстроки имени исполнителя и названия; теги задаются как genre:rock
 type FilterParams struct {
-	Filter string `json:"filter"`
-	Limit  int    `json:"limit"`
-	Offset int    `json:"offset"`
+	Filter   string   `json:"filter"`
+	Song     string   `json:"song,omitempty"`
+	TagsAll  []string `json:"tags_all,omitempty"`  // песня отмечена всеми тегами
+	TagsAny  []string `json:"tags_any,omitempty"`  // хотя бы одним
+	TagsNone []string `json:"tags_none,omitempty"` // ни одним
+	Sort     string   `json:"sort,omitempty"`      // id, group, song, release_date, created_at, rating, rating_count; «-rating» — по убыванию
+	Limit    int      `json:"limit"`
+	Offset   int      `json:"offset"`
+}
+
+func (p FilterParams) songFilter() models.SongFilter {
+	return models.SongFilter{
+		Group:    p.Filter,
+		Song:     p.Song,
+		TagsAll:  p.TagsAll,
+		TagsAny:  p.TagsAny,
+		TagsNone: p.TagsNone,
+		Sort:     p.Sort,
+	}
 }
 
 // @Summary Получить отфильтрованные песни
-// @Description Получает песни, основываясь на заданных фильтрах
+// @Description Получает песни, основываясь на заданных фильтрах: подстроках имени исполнителя и названия и тегах
 // @Tags songs
 // @Accept  json
 // @Produce  json
@@ -129,40 +179,48 @@ type FilterParams struct {
 // @Failure 500 {string} string "Failed to retrieve songs"
 // @Router /api/songs/filter [post]
 func (h *Handler) getFilteredSongs(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
 
 	var params FilterParams
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		logger.Logger.Errorf("Failed to decode request payload: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to decode request payload: %v", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	logger.Logger.Debugf("Received filter params: %+v", params)
+	logger.FromContext(r.Context()).Debugf("Received filter params: %+v", params)
 
 	ctx := r.Context()
-	songs, err := h.services.SongService.GetSongs(ctx, params.Filter, params.Limit, params.Offset)
+	songs, err := h.services.SongService.GetSongs(ctx, params.songFilter(), params.Limit, params.Offset)
+	if errors.Is(err, servicePostgres.ErrInvalidTag) || errors.Is(err, servicePostgres.ErrInvalidSort) {
+		logger.FromContext(r.Context()).Warnf("Invalid filter: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
-		logger.Logger.Errorf("Failed to retrieve songs: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to retrieve songs: %v", err)
 		http.Error(w, "Failed to retrieve songs", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Logger.Debugf("Retrieved %d songs", len(songs))
+	logger.FromContext(r.Context()).Debugf("Retrieved %d songs", len(songs))
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(songs); err != nil {
-		logger.Logger.Errorf("Failed to encode response: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
 // GetTextWithPaginationParams представляет параметры для получения текста песни с пагинацией.
+// Lang и Version выбирают версию текста: например, {"lang": "en"} — перевод, {"lang": "ru-Latn", "version": "scientific"} — транслитерация.
 type GetTextWithPaginationParams struct {
-	Id       int `json:"id"`
-	PageSize int `json:"page_size"`
-	Page     int `json:"page"`
+	Id       int    `json:"id"`
+	PageSize int    `json:"page_size"`
+	Page     int    `json:"page"`
+	Lang     string `json:"lang,omitempty"`
+	Version  string `json:"version,omitempty"`
 }
 
 // @Summary Получить текст песни с пагинацией
@@ -173,14 +231,15 @@ type GetTextWithPaginationParams struct {
 // @Param params body GetTextWithPaginationParams true "Параметры запроса"
 // @Success 200 {object} map[string]string "Текст песни"
 // @Failure 400 {string} string "Invalid request payload"
+// @Failure 404 {string} string "Lyrics version not found"
 // @Failure 500 {string} string "Failed to get text"
 // @Router /api/songs/text [post]
 func (h *Handler) getTextWithPagination(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
 
 	var params GetTextWithPaginationParams
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		logger.Logger.Errorf("Failed to decode request payload: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to decode request payload: %v", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
@@ -193,24 +252,29 @@ func (h *Handler) getTextWithPagination(w http.ResponseWriter, r *http.Request)
 		params.Page = 1
 	}
 
-	logger.Logger.Debugf("Retrieving text for song ID: %d with pagination: %+v", params.Id, params)
+	logger.FromContext(r.Context()).Debugf("Retrieving text for song ID: %d with pagination: %+v", params.Id, params)
 
 	ctx := r.Context()
-	text, err := h.services.GetSongText(ctx, params.Id, params.PageSize, params.Page)
+	text, err := h.services.GetSongText(ctx, params.Id, params.PageSize, params.Page, servicePostgres.LyricsQuery{Lang: params.Lang, Version: params.Version})
+	if errors.Is(err, servicePostgres.ErrLyricsVersionNotFound) {
+		logger.FromContext(r.Context()).Warnf("No lyrics version for song %d: %+v", params.Id, params)
+		http.Error(w, "Lyrics version not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		logger.Logger.Errorf("Failed to get text: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to get text: %v", err)
 		http.Error(w, "Failed to get text", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{"text": text}); err != nil {
-		logger.Logger.Errorf("Failed to encode response: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to encode response: %v", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 
-	logger.Logger.Debugf("Successfully retrieved text for song ID: %d", params.Id)
+	logger.FromContext(r.Context()).Debugf("Successfully retrieved text for song ID: %d", params.Id)
 }
 
 // @Summary Удалить песню
@@ -223,28 +287,59 @@ func (h *Handler) getTextWithPagination(w http.ResponseWriter, r *http.Request)
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/songs/{id} [delete]
 func (h *Handler) deleteSong(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 	idd, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Logger.Errorf("Invalid song ID: %s, error: %v", id, err)
+		logger.FromContext(r.Context()).Errorf("Invalid song ID: %s, error: %v", id, err)
 		http.Error(w, "Invalid song ID", http.StatusBadRequest)
 		return
 	}
 
-	logger.Logger.Debugf("Deleting song with ID: %d", idd)
+	logger.FromContext(r.Context()).Debugf("Deleting song with ID: %d", idd)
 
 	err = h.services.DeleteSong(r.Context(), int64(idd))
 	if err != nil {
-		logger.Logger.Errorf("Failed to delete song: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to delete song: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
-	logger.Logger.Debugf("Song with ID: %d deleted successfully", idd)
+	logger.FromContext(r.Context()).Debugf("Song with ID: %d deleted successfully", idd)
+}
+
+// @Summary Синхронизировать песню с источником
+// @Description Заново загружает песню по ее ссылке на Genius и обновляет изменившиеся поля, кроме отредактированных вручную
+// @Tags songs
+// @Produce  json
+// @Param id path int true "Идентификатор песни"
+// @Success 200 {object} servicePostgres.SyncResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/songs/{id}/resync [post]
+func (h *Handler) resyncSong(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid song ID")
+		return
+	}
+
+	result, err := h.services.ResyncSong(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, servicePostgres.ErrSongNotFound) {
+			h.handleError(w, r, err, http.StatusNotFound, "Song not found")
+			return
+		}
+		h.handleGeniusError(w, r, err)
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, result)
 }
 
 // GetSongUpdateParams представляет параметры для обновления данных о песне.
@@ -267,25 +362,25 @@ type GetSongUpdateParams struct {
 // @Failure 500 {string} string "Internal Server Error"
 // @Router /api/songs/{id} [put]
 func (h *Handler) updateSong(w http.ResponseWriter, r *http.Request) {
-	logger.Logger.Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
+	logger.FromContext(r.Context()).Debugf("Incoming request to %s with method: %s", r.URL.Path, r.Method)
 
 	vars := mux.Vars(r)
 	id := vars["id"]
 	idd, err := strconv.Atoi(id)
 	if err != nil {
-		logger.Logger.Errorf("Invalid song ID: %s, error: %v", id, err)
+		logger.FromContext(r.Context()).Errorf("Invalid song ID: %s, error: %v", id, err)
 		http.Error(w, "Invalid song ID", http.StatusBadRequest)
 		return
 	}
 
 	var params GetSongUpdateParams
 	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		logger.Logger.Errorf("Failed to decode request payload: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to decode request payload: %v", err)
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
 
-	logger.Logger.Debugf("Updating song with ID: %d, params: %+v", idd, params)
+	logger.FromContext(r.Context()).Debugf("Updating song with ID: %d, params: %+v", idd, params)
 
 	if err := h.services.UpdateSong(r.Context(), models.SongUpdateParams{
 		GroupName:   params.GroupName,
@@ -294,11 +389,11 @@ func (h *Handler) updateSong(w http.ResponseWriter, r *http.Request) {
 		Text:        params.Text,
 		ID:          idd,
 	}); err != nil {
-		logger.Logger.Errorf("Failed to update song: %v", err)
+		logger.FromContext(r.Context()).Errorf("Failed to update song: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	logger.Logger.Debugf("Song with ID: %d updated successfully", idd)
+	logger.FromContext(r.Context()).Debugf("Song with ID: %d updated successfully", idd)
 }
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/servicePostgres"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Пауза переподключения, которую SSE-клиент получает в поле retry
+	sseRetry = 3 * time.Second
+	// Таймаут записи одного сообщения WebSocket
+	wsWriteWait = 10 * time.Second
+	// Событие, после которого клиент должен перечитать список песен целиком
+	streamResetEvent = "reset"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// @Summary Живой поток изменений песен (SSE)
+// @Description Server-Sent Events с событиями song.created, song.updated и song.deleted; id события совпадает с id в вебхуках.
+// @Description После переподключения с заголовком Last-Event-ID (или параметром last_event_id) сначала приходят пропущенные события.
+// @Description Если пропущено слишком много, приходит событие reset и клиент должен перечитать список. Пока событий нет, раз в stream.heartbeat_interval приходит комментарий
+// @Tags stream
+// @Produce  text/event-stream
+// @Param artist query []string false "Только песни исполнителей (можно повторять)" collectionFormat(multi)
+// @Param tag query []string false "Только песни хотя бы с одним из тегов (через запятую или повторяя параметр)" collectionFormat(multi)
+// @Param last_event_id query int false "Идентификатор последнего полученного события"
+// @Param Last-Event-ID header int false "Идентификатор последнего полученного события"
+// @Success 200 {object} models.SongEvent
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/stream/songs [get]
+func (h *Handler) streamSongs(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribeSongEvents(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	// Поток живет дольше WriteTimeout сервера
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to start stream")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Отключает буферизацию ответа в nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if sub.Reset {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamResetEvent)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	for {
+		wait, cancel := context.WithTimeout(ctx, h.cfg.Stream.HeartbeatInterval)
+		event, err := sub.Next(wait)
+		cancel()
+
+		switch {
+		case err == nil:
+			data, err := json.Marshal(event)
+			if err != nil {
+				logger.FromContext(ctx).Errorf("Failed to encode song event %d: %v", event.ID, err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			fmt.Fprint(w, ": ping\n\n")
+		default:
+			// Клиент отключился, отстал или сервис останавливается: EventSource переподключится с Last-Event-ID
+			return
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// @Summary Живой поток изменений песен (WebSocket)
+// @Description То же, что /api/stream/songs, по WebSocket: каждое сообщение — JSON события, событие reset приходит как {"type": "reset"}.
+// @Description Возобновление — параметром last_event_id. Сервер отправляет ping раз в stream.heartbeat_interval; отставший клиент отключается с кодом 1013, при остановке сервиса — 1001
+// @Tags stream
+// @Param artist query []string false "Только песни исполнителей (можно повторять)" collectionFormat(multi)
+// @Param tag query []string false "Только песни хотя бы с одним из тегов (через запятую или повторяя параметр)" collectionFormat(multi)
+// @Param last_event_id query int false "Идентификатор последнего полученного события"
+// @Success 101 {object} models.SongEvent
+// @Failure 400 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/stream/songs/ws [get]
+func (h *Handler) streamSongsWebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, ok := h.subscribeSongEvents(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту
+		logger.FromContext(r.Context()).Warnf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// После Hijack контекст запроса не отменяется при отключении клиента, за соединением следит чтение
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	heartbeat := h.cfg.Stream.HeartbeatInterval
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if sub.Reset {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err := conn.WriteJSON(map[string]string{"type": streamResetEvent}); err != nil {
+			return
+		}
+	}
+
+	for {
+		wait, cancelWait := context.WithTimeout(ctx, heartbeat)
+		event, err := sub.Next(wait)
+		cancelWait()
+
+		switch {
+		case err == nil:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case errors.Is(err, servicePostgres.ErrSlowConsumer):
+			closeWebSocket(conn, websocket.CloseTryAgainLater, "too slow, reconnect with last_event_id")
+			return
+		case errors.Is(err, servicePostgres.ErrStreamClosed):
+			closeWebSocket(conn, websocket.CloseGoingAway, "server is shutting down")
+			return
+		default:
+			return
+		}
+	}
+}
+
+func closeWebSocket(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
+
+// subscribeSongEvents разбирает фильтр и точку возобновления и подписывается на события
+func (h *Handler) subscribeSongEvents(w http.ResponseWriter, r *http.Request) (*servicePostgres.SongSubscription, bool) {
+	q := r.URL.Query()
+	filter := servicePostgres.SongEventFilter{
+		Artists: q["artist"],
+		Tags:    splitList(q["tag"]),
+	}
+
+	var lastEventID *int64
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = q.Get("last_event_id")
+	}
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			h.handleError(w, r, err, http.StatusBadRequest, "Last-Event-ID must be a non-negative integer")
+			return nil, false
+		}
+		lastEventID = &id
+	}
+
+	sub, err := h.services.SubscribeSongEvents(r.Context(), filter, lastEventID)
+	switch {
+	case err == nil:
+		return sub, true
+	case errors.Is(err, servicePostgres.ErrInvalidTag):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	case errors.Is(err, servicePostgres.ErrStreamClosed):
+		h.handleError(w, r, err, http.StatusServiceUnavailable, "Server is shutting down")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to subscribe to song events")
+	}
+	return nil, false
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// TagRequest — данные тега. Name задается с пространством имен: genre:rock, mood:sad или просто live
+type TagRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// TagAssignRequest — массовое назначение или снятие тегов. Нужен ровно один из song_ids и filter;
+// limit и offset фильтра не учитываются, операция применяется ко всем подходящим песням
+type TagAssignRequest struct {
+	Tags    []string      `json:"tags"`
+	SongIDs []int         `json:"song_ids,omitempty"`
+	Filter  *FilterParams `json:"filter,omitempty"`
+}
+
+// TagAssignResponse — число добавленных или снятых назначений
+type TagAssignResponse struct {
+	Affected int64 `json:"affected"`
+}
+
+// TagFacetsRequest — фильтр песен, среди которых считаются теги, и необязательное пространство имен
+type TagFacetsRequest struct {
+	FilterParams
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// @Summary Список тегов
+// @Description Возвращает теги с числом отмеченных песен
+// @Tags tags
+// @Produce  json
+// @Param namespace query string false "Только теги пространства имен, например genre"
+// @Success 200 {array} models.Tag
+// @Failure 500 {object} map[string]string
+// @Router /api/tags [get]
+func (h *Handler) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.services.ListTags(r.Context(), r.URL.Query().Get("namespace"))
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to list tags")
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, tags)
+}
+
+// @Summary Создать тег
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param tag body TagRequest true "Тег"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/tags [post]
+func (h *Handler) createTag(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tag, err := h.services.CreateTag(r.Context(), req.Name, req.Description)
+	if err != nil {
+		h.handleTagError(w, r, err, "Failed to create tag")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusCreated, tag)
+}
+
+// @Summary Изменить тег
+// @Description Переименовывает тег или меняет описание; назначения песням сохраняются
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Идентификатор тега"
+// @Param tag body TagRequest true "Тег"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/tags/{id} [put]
+func (h *Handler) updateTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid tag ID")
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	tag, err := h.services.UpdateTag(r.Context(), tagID, req.Name, req.Description)
+	if err != nil {
+		h.handleTagError(w, r, err, "Failed to update tag")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, tag)
+}
+
+// @Summary Удалить тег
+// @Description Удаляет тег и снимает его со всех песен
+// @Tags tags
+// @Param id path int true "Идентификатор тега"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/tags/{id} [delete]
+func (h *Handler) deleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid tag ID")
+		return
+	}
+
+	if err := h.services.DeleteTag(r.Context(), tagID); err != nil {
+		h.handleTagError(w, r, err, "Failed to delete tag")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Назначить теги песням
+// @Description Отмечает тегами песни из списка song_ids или все песни, подходящие под filter. Несуществующие теги создаются
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param request body TagAssignRequest true "Теги и песни"
+// @Success 200 {object} TagAssignResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/tags/assign [post]
+func (h *Handler) assignTags(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeTagAssignRequest(w, r)
+	if !ok {
+		return
+	}
+
+	affected, err := h.services.AssignTags(r.Context(), req.Tags, req.selection())
+	if err != nil {
+		h.handleTagError(w, r, err, "Failed to assign tags")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, TagAssignResponse{Affected: affected})
+}
+
+// @Summary Снять теги с песен
+// @Description Снимает теги с песен из списка song_ids или со всех песен, подходящих под filter
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param request body TagAssignRequest true "Теги и песни"
+// @Success 200 {object} TagAssignResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/tags/unassign [post]
+func (h *Handler) unassignTags(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeTagAssignRequest(w, r)
+	if !ok {
+		return
+	}
+
+	affected, err := h.services.UnassignTags(r.Context(), req.Tags, req.selection())
+	if err != nil {
+		h.handleTagError(w, r, err, "Failed to unassign tags")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, TagAssignResponse{Affected: affected})
+}
+
+// @Summary Фасеты тегов
+// @Description Считает, сколько песен среди подходящих под фильтр отмечено каждым тегом; limit и offset не учитываются
+// @Tags tags
+// @Accept  json
+// @Produce  json
+// @Param request body TagFacetsRequest true "Фильтр песен"
+// @Success 200 {array} models.TagCount
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/songs/filter/facets [post]
+func (h *Handler) tagFacets(w http.ResponseWriter, r *http.Request) {
+	var req TagFacetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	facets, err := h.services.TagFacets(r.Context(), req.songFilter(), req.Namespace)
+	if err != nil {
+		h.handleTagError(w, r, err, "Failed to count tags")
+		return
+	}
+	if facets == nil {
+		facets = []models.TagCount{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, facets)
+}
+
+func (h *Handler) decodeTagAssignRequest(w http.ResponseWriter, r *http.Request) (TagAssignRequest, bool) {
+	var req TagAssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return req, false
+	}
+	return req, true
+}
+
+func (req TagAssignRequest) selection() models.SongSelection {
+	songs := models.SongSelection{SongIDs: req.SongIDs}
+	if req.Filter != nil {
+		filter := req.Filter.songFilter()
+		songs.Filter = &filter
+	}
+	return songs
+}
+
+// handleTagError переводит ошибки операций с тегами в ответ клиенту
+func (h *Handler) handleTagError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, servicePostgres.ErrInvalidTag):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	case errors.Is(err, servicePostgres.ErrTagNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Tag not found")
+	case errors.Is(err, servicePostgres.ErrTagExists):
+		h.handleError(w, r, err, http.StatusConflict, "Tag already exists")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"musPlayer/internal/servicePostgres"
+	"musPlayer/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookRequest — данные подписки. Пустой events — все события; без secret при создании
+// секрет генерируется, при изменении остается прежним. active по умолчанию true
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events,omitempty" example:"song.created,song.updated"`
+	Active      *bool    `json:"active,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func (req WebhookRequest) subscription(id int) models.WebhookSubscription {
+	active := req.Active == nil || *req.Active
+	return models.WebhookSubscription{
+		ID:          id,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		Events:      req.Events,
+		Active:      active,
+		Description: req.Description,
+	}
+}
+
+// @Summary Список подписок на события
+// @Tags webhooks
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Success 200 {array} models.WebhookSubscription
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks [get]
+func (h *Handler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.services.ListWebhooks(r.Context())
+	if err != nil {
+		h.handleError(w, r, err, http.StatusInternalServerError, "Failed to list webhooks")
+		return
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, subs)
+}
+
+// @Summary Создать подписку на события
+// @Description События song.created, song.updated и song.deleted отправляются POST-запросом на url.
+// @Description Подпись X-MusPlayer-Signature — sha256= и HMAC-SHA256 в hex от "<X-MusPlayer-Timestamp>.<тело>" на секрете подписки.
+// @Description Секрет возвращается только в ответе на создание
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param webhook body WebhookRequest true "Подписка"
+// @Success 201 {object} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks [post]
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	sub, err := h.services.CreateWebhook(r.Context(), req.subscription(0))
+	if err != nil {
+		h.handleWebhookError(w, r, err, "Failed to create webhook")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusCreated, sub)
+}
+
+// @Summary Получить подписку
+// @Tags webhooks
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param id path int true "Идентификатор подписки"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks/{id} [get]
+func (h *Handler) getWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	sub, err := h.services.GetWebhook(r.Context(), id)
+	if err != nil {
+		h.handleWebhookError(w, r, err, "Failed to get webhook")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, sub)
+}
+
+// @Summary Изменить подписку
+// @Description Заменяет адрес, события, активность и описание. Передайте secret, чтобы сменить секрет.
+// @Description Доставки неактивной подписки копятся и уходят после ее включения
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param id path int true "Идентификатор подписки"
+// @Param webhook body WebhookRequest true "Подписка"
+// @Success 200 {object} models.WebhookSubscription
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks/{id} [put]
+func (h *Handler) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	sub, err := h.services.UpdateWebhook(r.Context(), req.subscription(id))
+	if err != nil {
+		h.handleWebhookError(w, r, err, "Failed to update webhook")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusOK, sub)
+}
+
+// @Summary Удалить подписку
+// @Description Удаляет подписку вместе с журналом ее доставок
+// @Tags webhooks
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param id path int true "Идентификатор подписки"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks/{id} [delete]
+func (h *Handler) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.services.DeleteWebhook(r.Context(), id); err != nil {
+		h.handleWebhookError(w, r, err, "Failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Журнал доставок подписки
+// @Description Доставки от новых к старым с результатом последней попытки
+// @Tags webhooks
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param id path int true "Идентификатор подписки"
+// @Param status query string false "Статус" Enums(pending, delivered, failed, dead)
+// @Param limit query int false "Сколько доставок вернуть (по умолчанию 25, не больше 1000)"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks/{id}/deliveries [get]
+func (h *Handler) listWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	q := r.URL.Query()
+	status := q.Get("status")
+	switch status {
+	case "", models.DeliveryStatusPending, models.DeliveryStatusDelivered, models.DeliveryStatusFailed, models.DeliveryStatusDead:
+	default:
+		h.handleError(w, r, nil, http.StatusBadRequest, "Unknown status")
+		return
+	}
+	limit, err := limitParam("limit", q.Get("limit"), defaultStatsLimit, maxStatsLimit)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+		return
+	}
+	offset, err := strconv.Atoi(q.Get("offset"))
+	if q.Get("offset") == "" {
+		offset, err = 0, nil
+	}
+	if err != nil || offset < 0 {
+		h.handleError(w, r, err, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	deliveries, err := h.services.ListDeliveries(r.Context(), id, status, limit, offset)
+	if err != nil {
+		h.handleWebhookError(w, r, err, "Failed to list deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+
+	sendSuccessResponse(w, http.StatusOK, deliveries)
+}
+
+// @Summary Повторить доставку
+// @Description Ставит доставку в очередь заново с полным числом попыток, в том числе доставленную или исчерпавшую попытки
+// @Tags webhooks
+// @Produce  json
+// @Param X-API-Key header string true "Ключ администратора"
+// @Param id path int true "Идентификатор доставки"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/webhooks/deliveries/{id}/redeliver [post]
+func (h *Handler) redeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.handleError(w, r, err, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.services.Redeliver(r.Context(), id)
+	if err != nil {
+		h.handleWebhookError(w, r, err, "Failed to redeliver")
+		return
+	}
+
+	sendSuccessResponse(w, http.StatusAccepted, delivery)
+}
+
+// handleWebhookError переводит ошибки подписок в ответ клиенту
+func (h *Handler) handleWebhookError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, servicePostgres.ErrInvalidWebhook):
+		h.handleError(w, r, err, http.StatusBadRequest, err.Error())
+	case errors.Is(err, servicePostgres.ErrWebhookNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Webhook not found")
+	case errors.Is(err, servicePostgres.ErrDeliveryNotFound):
+		h.handleError(w, r, err, http.StatusNotFound, "Delivery not found")
+	default:
+		h.handleError(w, r, err, http.StatusInternalServerError, message)
+	}
+}
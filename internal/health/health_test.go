@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	fail := func(context.Context) error { return errors.New("connection refused") }
+	ok := func(context.Context) error { return nil }
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	// Проверка, которая не смотрит на контекст, не должна задерживать отчет дольше таймаута
+	ignore := func(context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		wantStatus string
+		wantChecks map[string]string
+		wantError  map[string]string
+	}{
+		{name: "no checks", checks: nil, wantStatus: StatusOK, wantChecks: map[string]string{}},
+		{name: "all ok", checks: map[string]CheckFunc{"db": ok, "genius": ok}, wantStatus: StatusOK,
+			wantChecks: map[string]string{"db": StatusOK, "genius": StatusOK}},
+		{name: "one failing", checks: map[string]CheckFunc{"db": fail, "genius": ok}, wantStatus: StatusFail,
+			wantChecks: map[string]string{"db": StatusFail, "genius": StatusOK},
+			wantError:  map[string]string{"db": "connection refused"}},
+		{name: "timeout", checks: map[string]CheckFunc{"db": hang}, wantStatus: StatusFail,
+			wantChecks: map[string]string{"db": StatusFail},
+			wantError:  map[string]string{"db": context.DeadlineExceeded.Error()}},
+		{name: "check ignoring context", checks: map[string]CheckFunc{"db": ignore, "genius": ok}, wantStatus: StatusFail,
+			wantChecks: map[string]string{"db": StatusFail, "genius": StatusOK},
+			wantError:  map[string]string{"db": context.DeadlineExceeded.Error()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(50 * time.Millisecond)
+			for name, fn := range tt.checks {
+				r.Register(name, fn)
+			}
+
+			start := time.Now()
+			report := r.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Check took %s, want it bounded by the timeout", elapsed)
+			}
+			if report.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", report.Status, tt.wantStatus)
+			}
+			if len(report.Checks) != len(tt.wantChecks) {
+				t.Fatalf("checks = %v, want %v", report.Checks, tt.wantChecks)
+			}
+			for name, want := range tt.wantChecks {
+				got := report.Checks[name]
+				if got.Status != want {
+					t.Errorf("check %s status = %q, want %q", name, got.Status, want)
+				}
+				if got.Error != tt.wantError[name] {
+					t.Errorf("check %s error = %q, want %q", name, got.Error, tt.wantError[name])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRunsInParallel(t *testing.T) {
+	r := NewRegistry(time.Second)
+	for _, name := range []string{"a", "b", "c"} {
+		r.Register(name, func(context.Context) error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+	}
+
+	start := time.Now()
+	if report := r.Check(context.Background()); report.Status != StatusOK {
+		t.Fatalf("status = %q, want ok", report.Status)
+	}
+	if elapsed := time.Since(start); elapsed >= 250*time.Millisecond {
+		t.Fatalf("three 100ms checks took %s, want them to run in parallel", elapsed)
+	}
+}
+
+func TestRegisterReplacesCheck(t *testing.T) {
+	r := NewRegistry(time.Second)
+	r.Register("db", func(context.Context) error { return errors.New("down") })
+	r.Register("db", func(context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	if report.Status != StatusOK || len(report.Checks) != 1 {
+		t.Fatalf("report = %+v, want a single passing check", report)
+	}
+}
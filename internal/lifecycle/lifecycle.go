@@ -0,0 +1,174 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"musPlayer/internal/logger"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Коды завершения процесса
+const (
+	ExitOK              = 0 // штатная остановка по сигналу
+	ExitError           = 1 // сервер или фоновый обработчик завершился с ошибкой
+	ExitShutdownFailure = 2 // при остановке не уложились в дедлайн или освобождение ресурсов завершилось ошибкой
+)
+
+// Func — функция запуска или остановки компонента
+type Func func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   Func
+}
+
+// Manager управляет жизненным циклом приложения: запускает основной сервер и фоновые обработчики,
+// ждет SIGINT/SIGTERM и останавливает компоненты в порядке регистрации с общим дедлайном
+type Manager struct {
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	workers         []hook
+	shutdownHooks   []hook
+	shuttingDown    atomic.Bool
+}
+
+// New создает менеджер с дедлайном на корректную остановку. drainDelay — пауза после начала остановки,
+// в течение которой проверка готовности уже не проходит, а сервер еще принимает запросы; входит в дедлайн
+func New(shutdownTimeout, drainDelay time.Duration) *Manager {
+	return &Manager{shutdownTimeout: shutdownTimeout, drainDelay: drainDelay}
+}
+
+// Go регистрирует фоновый обработчик. Его контекст отменяется в начале остановки,
+// и менеджер ждет завершения обработчика до истечения дедлайна
+func (m *Manager) Go(name string, run Func) {
+	m.workers = append(m.workers, hook{name: name, fn: run})
+}
+
+// OnShutdown регистрирует действие при остановке. Действия выполняются в порядке регистрации
+// после остановки фоновых обработчиков
+func (m *Manager) OnShutdown(name string, fn Func) {
+	m.shutdownHooks = append(m.shutdownHooks, hook{name: name, fn: fn})
+}
+
+// ShuttingDown сообщает, началась ли остановка. Используется проверкой готовности,
+// чтобы балансировщик перестал направлять запросы на останавливающийся экземпляр
+func (m *Manager) ShuttingDown() bool {
+	return m.shuttingDown.Load()
+}
+
+// CheckReady — проверка готовности, которая не проходит после начала остановки
+func (m *Manager) CheckReady(ctx context.Context) error {
+	if m.ShuttingDown() {
+		return errors.New("shutting down")
+	}
+	return nil
+}
+
+// Run запускает serve и фоновые обработчики, ждет сигнала или ошибки и выполняет остановку.
+// stop должен заставить serve вернуться, не прерывая обрабатываемые запросы.
+// Возвращает код завершения процесса
+func (m *Manager) Run(serve func() error, stop Func) int {
+	ctx, cancelSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancelSignals()
+
+	workersCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	exitCode := ExitOK
+	errCh := make(chan error, len(m.workers)+1)
+
+	var workersWG sync.WaitGroup
+	for _, w := range m.workers {
+		workersWG.Add(1)
+		go func(w hook) {
+			defer workersWG.Done()
+			logger.Logger.Infof("Starting background worker %s", w.name)
+			if err := w.fn(workersCtx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Logger.Errorf("Background worker %s failed: %v", w.name, err)
+				errCh <- err
+				return
+			}
+			logger.Logger.Infof("Background worker %s stopped", w.name)
+		}(w)
+	}
+
+	serveDone := make(chan struct{})
+	go func() {
+		defer close(serveDone)
+		if err := serve(); err != nil {
+			logger.Logger.Errorf("Server failed: %v", err)
+			errCh <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Logger.Info("Received shutdown signal")
+	case <-errCh:
+		exitCode = ExitError
+	}
+	// Повторный сигнал во время остановки завершает процесс немедленно
+	cancelSignals()
+
+	m.shuttingDown.Store(true)
+	logger.Logger.Infof("Shutting down, deadline %s", m.shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	failed := false
+
+	// Балансировщик узнает об остановке по /readyz не сразу: пока он не убрал экземпляр, запросы продолжают обслуживаться.
+	// При ошибке сервера или обработчика ждать нечего
+	if exitCode == ExitOK && m.drainDelay > 0 {
+		logger.Logger.Infof("Draining for %s before closing listeners", m.drainDelay)
+		select {
+		case <-time.After(m.drainDelay):
+		case <-shutdownCtx.Done():
+		}
+	}
+
+	// Перестаем принимать соединения и дожидаемся завершения текущих запросов
+	if err := stop(shutdownCtx); err != nil {
+		logger.Logger.Errorf("Failed to stop server gracefully: %v", err)
+		failed = true
+	}
+	select {
+	case <-serveDone:
+	case <-shutdownCtx.Done():
+		failed = true
+	}
+
+	// Останавливаем фоновые обработчики
+	cancelWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		workersWG.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		logger.Logger.Error("Background workers did not stop before the deadline")
+		failed = true
+	}
+
+	for _, h := range m.shutdownHooks {
+		if err := h.fn(shutdownCtx); err != nil {
+			logger.Logger.Errorf("Shutdown step %s failed: %v", h.name, err)
+			failed = true
+			continue
+		}
+		logger.Logger.Debugf("Shutdown step %s completed", h.name)
+	}
+
+	if failed && exitCode == ExitOK {
+		exitCode = ExitShutdownFailure
+	}
+	return exitCode
+}
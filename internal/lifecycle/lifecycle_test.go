@@ -0,0 +1,215 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// events — журнал шагов остановки в порядке выполнения
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(s string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, s)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.list...)
+}
+
+func TestRunStopsInOrderAfterDrain(t *testing.T) {
+	const drain = 100 * time.Millisecond
+	m := New(5*time.Second, drain)
+	var ev events
+
+	workerStopped := make(chan struct{})
+	m.Go("worker", func(ctx context.Context) error {
+		defer close(workerStopped)
+		<-ctx.Done()
+		ev.add("worker stopped")
+		return ctx.Err()
+	})
+	m.OnShutdown("database", func(context.Context) error {
+		ev.add("database closed")
+		return nil
+	})
+	m.OnShutdown("logger", func(context.Context) error {
+		ev.add("logger closed")
+		return nil
+	})
+
+	if err := m.CheckReady(context.Background()); err != nil {
+		t.Fatalf("CheckReady before shutdown = %v", err)
+	}
+
+	stopped := make(chan struct{})
+	var signalled atomic.Int64
+	serve := func() error {
+		signalled.Store(time.Now().UnixNano())
+		// Сигнал отправляется после того, как Run подписался на него
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+			return err
+		}
+		<-stopped
+		ev.add("server returned")
+		return nil
+	}
+	stop := func(ctx context.Context) error {
+		// К закрытию сервера проверка готовности уже не проходит не меньше drainDelay,
+		// а фоновые обработчики еще работают
+		if err := m.CheckReady(ctx); err == nil {
+			t.Error("readiness passes while the server is being stopped")
+		}
+		if elapsed := time.Since(time.Unix(0, signalled.Load())); elapsed < drain {
+			t.Errorf("server stopped %s after the signal, want at least the drain delay %s", elapsed, drain)
+		}
+		select {
+		case <-workerStopped:
+			t.Error("worker was stopped before the server")
+		default:
+		}
+		ev.add("server stop")
+		close(stopped)
+		return nil
+	}
+
+	if code := m.Run(serve, stop); code != ExitOK {
+		t.Fatalf("Run() = %d, want %d", code, ExitOK)
+	}
+	want := []string{"server stop", "server returned", "worker stopped", "database closed", "logger closed"}
+	if got := ev.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("shutdown order = %q, want %q", got, want)
+	}
+}
+
+func TestRunServerErrorSkipsDrain(t *testing.T) {
+	m := New(5*time.Second, time.Minute)
+	var ev events
+	m.OnShutdown("database", func(context.Context) error {
+		ev.add("database closed")
+		return nil
+	})
+
+	start := time.Now()
+	code := m.Run(func() error {
+		return errors.New("address already in use")
+	}, func(context.Context) error {
+		ev.add("server stop")
+		return nil
+	})
+	if code != ExitError {
+		t.Fatalf("Run() = %d, want %d", code, ExitError)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run waited %s after a server error, want no drain delay", elapsed)
+	}
+	if want := []string{"server stop", "database closed"}; !reflect.DeepEqual(ev.get(), want) {
+		t.Fatalf("shutdown steps = %q, want %q", ev.get(), want)
+	}
+}
+
+func TestRunWorkerErrorStopsServer(t *testing.T) {
+	m := New(5*time.Second, 0)
+	m.Go("failing", func(context.Context) error { return errors.New("boom") })
+
+	stopped := make(chan struct{})
+	code := m.Run(func() error {
+		<-stopped
+		return nil
+	}, func(context.Context) error {
+		close(stopped)
+		return nil
+	})
+	if code != ExitError {
+		t.Fatalf("Run() = %d, want %d", code, ExitError)
+	}
+	if !m.ShuttingDown() {
+		t.Fatal("ShuttingDown() = false after Run returned")
+	}
+}
+
+// signalServe — сервер, который сам отправляет SIGTERM после подписки Run на сигналы и работает до stopped
+func signalServe(stopped <-chan struct{}) func() error {
+	return func() error {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+			return err
+		}
+		<-stopped
+		return nil
+	}
+}
+
+func TestRunShutdownFailureStillClosesResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		hangStop bool
+		worker   Func
+		failStep bool
+	}{
+		{name: "server does not stop before the deadline", hangStop: true},
+		{
+			name: "worker ignores cancellation",
+			worker: func(context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			},
+		},
+		{name: "failing step does not skip the next one", failStep: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(100*time.Millisecond, 0)
+			if tt.worker != nil {
+				m.Go("worker", tt.worker)
+			}
+			var ev events
+			m.OnShutdown("cache", func(context.Context) error {
+				ev.add("cache")
+				if tt.failStep {
+					return errors.New("flush failed")
+				}
+				return nil
+			})
+			m.OnShutdown("database", func(context.Context) error {
+				ev.add("database")
+				return nil
+			})
+
+			stopped := make(chan struct{})
+			var once sync.Once
+			release := func() { once.Do(func() { close(stopped) }) }
+			t.Cleanup(release)
+			stop := func(ctx context.Context) error {
+				if tt.hangStop {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				release()
+				return nil
+			}
+
+			start := time.Now()
+			if code := m.Run(signalServe(stopped), stop); code != ExitShutdownFailure {
+				t.Fatalf("Run() = %d, want %d", code, ExitShutdownFailure)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Run took %s, want it bounded by the shutdown timeout", elapsed)
+			}
+			if want := []string{"cache", "database"}; !reflect.DeepEqual(ev.get(), want) {
+				t.Fatalf("shutdown steps = %q, want %q", ev.get(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+// WithFields возвращает контекст, логгер которого дополнен полями (request_id, user, route и т.п.)
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).WithFields(fields))
+}
+
+// FromContext возвращает логгер запроса из контекста или глобальный логгер, если его нет
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(Logger)
+}
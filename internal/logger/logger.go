@@ -2,37 +2,85 @@
 package logger
 
 import (
+	"fmt"
 	"io"
+	"musPlayer/models"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-var Logger *logrus.Logger
+var Logger *logrus.Logger = logrus.New()
 
-func InitLogger(level string) {
-	Logger = logrus.New()
+var logFile *rotatingFile
 
-	logLevel, err := logrus.ParseLevel(level)
+// InitLogger настраивает глобальный логгер: уровень, формат (text или json),
+// запись в stdout и в файл с ротацией по размеру и времени, маскирование секретов
+func InitLogger(cfg models.LoggingConfig) error {
+	logLevel, err := logrus.ParseLevel(cfg.Level)
 	if err != nil {
-		Logger.Fatal("Invalid log level: ", err)
+		return fmt.Errorf("invalid log level: %w", err)
 	}
-	Logger.SetLevel(logLevel)
+
+	l := logrus.New()
+	l.SetLevel(logLevel)
+	l.AddHook(redactHook{})
 
 	// Настройка формата логирования
-	Logger.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-	})
+	switch cfg.Format {
+	case "json":
+		l.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	case "", "text":
+		l.SetFormatter(&logrus.TextFormatter{
+			FullTimestamp: true,
+		})
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	var out io.Writer = os.Stdout
 
 	// Вывод логов в файл
-	file, err := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err == nil {
-		Logger.SetOutput(file)
-	} else {
-		Logger.Info("Failed to log to file, using default stderr")
-		file = os.Stderr
+	if cfg.File != "" {
+		file, err := newRotatingFile(cfg.File, int64(cfg.MaxSizeMB)<<20, cfg.RotateInterval, cfg.MaxBackups)
+		if err != nil {
+			l.Warnf("Failed to log to file %s, using stdout only: %v", cfg.File, err)
+		} else {
+			logFile = file
+			out = io.MultiWriter(os.Stdout, file)
+		}
+	}
+	l.SetOutput(out)
+
+	Logger = l
+	return nil
+}
+
+// SetLevel меняет уровень логирования во время работы
+func SetLevel(level string) error {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	Logger.SetLevel(logLevel)
+	Logger.Infof("Log level changed to %s", logLevel)
+	return nil
+}
+
+// Level возвращает текущий уровень логирования
+func Level() string {
+	return Logger.GetLevel().String()
+}
+
+// Close сбрасывает буферы файла логов на диск и закрывает его. Дальнейшие записи идут только в stdout
+func Close() error {
+	if logFile == nil {
+		return nil
 	}
+	Logger.SetOutput(os.Stdout)
 
-	multiWriter := io.MultiWriter(os.Stdout, file)
-	Logger.SetOutput(multiWriter)
+	file := logFile
+	logFile = nil
+	return file.Close()
 }
@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys — имена полей, значения которых никогда не попадают в лог
+var sensitiveKeys = map[string]bool{
+	"token": true, "code": true, "password": true, "passwd": true, "secret": true,
+	"authorization": true, "api_key": true, "apikey": true,
+}
+
+// sensitiveSuffixes — суффиксы имен полей с секретами (access_token, client_secret, db_password)
+var sensitiveSuffixes = []string{"_token", "_secret", "_password"}
+
+// Шаблоны секретов внутри сообщений: заголовок Bearer, пары ключ=значение и JSON-поля
+var redactPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`), "${1}" + redacted},
+	{regexp.MustCompile(`(?i)\b(access_token|refresh_token|client_secret|password|secret|api_key|token)("?\s*[:=]\s*"?)[^\s"&,]+`), "${1}${2}" + redacted},
+	{regexp.MustCompile(`(?i)\b(code=)[^\s"&,]+`), "${1}" + redacted},
+}
+
+// redactHook маскирует секреты в полях и тексте каждой записи перед форматированием
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	for key, value := range entry.Data {
+		if isSensitiveKey(key) {
+			entry.Data[key] = redacted
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = Redact(v)
+		case error:
+			entry.Data[key] = Redact(v.Error())
+		}
+	}
+	entry.Message = Redact(entry.Message)
+	return nil
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact заменяет в строке токены, коды авторизации и пароли заглушкой
+func Redact(s string) string {
+	for _, p := range redactPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
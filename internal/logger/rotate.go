@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// rotatingFile — файл логов, который переименовывается в резервную копию при превышении
+// размера или по истечении интервала. Хранится не больше maxBackups копий
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+
+	file       *os.File
+	size       int64
+	openedAt   time.Time
+	lastBackup string
+}
+
+func newRotatingFile(path string, maxSize int64, interval time.Duration, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		interval:   interval,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) needsRotation(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+next > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && time.Since(f.openedAt) >= f.interval
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.path, f.backupName(time.Now())); err != nil {
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// backupName возвращает имя новой резервной копии. Несколько ротаций за одну миллисекунду
+// получают суффиксы -001, -002...: имя должно быть свободно и сортироваться после предыдущей копии,
+// иначе rename затер бы ее, а prune удалил бы новую копию вместо старой
+func (f *rotatingFile) backupName(now time.Time) string {
+	base := fmt.Sprintf("%s.%s", f.path, now.Format("20060102T150405.000"))
+	name := base
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(name); os.IsNotExist(err) && name > f.lastBackup {
+			f.lastBackup = name
+			return name
+		}
+		name = fmt.Sprintf("%s-%03d", base, i)
+	}
+}
+
+// prune удаляет самые старые резервные копии сверх maxBackups
+func (f *rotatingFile) prune() {
+	if f.maxBackups <= 0 {
+		return
+	}
+
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil || len(backups) <= f.maxBackups {
+		return
+	}
+
+	// Метка времени в имени упорядочивает копии лексикографически
+	sort.Strings(backups)
+	for _, name := range backups[:len(backups)-f.maxBackups] {
+		_ = os.Remove(name)
+	}
+}
+
+// Close сбрасывает данные на диск и закрывает файл
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	file := f.file
+	f.file = nil
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileBySize(t *testing.T) {
+	// 20 строк по 10 байт, в файл до 30 байт помещается 3 строки: 7 файлов, из них 6 резервных копий
+	tests := []struct {
+		name        string
+		maxBackups  int
+		wantBackups int
+	}{
+		{name: "keeps all backups", maxBackups: 0, wantBackups: 6},
+		{name: "prunes old backups", maxBackups: 2, wantBackups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			f, err := newRotatingFile(path, 30, 0, tt.maxBackups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 20; i++ {
+				if _, err := fmt.Fprintf(f, "line %04d\n", i); err != nil {
+					t.Fatalf("write %d: %v", i, err)
+				}
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			backups, err := filepath.Glob(path + ".*")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(backups) != tt.wantBackups {
+				t.Fatalf("%d backups, want %d: %q", len(backups), tt.wantBackups, backups)
+			}
+
+			// Копии в порядке имен вместе с текущим файлом содержат последние строки без пропусков
+			sort.Strings(backups)
+			var content strings.Builder
+			for _, name := range append(backups, path) {
+				data, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(data) > 30 {
+					t.Errorf("%s has %d bytes, want at most 30", filepath.Base(name), len(data))
+				}
+				content.Write(data)
+			}
+			var want strings.Builder
+			for i := 20 - (tt.wantBackups*3 + 2); i < 20; i++ {
+				fmt.Fprintf(&want, "line %04d\n", i)
+			}
+			if content.String() != want.String() {
+				t.Fatalf("kept lines =\n%s\nwant\n%s", content.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestRotatingFileAppendsToExisting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("line 0000\nline 0001\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Размер уже записанного учитывается: третья строка помещается, четвертая уходит в новый файл
+	f, err := newRotatingFile(path, 30, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, line := range []string{"line 0002\n", "line 0003\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("%d backups, want 1", len(backups))
+	}
+	if data, _ := os.ReadFile(path); string(data) != "line 0003\n" {
+		t.Fatalf("current file = %q, want the last line", data)
+	}
+}
+
+func TestRotatingFileByInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := newRotatingFile(path, 0, 20*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	f.Write([]byte("first\n"))
+	f.Write([]byte("second\n"))
+	time.Sleep(30 * time.Millisecond)
+	f.Write([]byte("third\n"))
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("%d backups, want 1", len(backups))
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "first\nsecond\n" {
+		t.Fatalf("backup = %q", data)
+	}
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := newRotatingFile(filepath.Join(t.TempDir(), "app.log"), 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("second Close() = %v, want nil", err)
+	}
+	if _, err := f.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Fatalf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// Genius — счетчики обращений к API Genius, публикуются на /debug/vars
+var Genius = expvar.NewMap("genius")
+
+// Названия счетчиков Genius
+const (
+	GeniusRequests     = "requests_total"
+	GeniusRetries      = "retries_total"
+	GeniusFailures     = "failures_total"
+	GeniusRateLimited  = "rate_limited_total"
+	GeniusCircuitOpen  = "circuit_rejected_total"
+	GeniusCircuitState = "circuit_state"
+)
+
+// Webhooks — счетчики доставки событий подписчикам
+var Webhooks = expvar.NewMap("webhooks")
+
+// Названия счетчиков доставки
+const (
+	WebhooksDelivered = "delivered_total"
+	WebhooksFailed    = "failed_attempts_total"
+	WebhooksDead      = "dead_total"
+)
+
+// Handler отдает все опубликованные метрики в формате JSON
+func Handler() http.Handler {
+	return expvar.Handler()
+}
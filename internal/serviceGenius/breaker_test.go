@@ -0,0 +1,98 @@
+package servicegenius
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var transitions []string
+	b := newCircuitBreaker(2, 20*time.Millisecond, func(from, to breakerState) {
+		transitions = append(transitions, from.String()+"->"+to.String())
+	})
+
+	// Неудачи ниже порога цепь не размыкают
+	if !b.allow() {
+		t.Fatal("closed breaker rejected a request")
+	}
+	b.failure()
+	if b.state != breakerClosed {
+		t.Fatalf("state after 1 failure = %s, want closed", b.state)
+	}
+
+	b.allow()
+	b.failure()
+	if b.state != breakerOpen {
+		t.Fatalf("state after 2 failures = %s, want open", b.state)
+	}
+	if b.allow() {
+		t.Fatal("open breaker allowed a request before the cooldown")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker did not allow a probe after the cooldown")
+	}
+	if b.state != breakerHalfOpen {
+		t.Fatalf("state after cooldown = %s, want half-open", b.state)
+	}
+	if b.allow() {
+		t.Fatal("half-open breaker allowed a second concurrent probe")
+	}
+
+	// Неудачный пробный запрос снова размыкает цепь
+	b.failure()
+	if b.state != breakerOpen {
+		t.Fatalf("state after failed probe = %s, want open", b.state)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("breaker did not allow a probe after the second cooldown")
+	}
+	b.success()
+	if b.state != breakerClosed {
+		t.Fatalf("state after successful probe = %s, want closed", b.state)
+	}
+	if !b.allow() {
+		t.Fatal("closed breaker rejected a request")
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
+
+func TestCircuitBreakerReleaseFreesProbe(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond, nil)
+	b.failure()
+	time.Sleep(2 * time.Millisecond)
+
+	if !b.allow() {
+		t.Fatal("breaker did not allow a probe after the cooldown")
+	}
+	// Отмененный пробный запрос не меняет состояния, но позволяет сделать новый
+	b.release()
+	if b.state != breakerHalfOpen {
+		t.Fatalf("state after release = %s, want half-open", b.state)
+	}
+	if !b.allow() {
+		t.Fatal("breaker did not allow a new probe after release")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Hour, nil)
+	for i := 0; i < 10; i++ {
+		b.failure()
+	}
+	if !b.allow() {
+		t.Fatal("disabled breaker rejected a request")
+	}
+}
@@ -0,0 +1,41 @@
+package servicegenius
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Префиксы ключей кеша Genius
+const (
+	CachePrefix       = "genius:"
+	searchCachePrefix = CachePrefix + "search:"
+	lyricsCachePrefix = CachePrefix + "lyrics:"
+)
+
+// lyricsCacheKey — ключ кеша текста страницы url, разобранного текущей версией ExtractLyrics
+func lyricsCacheKey(url string) string {
+	return lyricsCachePrefix + "v" + strconv.Itoa(LyricsExtractorVersion) + ":" + url
+}
+
+// normalizeQuery приводит поисковый запрос к виду, в котором одинаковые по смыслу запросы совпадают
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// cached возвращает значение из кеша или загружает его через load
+func (g *GeniusService) cached(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if g.cache == nil {
+		return load(ctx)
+	}
+	return g.cache.GetOrLoad(ctx, key, ttl, load)
+}
+
+// PurgeCache удаляет из кеша записи Genius с ключами, начинающимися с prefix (например, "genius:search:")
+func (g *GeniusService) PurgeCache(ctx context.Context, prefix string) (int64, error) {
+	if g.cache == nil {
+		return 0, nil
+	}
+	return g.cache.Purge(ctx, prefix)
+}
@@ -0,0 +1,200 @@
+package servicegenius
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/metrics"
+	"musPlayer/models"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen возвращается без обращения к Genius, пока автомат разомкнут
+	ErrCircuitOpen = errors.New("genius is unavailable: circuit breaker is open")
+	// ErrRateLimited возвращается, когда Genius отвечает 429 и повторы исчерпаны
+	ErrRateLimited = errors.New("genius rate limit exceeded")
+	// ErrNotFound возвращается, когда Genius не нашел песню
+	ErrNotFound = errors.New("song not found on genius")
+	// ErrInvalidURL возвращается, когда адрес не ведет на страницу песни Genius
+	ErrInvalidURL = errors.New("invalid genius song url")
+)
+
+// UpstreamError описывает неуспешный HTTP-ответ Genius
+type UpstreamError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("genius responded with %s", e.Status)
+}
+
+// client — общий HTTP-клиент Genius с таймаутами, повторами и автоматическим выключателем
+type client struct {
+	http       *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	breaker    *circuitBreaker
+}
+
+func newClient(cfg models.GeniusConfig) *client {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: cfg.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   cfg.ConnectTimeout,
+		ResponseHeaderTimeout: cfg.ReadTimeout,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	metrics.Genius.Set(metrics.GeniusCircuitState, stringVar(breakerClosed.String()))
+
+	return &client{
+		http: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.RequestTimeout,
+		},
+		maxRetries: cfg.MaxRetries,
+		baseDelay:  cfg.RetryBaseDelay,
+		maxDelay:   cfg.RetryMaxDelay,
+		breaker: newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown, func(from, to breakerState) {
+			logger.Logger.Warnf("Genius circuit breaker state changed: %s -> %s", from, to)
+			metrics.Genius.Set(metrics.GeniusCircuitState, stringVar(to.String()))
+		}),
+	}
+}
+
+// do выполняет запрос. Идемпотентные запросы (GET, HEAD) повторяются при сетевых ошибках,
+// 429 и 5xx с экспоненциальной задержкой со случайным разбросом; заголовок Retry-After учитывается.
+// Возвращает ответ только со статусом 2xx, остальные преобразуются в ошибки
+func (c *client) do(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	log := logger.FromContext(ctx)
+
+	if !c.breaker.allow() {
+		metrics.Genius.Add(metrics.GeniusCircuitOpen, 1)
+		return nil, ErrCircuitOpen
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			c.breaker.release()
+			return nil, fmt.Errorf("failed to create genius request: %w", err)
+		}
+		retryable := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+		metrics.Genius.Add(metrics.GeniusRequests, 1)
+		resp, err := c.http.Do(req)
+
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				// Отмена запроса клиентом не говорит о состоянии Genius
+				c.breaker.release()
+				return nil, ctx.Err()
+			}
+			lastErr = fmt.Errorf("genius request failed: %w", err)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			metrics.Genius.Add(metrics.GeniusRateLimited, 1)
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			drain(resp)
+			lastErr = ErrRateLimited
+		case resp.StatusCode >= http.StatusInternalServerError:
+			lastErr = &UpstreamError{StatusCode: resp.StatusCode, Status: resp.Status}
+			drain(resp)
+		case resp.StatusCode >= http.StatusBadRequest:
+			// Ошибки клиента не повторяем и не считаем отказом Genius
+			c.breaker.success()
+			drain(resp)
+			if resp.StatusCode == http.StatusNotFound {
+				return nil, ErrNotFound
+			}
+			return nil, &UpstreamError{StatusCode: resp.StatusCode, Status: resp.Status}
+		default:
+			c.breaker.success()
+			return resp, nil
+		}
+
+		if !retryable || attempt >= c.maxRetries {
+			break
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			if retryAfter > c.maxDelay {
+				log.Warnf("Genius asked to retry after %s, which exceeds the retry limit", retryAfter)
+				break
+			}
+			delay = retryAfter
+		}
+
+		metrics.Genius.Add(metrics.GeniusRetries, 1)
+		log.Warnf("Genius request %s %s failed (attempt %d/%d): %v, retrying in %s",
+			req.Method, req.URL.Path, attempt+1, c.maxRetries+1, lastErr, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			c.breaker.release()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	metrics.Genius.Add(metrics.GeniusFailures, 1)
+	c.breaker.failure()
+	log.Errorf("Genius request failed: %v", lastErr)
+	return nil, lastErr
+}
+
+// backoff возвращает задержку перед повтором с номером attempt: случайное значение из [d/2, d],
+// где d = baseDelay * 2^attempt, но не больше maxDelay
+func (c *client) backoff(attempt int) time.Duration {
+	d := c.baseDelay << attempt
+	if d <= 0 || d > c.maxDelay {
+		d = c.maxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// drain дочитывает и закрывает тело ответа, чтобы соединение вернулось в пул
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+type stringVar string
+
+func (s stringVar) String() string { return strconv.Quote(string(s)) }
+
+var _ expvar.Var = stringVar("")
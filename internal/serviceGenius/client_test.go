@@ -0,0 +1,201 @@
+package servicegenius
+
+import (
+	"context"
+	"errors"
+	"musPlayer/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(maxRetries, breakerThreshold int) *client {
+	return newClient(models.GeniusConfig{
+		ConnectTimeout:   time.Second,
+		ReadTimeout:      time.Second,
+		RequestTimeout:   5 * time.Second,
+		MaxRetries:       maxRetries,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    2 * time.Second,
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  time.Hour,
+	})
+}
+
+func getRequest(url string) func(ctx context.Context) (*http.Request, error) {
+	return func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := &client{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{attempt: 1, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{attempt: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{attempt: 4, min: 500 * time.Millisecond, max: time.Second},
+		// Сдвиг переполняет Duration — задержка ограничивается maxDelay
+		{attempt: 80, min: 500 * time.Millisecond, max: time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := c.backoff(tt.attempt); d < tt.min || d > tt.max {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		min, max time.Duration
+	}{
+		{name: "empty", value: "", min: 0, max: 0},
+		{name: "seconds", value: "3", min: 3 * time.Second, max: 3 * time.Second},
+		{name: "zero seconds", value: "0", min: 0, max: 0},
+		{name: "negative", value: "-5", min: 0, max: 0},
+		{name: "garbage", value: "soon", min: 0, max: 0},
+		{name: "http date", value: time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), min: 8 * time.Second, max: 10 * time.Second},
+		{name: "past date", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), min: 0, max: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := parseRetryAfter(tt.value); d < tt.min || d > tt.max {
+				t.Fatalf("parseRetryAfter(%q) = %s, want within [%s, %s]", tt.value, d, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []int
+		method    string
+		wantCalls int32
+		wantErr   error
+	}{
+		{name: "success", responses: []int{200}, wantCalls: 1},
+		{name: "retries 5xx", responses: []int{503, 502, 200}, wantCalls: 3},
+		{name: "retries 429", responses: []int{429, 200}, wantCalls: 2},
+		{name: "gives up after max retries", responses: []int{500, 500, 500, 500}, wantCalls: 3, wantErr: &UpstreamError{}},
+		{name: "rate limit exhausted", responses: []int{429, 429, 429}, wantCalls: 3, wantErr: ErrRateLimited},
+		{name: "does not retry 4xx", responses: []int{404, 200}, wantCalls: 1, wantErr: ErrNotFound},
+		{name: "does not retry POST", responses: []int{503, 200}, method: http.MethodPost, wantCalls: 1, wantErr: &UpstreamError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tt.responses[n-1])
+			}))
+			defer srv.Close()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			c := newTestClient(2, 0)
+			resp, err := c.do(context.Background(), func(ctx context.Context) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, method, srv.URL, nil)
+			})
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			case *UpstreamError:
+				var upstream *UpstreamError
+				if !errors.As(err, &upstream) {
+					t.Fatalf("error = %v, want UpstreamError", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestClientHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	var waited time.Duration
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		waited = time.Since(first)
+	}))
+	defer srv.Close()
+
+	resp, err := newTestClient(1, 0).do(context.Background(), getRequest(srv.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if waited < 900*time.Millisecond {
+		t.Fatalf("retried after %s, want about 1s from Retry-After", waited)
+	}
+}
+
+func TestClientRetryAfterAboveLimit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	// Ждать дольше RetryMaxDelay бессмысленно: клиент сразу возвращает ошибку
+	_, err := newTestClient(3, 0).do(context.Background(), getRequest(srv.URL))
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("error = %v, want ErrRateLimited", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("calls = %d, want 1", got)
+	}
+}
+
+func TestClientOpensBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(0, 2)
+	for i := 0; i < 2; i++ {
+		if _, err := c.do(context.Background(), getRequest(srv.URL)); err == nil {
+			t.Fatal("expected an error from a failing upstream")
+		}
+	}
+	if _, err := c.do(context.Background(), getRequest(srv.URL)); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("calls = %d, want 2: the open breaker must not reach upstream", got)
+	}
+}
@@ -0,0 +1,60 @@
+package servicegenius
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// tokenCheckTTL — как долго кешируется результат проверки токена, чтобы проба готовности не расходовала лимит Genius
+const tokenCheckTTL = 5 * time.Minute
+
+// CheckToken проверяет, что токен доступа Genius получен и принимается API
+func (g *GeniusService) CheckToken(ctx context.Context) error {
+	// Запрос выполняется без блокировки: token() берет тот же мьютекс для каждого запроса к Genius,
+	// и медленная проба готовности не должна их задерживать
+	g.tokenMu.Lock()
+	token := g.AccessToken
+	if token != "" && g.tokenCheckedFor == token && time.Since(g.tokenCheckedAt) < tokenCheckTTL {
+		err := g.tokenCheckErr
+		g.tokenMu.Unlock()
+		return err
+	}
+	g.tokenMu.Unlock()
+
+	if token == "" {
+		return fmt.Errorf("genius access token is missing")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.BaseURL+"/account", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create token check request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	// Проверка идет в обход повторов и автоматического выключателя: нужен ответ здесь и сейчас
+	resp, err := g.client.http.Do(req)
+	if err != nil {
+		// Сетевая ошибка не означает, что токен невалиден, поэтому результат не кешируем
+		return fmt.Errorf("failed to check genius token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var checkErr error
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusForbidden:
+		// 403 означает, что токен принят, но у него нет scope "me"
+	case http.StatusUnauthorized:
+		checkErr = fmt.Errorf("genius access token is invalid")
+	default:
+		return fmt.Errorf("unexpected genius response: %s", resp.Status)
+	}
+
+	g.tokenMu.Lock()
+	g.tokenCheckedFor = token
+	g.tokenCheckedAt = time.Now()
+	g.tokenCheckErr = checkErr
+	g.tokenMu.Unlock()
+	return checkErr
+}
@@ -0,0 +1,193 @@
+package servicegenius
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Классы блоков, которые Genius вставляет внутрь или между контейнерами текста и которые не относятся к песне
+var lyricsWidgetClasses = []string{
+	"LyricsHeader__",
+	"LyricsFooter__",
+	"RightSidebar__",
+	"SidebarLyrics__",
+	"InreadContainer__",
+	"EmbedFooter__",
+	"ShareButtons__",
+	"ContributorsCreditSong__",
+}
+
+// Элементы, содержимое которых никогда не бывает текстом песни
+var lyricsSkipElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"button":   true,
+	"svg":      true,
+	"iframe":   true,
+	"img":      true,
+}
+
+// Блочные элементы внутри контейнера начинают новую строку
+var lyricsBlockElements = map[string]bool{
+	"div": true,
+	"p":   true,
+	"h1":  true,
+	"h2":  true,
+	"h3":  true,
+	"li":  true,
+}
+
+var (
+	// Строки-вставки Genius, которые попадают в текст контейнера
+	lyricsJunkLine = regexp.MustCompile(`^(?:You might also like|See .+ LiveGet tickets as low as \$\d+|\d*Embed)$`)
+	// На старой разметке счетчик встраиваний приклеен к последней строке: «Last line42Embed»
+	lyricsTrailingEmbed = regexp.MustCompile(`\d*Embed$`)
+	lyricsSpaces        = regexp.MustCompile(`\s+`)
+)
+
+// LyricsExtractorVersion входит в ключ кеша текстов: после изменения ExtractLyrics ее нужно увеличить,
+// чтобы тексты, разобранные прежней версией, не отдавались из кеша до истечения их срока
+const LyricsExtractorVersion = 2
+
+// ExtractLyrics извлекает текст песни из HTML страницы Genius.
+// Текст собирается из всех контейнеров data-lyrics-container по порядку, включая вложенные
+// аннотации и форматирование; заголовки секций ([Chorus] и т.п.) и пустые строки между куплетами сохраняются.
+func ExtractLyrics(htmlBody string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(htmlBody))
+	if err != nil {
+		return "", fmt.Errorf("ошибка парсинга HTML: %v", err)
+	}
+
+	var containers []*html.Node
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "div" && attr(n, "data-lyrics-container") == "true" {
+			containers = append(containers, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(doc)
+
+	if len(containers) == 0 {
+		return "", fmt.Errorf("не удалось найти элемент с data-lyrics-container")
+	}
+
+	// Genius делит длинные песни на контейнеры по границам куплетов, вставляя между ними рекламу
+	var b strings.Builder
+	for _, container := range containers {
+		writeLyricsNode(&b, container)
+		b.WriteString("\n\n")
+	}
+
+	text := cleanLyrics(b.String())
+	if text == "" {
+		return "", fmt.Errorf("не удалось извлечь текст из найденного элемента")
+	}
+
+	return text, nil
+}
+
+// writeLyricsNode рекурсивно выписывает текст узла, пропуская служебные блоки
+func writeLyricsNode(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Переводы строк в исходном HTML — это форматирование разметки, строки задаются только через <br>
+		b.WriteString(lyricsSpaces.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+		if n.Data == "br" {
+			b.WriteByte('\n')
+			return
+		}
+		if isLyricsWidget(n) {
+			return
+		}
+	default:
+		return
+	}
+
+	block := lyricsBlockElements[n.Data]
+	if block {
+		newLine(b)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeLyricsNode(b, c)
+	}
+	if block {
+		newLine(b)
+	}
+}
+
+// newLine завершает текущую строку, не добавляя пустых строк, которых нет в тексте песни
+func newLine(b *strings.Builder) {
+	if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		b.WriteByte('\n')
+	}
+}
+
+// isLyricsWidget сообщает, что элемент — не часть текста песни (шапка, реклама, кнопки, блок рекомендаций)
+func isLyricsWidget(n *html.Node) bool {
+	if lyricsSkipElements[n.Data] || attr(n, "data-exclude-from-selection") == "true" {
+		return true
+	}
+	class := attr(n, "class")
+	for _, prefix := range lyricsWidgetClasses {
+		if strings.Contains(class, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanLyrics нормализует пробелы, убирает строки-вставки и оставляет ровно одну пустую строку между куплетами
+func cleanLyrics(raw string) string {
+	raw = strings.ReplaceAll(raw, "\u200b", "")
+
+	var lines []string
+	blank := false
+	for _, line := range strings.Split(raw, "\n") {
+		// strings.Fields заодно превращает неразрывные пробелы в обычные
+		line = strings.Join(strings.Fields(line), " ")
+		if lyricsJunkLine.MatchString(line) {
+			continue
+		}
+		if line == "" {
+			blank = len(lines) > 0
+			continue
+		}
+		// Заголовок секции всегда начинает новый куплет, даже если Genius не поставил перед ним пустую строку
+		if strings.HasPrefix(line, "[") && len(lines) > 0 {
+			blank = true
+		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
+	}
+
+	if n := len(lines); n > 0 {
+		lines[n-1] = strings.TrimSpace(lyricsTrailingEmbed.ReplaceAllString(lines[n-1], ""))
+		if lines[n-1] == "" {
+			lines = lines[:n-1]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
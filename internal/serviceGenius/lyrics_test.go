@@ -0,0 +1,212 @@
+package servicegenius
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Эталоны перезаписываются после осознанного изменения парсера:
+//
+//	go test ./internal/serviceGenius -run TestExtractLyricsCorpus -update
+var update = flag.Bool("update", false, "rewrite expected lyrics in testdata/lyrics with the current parser output")
+
+// TestExtractLyricsCorpus прогоняет парсер по сохраненным страницам Genius: для каждой страницы name.html
+// ожидается текст name.txt. Новая страница добавляется как name.html, эталон создается запуском с -update
+// и проверяется вручную
+func TestExtractLyricsCorpus(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("testdata", "lyrics", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("no pages found in testdata/lyrics")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		golden := strings.TrimSuffix(page, ".html") + ".txt"
+		t.Run(name, func(t *testing.T) {
+			body, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ExtractLyrics(string(body))
+			if err != nil {
+				t.Fatalf("ExtractLyrics: %v", err)
+			}
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(got+"\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			want := strings.TrimSuffix(string(data), "\n")
+			if got == want {
+				return
+			}
+
+			gotLines, wantLines := strings.Split(got, "\n"), strings.Split(want, "\n")
+			for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+				var g, w string
+				if i < len(gotLines) {
+					g = gotLines[i]
+				}
+				if i < len(wantLines) {
+					w = wantLines[i]
+				}
+				if g != w {
+					t.Fatalf("line %d differs:\n\twant: %q\n\tgot:  %q", i+1, w, g)
+				}
+			}
+		})
+	}
+}
+
+// TestExtractLyrics проверяет отдельные особенности разметки на минимальных фрагментах;
+// целые страницы Genius проверяет TestExtractLyricsCorpus
+func TestExtractLyrics(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "plain page",
+			html: `<!DOCTYPE html>
+<html><head><title>Plain</title></head>
+<body>
+<div id="lyrics-root">
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">[Verse 1]<br/>First line of the song<br/>Second line of the song<br/><br/>[Chorus]<br/>Chorus line one<br/>Chorus line two</div>
+</div>
+</body></html>`,
+			want: `[Verse 1]
+First line of the song
+Second line of the song
+
+[Chorus]
+Chorus line one
+Chorus line two`,
+		},
+		{
+			name: "annotations and inline formatting",
+			html: `<!DOCTYPE html>
+<html><body>
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">[Intro: <a href="/artists/Artist">Artist</a>]<br/><a href="/123/Artist-song/annotated" class="ReferentFragmentdesktop__ClickTarget-sc-110r0d9-0 cehZkS"><span class="ReferentFragmentdesktop__Highlight-sc-110r0d9-1 jAzSMw">Annotated line that <i>leans</i> in<br/>and keeps going <b>loud</b></span></a><br/>Plain line after<br/><i>Whispered line</i><br/><br/>[Verse 1]<br/>Line with a <a href="/x"><span>link</span></a>, then more text<br/>Last verse line</div>
+</body></html>`,
+			want: `[Intro: Artist]
+Annotated line that leans in
+and keeps going loud
+Plain line after
+Whispered line
+
+[Verse 1]
+Line with a link, then more text
+Last verse line`,
+		},
+		{
+			name: "cyrillic with entities and stray whitespace",
+			html: `<!DOCTYPE html>
+<html lang="ru"><head><meta charset="utf-8"></head><body>
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">[Куплет 1]<br/>Группа&nbsp;крови —&nbsp;на рукаве,<br/>   Мой порядковый номер —
+на рукаве,<br/>Пожелай мне удачи в бою&#8203;<br/><br/><br/>[Припев]<br/>Пожелай мне&hellip;<br/>Не остаться в этой траве</div>
+</body></html>`,
+			want: `[Куплет 1]
+Группа крови — на рукаве,
+Мой порядковый номер — на рукаве,
+Пожелай мне удачи в бою
+
+[Припев]
+Пожелай мне…
+Не остаться в этой траве`,
+		},
+		{
+			name: "containers split by sidebar and ads",
+			html: `<!DOCTYPE html>
+<html><body>
+<div id="lyrics-root">
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">[Verse 1]<br/>Container one, line one<br/>Container one, line two</div>
+<div class="RightSidebar__Container-pajcl2-0 lfRlAS"><div class="SidebarLyrics__Container">You might also like</div><a href="/other">Other Song</a></div>
+<div class="InreadContainer__Container-sc-19040w5-0 cujBpY"><div>Advertisement</div></div>
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">[Chorus]<br/>Container two, chorus<br/>You might also like<br/>Container two, after widget text</div>
+<div data-lyrics-container="true" class="Lyrics__Container-sc-1ynbvzw-1 kUgSbL">Container three without header<br/>Final line</div>
+</div>
+</body></html>`,
+			want: `[Verse 1]
+Container one, line one
+Container one, line two
+
+[Chorus]
+Container two, chorus
+Container two, after widget text
+
+Container three without header
+Final line`,
+		},
+		{
+			name: "header, buttons and embed counter",
+			html: `<!DOCTYPE html>
+<html><body>
+<div data-lyrics-container="true" class="Lyrics__Container-sc-78fb6627-1 hiRbsH"><div data-exclude-from-selection="true" class="LyricsHeader__Container-sc-5e4b7146-1 hFsUgC"><div>12 Contributors</div><h2>Song Title Lyrics</h2></div>[Verse 1]<br/>Header is skipped<br/>[Pre-Chorus]<br/>No blank line before this header on the page<br/><button>Translations</button>[Chorus]<br/>Chorus line<br/>See Artist LiveGet tickets as low as $45<br/>Last line42Embed</div>
+<div class="LyricsFooter__Container-sc-1q1ll31-0"><div class="EmbedFooter__Container">Embed</div></div>
+</body></html>`,
+			want: `[Verse 1]
+Header is skipped
+
+[Pre-Chorus]
+No blank line before this header on the page
+
+[Chorus]
+Chorus line
+Last line`,
+		},
+		{
+			name: "legacy paragraph markup",
+			html: `<!DOCTYPE html>
+<html><body>
+<div class="lyrics">
+<div data-lyrics-container="true">
+<p>[Verse]<br>
+Old markup wraps lines in a paragraph<br>
+<a href="/1"><i>and annotates</i></a> inside it<br>
+</p>
+<p>[Outro]<br>
+Outro line</p>
+<script>window.__PRELOADED_STATE__ = {};</script>
+</div>
+</div>
+</body></html>`,
+			want: `[Verse]
+Old markup wraps lines in a paragraph
+and annotates inside it
+
+[Outro]
+Outro line`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractLyrics(tt.html)
+			if err != nil {
+				t.Fatalf("ExtractLyrics: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ExtractLyrics() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractLyricsWithoutContainer(t *testing.T) {
+	if _, err := ExtractLyrics(`<html><body><div class="SongHeader">Title</div></body></html>`); err == nil {
+		t.Fatal("expected an error for a page without lyrics containers")
+	}
+}
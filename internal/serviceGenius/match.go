@@ -0,0 +1,172 @@
+package servicegenius
+
+import (
+	"fmt"
+	"musPlayer/pkg/translit"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Candidate — результат поиска Genius с оценкой соответствия запросу
+type Candidate struct {
+	GeniusID    int      `json:"genius_id"`
+	Title       string   `json:"title"`
+	Artist      string   `json:"artist"`
+	ReleaseDate string   `json:"release_date"`
+	URL         string   `json:"url"`
+	Score       float64  `json:"score"`
+	Penalties   []string `json:"penalties,omitempty"`
+}
+
+// AmbiguousMatchError возвращается, когда ни один кандидат не набрал порог уверенности
+type AmbiguousMatchError struct {
+	Candidates []Candidate
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	return fmt.Sprintf("no confident match among %d candidates", len(e.Candidates))
+}
+
+// Штрафы за страницы, которые обычно не являются оригиналом песни
+var penalties = []struct {
+	name   string
+	factor float64
+	words  []string
+}{
+	{name: "translation", factor: 0.4, words: []string{"translation", "traduction", "traducción", "übersetzung", "перевод"}},
+	{name: "transliteration", factor: 0.5, words: []string{"transliteration", "романизация", "romanized"}},
+	{name: "remix", factor: 0.7, words: []string{"remix", "ремикс", "rmx"}},
+	{name: "cover", factor: 0.7, words: []string{"cover", "кавер"}},
+	{name: "live", factor: 0.8, words: []string{"live", "концерт", "acoustic", "акустика", "sped up", "slowed"}},
+}
+
+// rankCandidates оценивает каждый результат поиска относительно запрошенных названия и исполнителя
+// и возвращает кандидатов по убыванию оценки
+func rankCandidates(title, artist string, hits []searchHit) []Candidate {
+	wantTitle := normalizeForMatch(title)
+	wantArtist := normalizeForMatch(artist)
+	requested := title + " " + artist
+
+	candidates := make([]Candidate, 0, len(hits))
+	for _, hit := range hits {
+		c := Candidate{
+			GeniusID:    hit.ID,
+			Title:       hit.Title,
+			Artist:      hit.PrimaryArtist.Name,
+			ReleaseDate: hit.ReleaseDate,
+			URL:         hit.URL,
+		}
+
+		score := similarity(wantTitle, normalizeForMatch(hit.Title))
+		if wantArtist != "" {
+			score = 0.6*score + 0.4*similarity(wantArtist, normalizeForMatch(hit.PrimaryArtist.Name))
+		}
+
+		// Служебные аккаунты Genius ("Genius Russian Translations") публикуют только переводы
+		page := hit.Title + " " + hit.PrimaryArtist.Name
+		if strings.HasPrefix(strings.ToLower(hit.PrimaryArtist.Name), "genius ") {
+			page += " translation"
+		}
+		for _, p := range penalties {
+			if containsAny(page, p.words) && !containsAny(requested, p.words) {
+				score *= p.factor
+				c.Penalties = append(c.Penalties, p.name)
+			}
+		}
+
+		c.Score = float64(int(score*1000+0.5)) / 1000
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+// normalizeForMatch приводит строку к нижнему регистру латиницей, убирает пунктуацию и лишние пробелы
+func normalizeForMatch(s string) string {
+	s = strings.ToLower(translit.Simple(s))
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// similarity возвращает сходство строк от 0 до 1: максимум из нормированного расстояния Левенштейна
+// и доли общих слов (чтобы "группа крови" совпадала с "группа крови (remastered)")
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ra, rb := []rune(a), []rune(b)
+	maxLen := len(ra)
+	if len(rb) > maxLen {
+		maxLen = len(rb)
+	}
+	editScore := 1 - float64(levenshtein(ra, rb))/float64(maxLen)
+
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	set := make(map[string]bool, len(wordsB))
+	for _, w := range wordsB {
+		set[w] = true
+	}
+	common := 0
+	for _, w := range wordsA {
+		if set[w] {
+			common++
+		}
+	}
+	// Доля слов запроса, найденных в результате, с небольшим штрафом за лишние слова
+	tokenScore := float64(common) / float64(len(wordsA)) * (0.9 + 0.1*float64(common)/float64(len(wordsB)))
+
+	if tokenScore > editScore {
+		return tokenScore
+	}
+	return editScore
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// containsAny сообщает, встречается ли в s одно из слов или словосочетаний words целиком
+func containsAny(s string, words []string) bool {
+	s = " " + strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ") + " "
+	for _, w := range words {
+		if strings.Contains(s, " "+w+" ") {
+			return true
+		}
+	}
+	return false
+}
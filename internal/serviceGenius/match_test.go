@@ -0,0 +1,167 @@
+package servicegenius
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func hit(id int, title, artist string) searchHit {
+	h := searchHit{ID: id, Title: title}
+	h.PrimaryArtist.Name = artist
+	return h
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		min, max float64
+	}{
+		{a: "", b: "", min: 1, max: 1},
+		{a: "kino", b: "", min: 0, max: 0},
+		{a: "gruppa krovi", b: "gruppa krovi", min: 1, max: 1},
+		// Все слова запроса есть в результате, лишнее слово дает небольшой штраф
+		{a: "gruppa krovi", b: "gruppa krovi remastered", min: 0.9, max: 0.99},
+		// Опечатка: сходство по расстоянию Левенштейна
+		{a: "gruppa krovi", b: "grupa krovi", min: 0.9, max: 0.95},
+		{a: "zvezda po imeni solntse", b: "kukushka", min: 0, max: 0.3},
+	}
+	for _, tt := range tests {
+		if got := similarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("similarity(%q, %q) = %.3f, want within [%.2f, %.2f]", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "", b: "", want: 0},
+		{a: "abc", b: "", want: 3},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "кино", b: "кина", want: 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeForMatch(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Группа крови", want: "gruppa krovi"},
+		{in: "  AC/DC — Back in Black! ", want: "ac dc back in black"},
+		{in: "Кино", want: "kino"},
+	}
+	for _, tt := range tests {
+		if got := normalizeForMatch(tt.in); got != tt.want {
+			t.Errorf("normalizeForMatch(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	tests := []struct {
+		name          string
+		title, artist string
+		hits          []searchHit
+		wantOrder     []int
+		wantPenalties map[int][]string
+	}{
+		{
+			name:  "original above translation",
+			title: "Группа крови", artist: "Кино",
+			hits: []searchHit{
+				hit(1, "Кино - Группа крови (English Translation)", "Genius English Translations"),
+				hit(2, "Группа крови", "Кино"),
+			},
+			wantOrder:     []int{2, 1},
+			wantPenalties: map[int][]string{1: {"translation"}},
+		},
+		{
+			name:  "remix penalized unless requested",
+			title: "Blood Type", artist: "Kino",
+			hits: []searchHit{
+				hit(1, "Blood Type (Remix)", "Kino"),
+				hit(2, "Blood Type", "Kino"),
+			},
+			wantOrder:     []int{2, 1},
+			wantPenalties: map[int][]string{1: {"remix"}},
+		},
+		{
+			name:  "requested remix is not penalized",
+			title: "Blood Type Remix", artist: "Kino",
+			hits: []searchHit{
+				hit(1, "Blood Type", "Kino"),
+				hit(2, "Blood Type (Remix)", "Kino"),
+			},
+			wantOrder: []int{2, 1},
+		},
+		{
+			name:  "artist breaks the tie",
+			title: "Кукушка", artist: "Кино",
+			hits: []searchHit{
+				hit(1, "Кукушка", "Полина Гагарина"),
+				hit(2, "Кукушка", "Кино"),
+			},
+			wantOrder: []int{2, 1},
+		},
+		{
+			name:  "live and cover",
+			title: "Звезда по имени Солнце", artist: "Кино",
+			hits: []searchHit{
+				hit(1, "Звезда по имени Солнце (Live)", "Кино"),
+				hit(2, "Звезда по имени Солнце (Cover)", "Кино"),
+				hit(3, "Звезда по имени Солнце", "Кино"),
+			},
+			wantOrder:     []int{3, 1, 2},
+			wantPenalties: map[int][]string{1: {"live"}, 2: {"cover"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := rankCandidates(tt.title, tt.artist, tt.hits)
+
+			var order []int
+			for i, c := range candidates {
+				order = append(order, c.GeniusID)
+				if c.Score < 0 || c.Score > 1 || c.Score != math.Round(c.Score*1000)/1000 {
+					t.Errorf("candidate %d has score %v, want a value in [0, 1] rounded to 3 digits", c.GeniusID, c.Score)
+				}
+				if i > 0 && c.Score > candidates[i-1].Score {
+					t.Errorf("candidates are not sorted by score: %v", candidates)
+				}
+				if want := tt.wantPenalties[c.GeniusID]; !reflect.DeepEqual(c.Penalties, want) {
+					t.Errorf("candidate %d penalties = %v, want %v", c.GeniusID, c.Penalties, want)
+				}
+			}
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Fatalf("order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestContainsAny(t *testing.T) {
+	tests := []struct {
+		s     string
+		words []string
+		want  bool
+	}{
+		{s: "Song (Live at Wembley)", words: []string{"live"}, want: true},
+		// Слово должно встречаться целиком
+		{s: "Alive", words: []string{"live"}, want: false},
+		{s: "Song - Sped Up", words: []string{"sped up"}, want: true},
+		{s: "Песня (Перевод)", words: []string{"перевод"}, want: true},
+	}
+	for _, tt := range tests {
+		if got := containsAny(tt.s, tt.words); got != tt.want {
+			t.Errorf("containsAny(%q, %v) = %v, want %v", tt.s, tt.words, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,18 @@
 package servicegenius
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"musPlayer/internal/cache"
 	"musPlayer/internal/logger"
 	"musPlayer/models"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
-
-	"golang.org/x/net/html"
 )
 
 type GeniusService struct {
@@ -19,14 +20,42 @@ type GeniusService struct {
 	ClientSecret string
 	RedirectURI  string
 	AccessToken  string
+	AuthURL      string
+	TokenURL     string
+	Scope        string
+	BaseURL      string
+
+	// MatchThreshold — минимальная оценка, при которой лучший кандидат выбирается автоматически
+	MatchThreshold float64
+
+	client   *client
+	cache    *cache.Tiered
+	cacheCfg models.CacheConfig
+
+	tokenMu         sync.Mutex
+	tokenCheckedFor string
+	tokenCheckedAt  time.Time
+	tokenCheckErr   error
 }
 
-func NewGeniusService(clientID, clientSecret, redirectURI string) *GeniusService {
-	logger.Logger.Debug("Initializing GeniusService with clientID: ", clientID)
+// NewGeniusService создает клиент Genius. baseURL — адрес API (api.base_url),
+// cfg.Token используется как токен доступа до прохождения OAuth-авторизации.
+// Результаты поиска и тексты кешируются в c; c может быть nil
+func NewGeniusService(cfg models.GeniusConfig, baseURL string, c *cache.Tiered, cacheCfg models.CacheConfig) *GeniusService {
+	logger.Logger.Debug("Initializing GeniusService with clientID: ", cfg.ID)
 	return &GeniusService{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		RedirectURI:  redirectURI,
+		ClientID:       cfg.ID,
+		ClientSecret:   cfg.Secret,
+		RedirectURI:    cfg.RedirectURI,
+		AccessToken:    cfg.Token,
+		AuthURL:        cfg.AuthURL,
+		TokenURL:       cfg.TokenURL,
+		Scope:          cfg.Scope,
+		BaseURL:        strings.TrimRight(baseURL, "/"),
+		MatchThreshold: cfg.MatchThreshold,
+		client:         newClient(cfg),
+		cache:          c,
+		cacheCfg:       cacheCfg,
 	}
 }
 
@@ -38,16 +67,22 @@ func (g *GeniusService) RedirectUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authURL := fmt.Sprintf("https://api.genius.com/oauth/authorize?client_id=%s&redirect_uri=%s&response_type=code",
-		g.ClientID,
-		url.QueryEscape(g.RedirectURI),
-	)
+	params := url.Values{
+		"client_id":     {g.ClientID},
+		"redirect_uri":  {g.RedirectURI},
+		"response_type": {"code"},
+	}
+	if g.Scope != "" {
+		params.Set("scope", g.Scope)
+	}
+	authURL := g.AuthURL + "?" + params.Encode()
 	logger.Logger.Debug("Redirecting to auth URL: ", authURL)
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
-func (g *GeniusService) GetAccessToken(code string) error {
-	logger.Logger.Debug("Requesting access token with code: ", code)
+func (g *GeniusService) GetAccessToken(ctx context.Context, code string) error {
+	log := logger.FromContext(ctx)
+	log.Debug("Requesting access token")
 
 	form := url.Values{
 		"code":          {code},
@@ -57,178 +92,158 @@ func (g *GeniusService) GetAccessToken(code string) error {
 		"grant_type":    {"authorization_code"},
 	}
 
-	resp, err := http.PostForm("https://api.genius.com/oauth/token", form)
+	// Код авторизации одноразовый, поэтому POST не повторяется
+	resp, err := g.client.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.TokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
 	if err != nil {
-		logger.Logger.Error("Failed to request access token: ", err)
-		return err
+		log.Error("Failed to request access token: ", err)
+		return fmt.Errorf("failed to fetch access token: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		errMsg := fmt.Sprintf("failed to fetch access token: %s", resp.Status)
-		logger.Logger.Error(errMsg)
-		return fmt.Errorf(errMsg)
-	}
-
 	var result struct {
 		AccessToken string `json:"access_token"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Logger.Error("Failed to decode access token response: ", err)
+		log.Error("Failed to decode access token response: ", err)
 		return err
 	}
 
+	g.tokenMu.Lock()
 	g.AccessToken = result.AccessToken
-	logger.Logger.Debug("Successfully obtained access token")
+	g.tokenMu.Unlock()
+	log.Debug("Successfully obtained access token")
 	return nil
 }
 
-// SearchSong ищет песню по названию и заполняет структуру Song
-func (g *GeniusService) SearchSong(title, artist string) (*models.Song, error) {
-	logger.Logger.Debug("Searching for song with title: ", title, " and artist: ", artist)
-	query := url.QueryEscape(title)
+// token возвращает текущий токен доступа
+func (g *GeniusService) token() string {
+	g.tokenMu.Lock()
+	defer g.tokenMu.Unlock()
+	return g.AccessToken
+}
+
+// get выполняет авторизованный GET-запрос к Genius через общий клиент
+func (g *GeniusService) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	return g.client.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+g.token())
+		return req, nil
+	})
+}
+
+// searchHit — результат поиска Genius
+type searchHit struct {
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	PrimaryArtist struct {
+		Name string `json:"name"`
+	} `json:"primary_artist"`
+	ReleaseDate string `json:"release_date"`
+	URL         string `json:"url"`
+}
+
+// SearchCandidates ищет песню на Genius и возвращает всех кандидатов, упорядоченных по оценке соответствия
+func (g *GeniusService) SearchCandidates(ctx context.Context, title, artist string) ([]Candidate, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("Searching for song with title: ", title, " and artist: ", artist)
+	query := title
 	if artist != "" {
-		query += " " + url.QueryEscape(artist)
+		query += " " + artist
 	}
 
-	url := fmt.Sprintf("https://api.genius.com/search?q=%s", query)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+g.AccessToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	body, err := g.cached(ctx, searchCachePrefix+normalizeQuery(query), g.cacheCfg.SearchTTL, func(ctx context.Context) ([]byte, error) {
+		resp, err := g.get(ctx, fmt.Sprintf("%s/search?%s", g.BaseURL, url.Values{"q": {query}}.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	})
 	if err != nil {
-		logger.Logger.Error("Failed to perform search: ", err)
+		log.Error("Failed to perform search: ", err)
 		return nil, fmt.Errorf("failed to perform search: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch song: %s", resp.Status)
-	}
 
 	var result struct {
 		Response struct {
 			Hits []struct {
-				Result struct {
-					ID            int    `json:"id"`
-					Title         string `json:"title"`
-					PrimaryArtist struct {
-						Name string `json:"name"`
-					} `json:"primary_artist"`
-					ReleaseDate string `json:"release_date"`
-					URL         string `json:"url"`
-				} `json:"result"`
+				Result searchHit `json:"result"`
 			} `json:"hits"`
 		} `json:"response"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Logger.Error("Failed to decode search response: ", err)
+	if err := json.Unmarshal(body, &result); err != nil {
+		log.Error("Failed to decode search response: ", err)
 		return nil, fmt.Errorf("failed to decode search response: %w", err)
 	}
 
 	if len(result.Response.Hits) == 0 {
-		return nil, fmt.Errorf("no song found for title: %s and artist: %s", title, artist)
+		return nil, fmt.Errorf("%w: title %q, artist %q", ErrNotFound, title, artist)
 	}
 
-	songID := result.Response.Hits[0].Result.ID
-	songText, err := g.GetSongText(result.Response.Hits[0].Result.URL)
-	if err != nil {
-		return nil, err
-	}
-
-	song := &models.Song{
-		ID:          songID,
-		GroupName:   result.Response.Hits[0].Result.PrimaryArtist.Name,
-		SongName:    result.Response.Hits[0].Result.Title,
-		ReleaseDate: result.Response.Hits[0].Result.ReleaseDate,
-		Link:        result.Response.Hits[0].Result.URL,
-		Text:        songText,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	hits := make([]searchHit, 0, len(result.Response.Hits))
+	for _, h := range result.Response.Hits {
+		hits = append(hits, h.Result)
 	}
 
-	return song, nil
+	candidates := rankCandidates(title, artist, hits)
+	log.Debugf("Ranked %d candidates, best score %.3f", len(candidates), candidates[0].Score)
+	return candidates, nil
 }
 
-func extractTextFromHTML(htmlBody string) (string, error) {
-	doc, err := html.Parse(strings.NewReader(htmlBody))
+// SearchSong ищет песню по названию и исполнителю и загружает лучшего кандидата по его идентификатору.
+// Если ни один кандидат не набрал порог уверенности, возвращается *AmbiguousMatchError со списком кандидатов
+func (g *GeniusService) SearchSong(ctx context.Context, title, artist string) (*models.Song, error) {
+	candidates, err := g.SearchCandidates(ctx, title, artist)
 	if err != nil {
-		return "", fmt.Errorf("ошибка парсинга HTML: %v", err)
-	}
-
-	var text string
-	var found bool
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.Type == html.ElementNode && n.Data == "div" {
-			for _, attr := range n.Attr {
-				if attr.Key == "data-lyrics-container" && attr.Val == "true" {
-					found = true
-					for c := n.FirstChild; c != nil; c = c.NextSibling {
-						if c.Type == html.TextNode {
-							line := strings.TrimSpace(c.Data)
-							if line != "" {
-								text += line + "\n"
-							}
-						} else if c.Type == html.ElementNode && c.Data == "br" {
-							text += "\n"
-						}
-					}
-					return
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
-	}
-	f(doc)
-
-	if !found {
-		return "", fmt.Errorf("не удалось найти элемент с data-lyrics-container")
+		return nil, err
 	}
 
-	if text == "" {
-		return "", fmt.Errorf("не удалось извлечь текст из найденного элемента")
+	if candidates[0].Score < g.MatchThreshold {
+		return nil, &AmbiguousMatchError{Candidates: candidates}
 	}
-
-	text = strings.TrimSpace(text)
-	text = strings.ReplaceAll(text, "\n\n", "\n")
-
-	return text, nil
+	return g.GetSongByID(ctx, candidates[0].GeniusID)
 }
 
-// GetSongText получает текст песни по идентификатору песни
-func (g *GeniusService) GetSongText(url string) (string, error) {
-	logger.Logger.Debug("Fetching song text from URL: ", url)
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+g.AccessToken)
+// GetSongText получает текст песни со страницы Genius
+func (g *GeniusService) GetSongText(ctx context.Context, url string) (string, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("Fetching song text from URL: ", url)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		logger.Logger.Error("Failed to perform song text request: ", err)
-		return "", fmt.Errorf("failed to perform song text request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch song text: %s", resp.Status)
-	}
+	text, err := g.cached(ctx, lyricsCacheKey(url), g.cacheCfg.LyricsTTL, func(ctx context.Context) ([]byte, error) {
+		resp, err := g.get(ctx, url)
+		if err != nil {
+			log.Error("Failed to perform song text request: ", err)
+			return nil, fmt.Errorf("failed to perform song text request: %w", err)
+		}
+		defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Logger.Error("Failed to read response body: ", err)
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			log.Error("Failed to read response body: ", err)
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
 
-	songText, err := extractTextFromHTML(string(body))
+		songText, err := ExtractLyrics(string(body))
+		if err != nil {
+			log.Error("Failed to extract song text: ", err)
+			return nil, fmt.Errorf("failed to extract song text: %w", err)
+		}
+		return []byte(songText), nil
+	})
 	if err != nil {
-		logger.Logger.Error("Failed to extract song text: ", err)
-		return "", fmt.Errorf("failed to extract song text: %w", err)
+		return "", err
 	}
 
-	return songText, nil
+	return string(text), nil
 }
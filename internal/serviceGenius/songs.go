@@ -0,0 +1,146 @@
+package servicegenius
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const songCachePrefix = CachePrefix + "song:"
+
+// songIDPattern находит идентификатор песни в метаданных страницы Genius:
+// <meta name="newrelic-resource-path" content="/songs/123"> или <meta property="al:ios:url" content="genius://songs/123">
+var songIDPattern = regexp.MustCompile(`content="(?:genius:/)?/songs/(\d+)"`)
+
+// songMetadata — каноничные данные песни из GET /songs/{id}
+type songMetadata struct {
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	URL           string `json:"url"`
+	ReleaseDate   string `json:"release_date"`
+	PrimaryArtist struct {
+		Name string `json:"name"`
+	} `json:"primary_artist"`
+	Album *struct {
+		Name string `json:"name"`
+	} `json:"album"`
+}
+
+// GetSongByID загружает каноничные метаданные песни из API Genius и текст с ее страницы
+func (g *GeniusService) GetSongByID(ctx context.Context, geniusID int) (*models.Song, error) {
+	meta, err := g.songMetadata(ctx, geniusID)
+	if err != nil {
+		return nil, err
+	}
+
+	songText, err := g.GetSongText(ctx, meta.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return meta.toSong(songText), nil
+}
+
+// GetSongByURL загружает песню по адресу страницы Genius: идентификатор берется из метаданных страницы,
+// каноничные данные — из API, текст — с этой же страницы
+func (g *GeniusService) GetSongByURL(ctx context.Context, pageURL string) (*models.Song, error) {
+	log := logger.FromContext(ctx)
+
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Scheme != "https" || (u.Host != "genius.com" && !strings.HasSuffix(u.Host, ".genius.com")) {
+		return nil, fmt.Errorf("%w: %q is not a genius.com page", ErrInvalidURL, pageURL)
+	}
+
+	resp, err := g.get(ctx, u.String())
+	if err != nil {
+		log.Error("Failed to fetch song page: ", err)
+		return nil, fmt.Errorf("failed to fetch song page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read song page: %w", err)
+	}
+
+	match := songIDPattern.FindSubmatch(body)
+	if match == nil {
+		return nil, fmt.Errorf("%w: %q is not a song page", ErrInvalidURL, pageURL)
+	}
+	geniusID, err := strconv.Atoi(string(match[1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid song id on page %q: %w", pageURL, err)
+	}
+	log.Debugf("Resolved %s to genius song %d", pageURL, geniusID)
+
+	meta, err := g.songMetadata(ctx, geniusID)
+	if err != nil {
+		return nil, err
+	}
+
+	songText, err := ExtractLyrics(string(body))
+	if err != nil {
+		log.Error("Failed to extract song text: ", err)
+		return nil, fmt.Errorf("failed to extract song text: %w", err)
+	}
+
+	return meta.toSong(songText), nil
+}
+
+// songMetadata запрашивает GET /songs/{id}
+func (g *GeniusService) songMetadata(ctx context.Context, geniusID int) (*songMetadata, error) {
+	log := logger.FromContext(ctx)
+	log.Debugf("Fetching genius song %d", geniusID)
+
+	body, err := g.cached(ctx, songCachePrefix+strconv.Itoa(geniusID), g.cacheCfg.SearchTTL, func(ctx context.Context) ([]byte, error) {
+		resp, err := g.get(ctx, fmt.Sprintf("%s/songs/%d", g.BaseURL, geniusID))
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return io.ReadAll(resp.Body)
+	})
+	if err != nil {
+		log.Error("Failed to fetch song metadata: ", err)
+		return nil, fmt.Errorf("failed to fetch song %d: %w", geniusID, err)
+	}
+
+	var result struct {
+		Response struct {
+			Song songMetadata `json:"song"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode song response: %w", err)
+	}
+	if result.Response.Song.ID == 0 || result.Response.Song.URL == "" {
+		return nil, fmt.Errorf("%w: genius id %d", ErrNotFound, geniusID)
+	}
+
+	return &result.Response.Song, nil
+}
+
+func (m *songMetadata) toSong(text string) *models.Song {
+	song := &models.Song{
+		ID:          m.ID,
+		GroupName:   m.PrimaryArtist.Name,
+		SongName:    m.Title,
+		ReleaseDate: m.ReleaseDate,
+		Link:        m.URL,
+		Text:        text,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if m.Album != nil {
+		song.Album = m.Album.Name
+	}
+	return song
+}
@@ -0,0 +1,120 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"musPlayer/internal/cache"
+	"musPlayer/internal/logger"
+	"musPlayer/pkg/lyricstats"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+	"time"
+)
+
+// ErrArtistNotFound возвращается, когда в базе нет песен исполнителя
+var ErrArtistNotFound = errors.New("artist not found")
+
+// Число слов в топе
+const analysisTopWords = 20
+
+// Префиксы ключей кеша анализа
+const (
+	songAnalysisPrefix   = "analysis:song:"
+	artistAnalysisPrefix = "analysis:artist:"
+)
+
+// analysisCache хранит результаты анализа в памяти процесса. Его сбрасывают все операции, меняющие тексты песен
+type analysisCache struct {
+	lru *cache.LRU
+	ttl time.Duration
+}
+
+func newAnalysisCache(size int, ttl time.Duration) *analysisCache {
+	return &analysisCache{lru: cache.NewLRU(size), ttl: ttl}
+}
+
+// invalidateSong сбрасывает анализ песни и всех исполнителей: название группы могло измениться
+func (c *analysisCache) invalidateSong(songID int) {
+	c.lru.Purge(songAnalysisKey(songID))
+	c.lru.Purge(artistAnalysisPrefix)
+}
+
+func (c *analysisCache) songChanged(_ context.Context, songID int) {
+	c.invalidateSong(songID)
+}
+
+func (c *analysisCache) songDeleted(_ context.Context, songID int) {
+	c.invalidateSong(songID)
+}
+
+func songAnalysisKey(songID int) string {
+	return fmt.Sprintf("%s%d:", songAnalysisPrefix, songID)
+}
+
+type analysisService struct {
+	repo  postgresrepo.SongRepository
+	cache *analysisCache
+}
+
+func NewAnalysisService(repo postgresrepo.SongRepository, c *analysisCache) AnalysisService {
+	return &analysisService{
+		repo:  repo,
+		cache: c,
+	}
+}
+
+// AnalyzeSong считает статистику текста песни
+func (s *analysisService) AnalyzeSong(ctx context.Context, songID int) (lyricstats.Analysis, error) {
+	return s.cached(ctx, songAnalysisKey(songID), func() (lyricstats.Analysis, error) {
+		text, err := s.repo.GetSongText(ctx, songID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return lyricstats.Analysis{}, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+			}
+			return lyricstats.Analysis{}, err
+		}
+		return lyricstats.Analyze([]string{strings.ReplaceAll(text, "\\n", "\n")}, analysisTopWords), nil
+	})
+}
+
+// AnalyzeArtist считает статистику по всем песням исполнителя; имя сравнивается без учета регистра
+func (s *analysisService) AnalyzeArtist(ctx context.Context, name string) (lyricstats.Analysis, error) {
+	key := artistAnalysisPrefix + strings.ToLower(strings.TrimSpace(name))
+	return s.cached(ctx, key, func() (lyricstats.Analysis, error) {
+		texts, err := s.repo.GetArtistTexts(ctx, name)
+		if err != nil {
+			return lyricstats.Analysis{}, err
+		}
+		if len(texts) == 0 {
+			return lyricstats.Analysis{}, fmt.Errorf("%w: %q", ErrArtistNotFound, name)
+		}
+		for i := range texts {
+			texts[i] = strings.ReplaceAll(texts[i], "\\n", "\n")
+		}
+		return lyricstats.Analyze(texts, analysisTopWords), nil
+	})
+}
+
+func (s *analysisService) cached(ctx context.Context, key string, compute func() (lyricstats.Analysis, error)) (lyricstats.Analysis, error) {
+	var a lyricstats.Analysis
+	if data, ok := s.cache.lru.Get(key); ok {
+		if err := json.Unmarshal(data, &a); err == nil {
+			return a, nil
+		}
+	}
+
+	startTime := time.Now()
+	a, err := compute()
+	if err != nil {
+		return a, err
+	}
+	logger.FromContext(ctx).Debugf("Computed %s in %s", key, time.Since(startTime))
+
+	if data, err := json.Marshal(a); err == nil {
+		s.cache.lru.Set(key, data, s.cache.ttl)
+	}
+	return a, nil
+}
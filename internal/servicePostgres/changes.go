@@ -0,0 +1,59 @@
+package servicePostgres
+
+import (
+	"context"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"time"
+)
+
+// Как часто удаляются устаревшие надгробия ленты изменений
+const tombstonePurgeEvery = time.Hour
+
+type changeFeedService struct {
+	repo postgresrepo.ChangeFeedRepository
+	cfg  models.ChangesConfig
+}
+
+func NewChangeFeedService(repo postgresrepo.ChangeFeedRepository, cfg models.ChangesConfig) ChangeFeedService {
+	return &changeFeedService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+func (s *changeFeedService) ListChanges(ctx context.Context, since int64, limit int) (models.ChangeFeedPage, error) {
+	page, err := s.repo.ListSongChanges(ctx, since, limit)
+	if err != nil {
+		return page, err
+	}
+	if page.Changes == nil {
+		page.Changes = []models.SongChange{}
+	}
+	if page.ResyncRequired {
+		logger.FromContext(ctx).Infof("Change feed cursor %d is too old, resync required", since)
+	}
+	return page, nil
+}
+
+// RunTombstonePurger удаляет надгробия старше changes.retention, пока не отменен ctx
+func (s *changeFeedService) RunTombstonePurger(ctx context.Context) error {
+	ticker := time.NewTicker(tombstonePurgeEvery)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.repo.PurgeTombstones(ctx, time.Now().Add(-s.cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to purge change feed tombstones: %v", err)
+		} else if n > 0 {
+			logger.Logger.Infof("Purged %d change feed tombstones", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotInLibrary возвращается, когда песни нет в библиотеке пользователя
+	ErrNotInLibrary = errors.New("song is not in library")
+	// ErrInvalidRating возвращается для оценки вне диапазона 1–5
+	ErrInvalidRating = errors.New("rating must be between 1 and 5")
+	// ErrInvalidSort возвращается для неизвестного поля сортировки
+	ErrInvalidSort = errors.New("invalid sort field")
+	// ErrNoteTooLong возвращается для слишком длинной заметки
+	ErrNoteTooLong = errors.New("note is too long")
+)
+
+const maxNoteLength = 4000
+
+type libraryService struct {
+	repo postgresrepo.LibraryRepository
+}
+
+func NewLibraryService(repo postgresrepo.LibraryRepository) LibraryService {
+	return &libraryService{
+		repo: repo,
+	}
+}
+
+// Песни библиотеки пользователя; favoritesOnly оставляет только избранные
+func (s *libraryService) ListLibrary(ctx context.Context, userID string, filter models.SongFilter, favoritesOnly bool, limit, offset int) ([]models.LibrarySong, error) {
+	filter, err := normalizeSongFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	songs, err := s.repo.ListLibrary(ctx, userID, filter, favoritesOnly, limit, offset)
+	if errors.Is(err, postgresrepo.ErrInvalidSort) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSort, filter.Sort)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing library: ", err)
+		return nil, err
+	}
+	return songs, nil
+}
+
+// Добавление песни в библиотеку. Повторное добавление меняет только заметку, если она передана
+func (s *libraryService) AddToLibrary(ctx context.Context, userID string, songID int, note *string) error {
+	if note != nil && utf8.RuneCountInString(*note) > maxNoteLength {
+		return fmt.Errorf("%w: at most %d characters", ErrNoteTooLong, maxNoteLength)
+	}
+
+	err := s.repo.AddToLibrary(ctx, userID, songID, note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error adding song to library: ", err)
+		return err
+	}
+
+	logger.FromContext(ctx).Debugf("Song %d added to library", songID)
+	return nil
+}
+
+// Удаление песни из библиотеки
+func (s *libraryService) RemoveFromLibrary(ctx context.Context, userID string, songID int) error {
+	err := s.repo.RemoveFromLibrary(ctx, userID, songID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: id %d", ErrNotInLibrary, songID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error removing song from library: ", err)
+		return err
+	}
+
+	logger.FromContext(ctx).Debugf("Song %d removed from library", songID)
+	return nil
+}
+
+// Добавление в избранное или снятие отметки
+func (s *libraryService) SetFavorite(ctx context.Context, userID string, songID int, favorite bool) error {
+	err := s.repo.SetFavorite(ctx, userID, songID, favorite)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error updating favorite: ", err)
+		return err
+	}
+	return nil
+}
+
+// Оценка песни от 1 до 5; nil снимает оценку. Возвращает пересчитанную среднюю оценку
+func (s *libraryService) RateSong(ctx context.Context, userID string, songID int, rating *int) (models.SongRating, error) {
+	if rating != nil && (*rating < 1 || *rating > 5) {
+		return models.SongRating{}, ErrInvalidRating
+	}
+
+	if err := s.repo.SetRating(ctx, userID, songID, rating); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SongRating{}, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+		}
+		logger.FromContext(ctx).Error("Error saving rating: ", err)
+		return models.SongRating{}, err
+	}
+
+	return s.GetRating(ctx, userID, songID)
+}
+
+// Средняя оценка песни и оценка пользователя
+func (s *libraryService) GetRating(ctx context.Context, userID string, songID int) (models.SongRating, error) {
+	rating, err := s.repo.GetRating(ctx, userID, songID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SongRating{}, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error retrieving rating: ", err)
+		return models.SongRating{}, err
+	}
+	return rating, nil
+}
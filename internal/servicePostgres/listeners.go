@@ -0,0 +1,23 @@
+package servicePostgres
+
+import "context"
+
+// songListener получает уведомления об изменении и удалении песен, чтобы обновить производные данные (кеш анализа, индекс похожих песен)
+type songListener interface {
+	songChanged(ctx context.Context, songID int)
+	songDeleted(ctx context.Context, songID int)
+}
+
+type songListeners []songListener
+
+func (ls songListeners) changed(ctx context.Context, songID int) {
+	for _, l := range ls {
+		l.songChanged(ctx, songID)
+	}
+}
+
+func (ls songListeners) deleted(ctx context.Context, songID int) {
+	for _, l := range ls {
+		l.songDeleted(ctx, songID)
+	}
+}
@@ -0,0 +1,426 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	servicegenius "musPlayer/internal/serviceGenius"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidListens возвращается, когда тело submit-listens не соответствует формату ListenBrainz
+	ErrInvalidListens = errors.New("invalid listens")
+	// ErrNothingPlaying возвращается, когда пользователь сейчас ничего не слушает
+	ErrNothingPlaying = errors.New("nothing is playing")
+	// ErrIngestItemNotFound возвращается, когда записи очереди с таким идентификатором нет
+	ErrIngestItemNotFound = errors.New("ingest queue item not found")
+)
+
+// Ограничения ListenBrainz
+const (
+	maxListensPerRequest = 1000
+	maxListenSize        = 10240
+	// Самая ранняя допустимая метка listened_at (1 октября 2002 года)
+	minListenTimestamp = 1033430400
+)
+
+const (
+	maxListenNameLength = 512
+	// Сколько раз повторяется поиск трека, завершившийся ошибкой
+	maxIngestAttempts = 5
+)
+
+// ListenSubmitResult — итог приема прослушиваний
+type ListenSubmitResult struct {
+	Status   string `json:"status"`
+	Accepted int    `json:"accepted"`
+	Matched  int    `json:"matched"`
+}
+
+type listenService struct {
+	repo      postgresrepo.ListenRepository
+	tx        Transactor
+	listeners songListeners
+	source    SongSource
+	cfg       models.ListensConfig
+}
+
+func NewListenService(repo postgresrepo.ListenRepository, tx Transactor, listeners songListeners, source SongSource, cfg models.ListensConfig) ListenService {
+	return &listenService{
+		repo:      repo,
+		tx:        tx,
+		listeners: listeners,
+		source:    source,
+		cfg:       cfg,
+	}
+}
+
+// SubmitListens принимает прослушивания в формате submit-listens ListenBrainz.
+// single и import сохраняются в историю, playing_now только запоминается как текущий трек
+func (s *listenService) SubmitListens(ctx context.Context, userID string, sub models.ListenSubmission) (ListenSubmitResult, error) {
+	listens, err := parseSubmission(sub, time.Now())
+	if err != nil {
+		return ListenSubmitResult{}, err
+	}
+	result := ListenSubmitResult{Status: "ok"}
+
+	if sub.ListenType == models.ListenTypePlayingNow {
+		l := listens[0]
+		ttl := s.cfg.PlayingNowTTL
+		if l.DurationMs != nil && *l.DurationMs > 0 {
+			ttl = time.Duration(*l.DurationMs) * time.Millisecond
+		}
+		if err := s.repo.SetPlayingNow(ctx, userID, l, ttl); err != nil {
+			logger.FromContext(ctx).Error("Error saving playing now: ", err)
+			return ListenSubmitResult{}, err
+		}
+		return result, nil
+	}
+
+	startTime := time.Now()
+	result.Accepted, result.Matched, err = s.repo.SaveListens(ctx, userID, listens)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error saving listens: ", err)
+		return ListenSubmitResult{}, err
+	}
+
+	logger.FromContext(ctx).Infof("Listens submitted (%s): %d received, %d new, %d matched, execution time: %s",
+		sub.ListenType, len(listens), result.Accepted, result.Matched, time.Since(startTime))
+	return result, nil
+}
+
+// parseSubmission проверяет тело по правилам ListenBrainz и переводит его в прослушивания
+func parseSubmission(sub models.ListenSubmission, now time.Time) ([]models.Listen, error) {
+	switch sub.ListenType {
+	case models.ListenTypeSingle, models.ListenTypePlayingNow:
+		if len(sub.Payload) != 1 {
+			return nil, fmt.Errorf("%w: %s requires exactly one listen in payload", ErrInvalidListens, sub.ListenType)
+		}
+	case models.ListenTypeImport:
+		if len(sub.Payload) == 0 || len(sub.Payload) > maxListensPerRequest {
+			return nil, fmt.Errorf("%w: import payload must contain 1 to %d listens", ErrInvalidListens, maxListensPerRequest)
+		}
+	default:
+		return nil, fmt.Errorf("%w: listen_type must be single, playing_now or import", ErrInvalidListens)
+	}
+
+	listens := make([]models.Listen, 0, len(sub.Payload))
+	for i, p := range sub.Payload {
+		if raw, err := json.Marshal(p); err != nil || len(raw) > maxListenSize {
+			return nil, fmt.Errorf("%w: listen %d is larger than %d bytes", ErrInvalidListens, i, maxListenSize)
+		}
+
+		l := models.Listen{
+			ArtistName:     normalizeListenName(p.TrackMetadata.ArtistName),
+			TrackName:      normalizeListenName(p.TrackMetadata.TrackName),
+			ReleaseName:    normalizeListenName(p.TrackMetadata.ReleaseName),
+			AdditionalInfo: p.TrackMetadata.AdditionalInfo,
+		}
+		if l.ArtistName == "" || l.TrackName == "" {
+			return nil, fmt.Errorf("%w: listen %d: artist_name and track_name are required", ErrInvalidListens, i)
+		}
+		if utf8.RuneCountInString(l.ArtistName) > maxListenNameLength || utf8.RuneCountInString(l.TrackName) > maxListenNameLength ||
+			utf8.RuneCountInString(l.ReleaseName) > maxListenNameLength {
+			return nil, fmt.Errorf("%w: listen %d: names must be at most %d characters", ErrInvalidListens, i, maxListenNameLength)
+		}
+
+		if sub.ListenType == models.ListenTypePlayingNow {
+			if p.ListenedAt != nil {
+				return nil, fmt.Errorf("%w: playing_now listens must not have listened_at", ErrInvalidListens)
+			}
+		} else {
+			if p.ListenedAt == nil {
+				return nil, fmt.Errorf("%w: listen %d: listened_at is required", ErrInvalidListens, i)
+			}
+			l.ListenedAt = time.Unix(*p.ListenedAt, 0).UTC()
+			if *p.ListenedAt < minListenTimestamp || l.ListenedAt.After(now.Add(24*time.Hour)) {
+				return nil, fmt.Errorf("%w: listen %d: listened_at %d is out of range", ErrInvalidListens, i, *p.ListenedAt)
+			}
+		}
+
+		l.DurationMs = listenDuration(l.AdditionalInfo)
+		l.RecordingMBID, _ = l.AdditionalInfo["recording_mbid"].(string)
+		l.SubmissionClient, _ = l.AdditionalInfo["submission_client"].(string)
+		if len(l.RecordingMBID) > 36 || len(l.SubmissionClient) > 255 {
+			return nil, fmt.Errorf("%w: listen %d: invalid recording_mbid or submission_client", ErrInvalidListens, i)
+		}
+
+		listens = append(listens, l)
+	}
+
+	return listens, nil
+}
+
+// listenDuration берет длительность из additional_info: duration_ms или duration в секундах
+func listenDuration(info map[string]interface{}) *int {
+	if v, ok := info["duration_ms"].(float64); ok && v > 0 {
+		ms := int(v)
+		return &ms
+	}
+	if v, ok := info["duration"].(float64); ok && v > 0 {
+		ms := int(v * 1000)
+		return &ms
+	}
+	return nil
+}
+
+func normalizeListenName(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ListListens возвращает историю прослушиваний по правилам ListenBrainz: без границ — последние count прослушиваний,
+// с maxTS — прослушивания до этого момента, с minTS — ближайшие после него. Результат всегда от новых к старым
+func (s *listenService) ListListens(ctx context.Context, userID string, minTS, maxTS *time.Time, count int) ([]models.Listen, error) {
+	after := time.Unix(0, 0)
+	before := time.Now().Add(24 * time.Hour)
+	if minTS != nil {
+		after = *minTS
+	}
+	if maxTS != nil {
+		before = *maxTS
+	}
+
+	ascending := minTS != nil && maxTS == nil
+	listens, err := s.repo.ListListens(ctx, userID, after, before, count, ascending)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing listens: ", err)
+		return nil, err
+	}
+	if ascending {
+		for i, j := 0, len(listens)-1; i < j; i, j = i+1, j-1 {
+			listens[i], listens[j] = listens[j], listens[i]
+		}
+	}
+	return listens, nil
+}
+
+// PlayingNow возвращает трек, который пользователь слушает сейчас
+func (s *listenService) PlayingNow(ctx context.Context, userID string) (models.PlayingNow, error) {
+	p, err := s.repo.GetPlayingNow(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrNothingPlaying
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error retrieving playing now: ", err)
+	}
+	return p, err
+}
+
+// SongPlayCounts возвращает самые прослушиваемые треки пользователя за период
+func (s *listenService) SongPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.SongPlayCount, error) {
+	counts, err := s.repo.SongPlayCounts(ctx, userID, period)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting song plays: ", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ArtistPlayCounts возвращает самых прослушиваемых исполнителей пользователя за период
+func (s *listenService) ArtistPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.ArtistPlayCount, error) {
+	counts, err := s.repo.ArtistPlayCounts(ctx, userID, period)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting artist plays: ", err)
+		return nil, err
+	}
+	return counts, nil
+}
+
+// ListenStreaks считает текущую и самую длинную серии дней с прослушиваниями в часовом поясе loc
+func (s *listenService) ListenStreaks(ctx context.Context, userID string, loc *time.Location) (models.ListenStreaks, error) {
+	streaks, err := s.repo.ListenStreaks(ctx, userID, loc.String())
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting listen streaks: ", err)
+		return models.ListenStreaks{}, err
+	}
+
+	result := models.ListenStreaks{TimeZone: loc.String()}
+	now := time.Now().In(loc)
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
+	for i := range streaks {
+		streak := streaks[i]
+		result.ActiveDays += streak.Days
+		// Серии отсортированы от последней, поэтому текущей может быть только первая
+		if i == 0 && (streak.End == today || streak.End == yesterday) {
+			result.Current = &streak
+		}
+		if result.Longest == nil || streak.Days > result.Longest.Days {
+			result.Longest = &streak
+		}
+	}
+	return result, nil
+}
+
+// IngestQueue возвращает треки из прослушиваний, которых нет в каталоге
+func (s *listenService) IngestQueue(ctx context.Context, status string, limit, offset int) ([]models.IngestQueueItem, error) {
+	items, err := s.repo.ListIngestQueue(ctx, status, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing ingest queue: ", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// ResolveIngest вручную сопоставляет трек из очереди с песней каталога, например когда поиск нашел несколько кандидатов
+func (s *listenService) ResolveIngest(ctx context.Context, queueID, songID int) (int64, error) {
+	linked, err := s.repo.ResolveIngest(ctx, queueID, songID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("%w: id %d", ErrIngestItemNotFound, queueID)
+	case errors.Is(err, postgresrepo.ErrSongReference):
+		return 0, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+	case err != nil:
+		logger.FromContext(ctx).Error("Error resolving ingest queue item: ", err)
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Infof("Ingest queue item %d resolved to song %d, %d listens linked", queueID, songID, linked)
+	return linked, nil
+}
+
+// RunIngester периодически ищет на Genius треки из очереди, добавляет найденные песни в каталог
+// и сопоставляет с ними прослушивания. Запросы к Genius идут не чаще одного в IngestRequestDelay
+func (s *listenService) RunIngester(ctx context.Context) error {
+	if !s.cfg.IngestEnabled {
+		logger.Logger.Info("Listen ingester is disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.IngestInterval)
+	defer ticker.Stop()
+
+	for {
+		s.ingestBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *listenService) ingestBatch(ctx context.Context) {
+	items, err := s.repo.NextIngestBatch(ctx, s.cfg.IngestBatchSize, maxIngestAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to list unmatched listens: %v", err)
+		}
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+	logger.Logger.Infof("Looking up %d unmatched tracks", len(items))
+
+	limiter := time.NewTicker(s.cfg.IngestRequestDelay)
+	defer limiter.Stop()
+
+	for i, item := range items {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-limiter.C:
+			}
+		}
+
+		if err := s.ingest(ctx, item); err != nil {
+			if isTransient(err) {
+				logger.Logger.Warnf("Stopping ingest batch: %v", err)
+				return
+			}
+			logger.Logger.Warnf("Ingest of %q by %q failed: %v", item.TrackName, item.ArtistName, err)
+		}
+	}
+}
+
+// ingest находит трек в каталоге или на Genius и сопоставляет с ним прослушивания
+func (s *listenService) ingest(ctx context.Context, item models.IngestQueueItem) error {
+	// Песню могли добавить вручную после того, как трек попал в очередь
+	songID, err := s.repo.FindSongByArtistTitle(ctx, item.ArtistName, item.TrackName)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var song *models.Song
+	if songID == 0 {
+		if song, err = s.source.SearchSong(ctx, item.TrackName, item.ArtistName); err != nil {
+			return s.markIngestFailure(ctx, item, err)
+		}
+	}
+
+	// Новая песня и сопоставление прослушиваний сохраняются вместе: при ошибке в каталоге не остается
+	// песни, на которую не перенесены прослушивания очереди
+	var added bool
+	var linked int64
+	err = s.tx.WithTx(ctx, func(r *postgresrepo.Repository) (err error) {
+		added = false
+		if song != nil {
+			// Поиск мог вернуть песню, которая уже есть в каталоге под другим названием
+			songID, err = r.FindSongByLink(ctx, song.Link)
+			if errors.Is(err, sql.ErrNoRows) {
+				added = true
+				songID, err = r.AddSong(ctx, postgresrepo.AddSongParams{
+					SongId:      song.ID,
+					GroupName:   song.GroupName,
+					SongName:    song.SongName,
+					Text:        song.Text,
+					Link:        song.Link,
+					ReleaseDate: song.ReleaseDate,
+					Album:       song.Album,
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		linked, err = r.ResolveIngest(ctx, item.ID, songID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		s.listeners.changed(ctx, songID)
+	}
+	logger.Logger.Infof("Track %q by %q matched to song %d, %d listens linked", item.TrackName, item.ArtistName, songID, linked)
+	return nil
+}
+
+// markIngestFailure записывает результат неудачного поиска. Временные ошибки Genius не считаются попыткой
+func (s *listenService) markIngestFailure(ctx context.Context, item models.IngestQueueItem, err error) error {
+	if isTransient(err) {
+		return err
+	}
+
+	var ambiguous *servicegenius.AmbiguousMatchError
+	status, retryAfter := models.IngestStatusFailed, time.Duration(0)
+	switch {
+	case errors.Is(err, servicegenius.ErrNotFound):
+		status = models.IngestStatusNotFound
+	case errors.As(err, &ambiguous):
+		status = models.IngestStatusAmbiguous
+	default:
+		// Пауза удваивается с каждой попыткой; после maxIngestAttempts запись остается в failed и больше не выбирается
+		retryAfter = s.cfg.IngestInterval << item.Attempts
+	}
+
+	if markErr := s.repo.MarkIngest(ctx, item.ID, status, err.Error(), retryAfter); markErr != nil {
+		return markErr
+	}
+	if status == models.IngestStatusFailed {
+		return err
+	}
+	logger.Logger.Infof("Track %q by %q marked as %s", item.TrackName, item.ArtistName, status)
+	return nil
+}
@@ -0,0 +1,212 @@
+package servicePostgres
+
+import (
+	"errors"
+	"musPlayer/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenPayload(artist, track string, listenedAt *int64) models.ListenPayload {
+	return models.ListenPayload{
+		ListenedAt:    listenedAt,
+		TrackMetadata: models.TrackMetadata{ArtistName: artist, TrackName: track},
+	}
+}
+
+func unix(ts int64) *int64 { return &ts }
+
+func TestParseSubmission(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	listenedAt := now.Add(-time.Hour).Unix()
+	ms := func(n int) *int { return &n }
+
+	manyImports := make([]models.ListenPayload, maxListensPerRequest+1)
+	for i := range manyImports {
+		manyImports[i] = listenPayload("Кино", "Кукушка", unix(listenedAt))
+	}
+
+	tests := []struct {
+		name string
+		sub  models.ListenSubmission
+		want []models.Listen
+		// wantErr — часть текста ошибки; пустая строка — ошибки нет
+		wantErr string
+	}{
+		{
+			name: "single",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{{
+				ListenedAt: unix(listenedAt),
+				TrackMetadata: models.TrackMetadata{
+					ArtistName:  "  Кино ",
+					TrackName:   "Группа\tкрови",
+					ReleaseName: "Группа крови",
+					AdditionalInfo: map[string]interface{}{
+						"duration":          245.5,
+						"recording_mbid":    "3e0b3a4c-8f1d-4a7e-9c5b-1f2d3e4a5b6c",
+						"submission_client": "Navidrome",
+					},
+				},
+			}}},
+			want: []models.Listen{{
+				ListenedAt:       time.Unix(listenedAt, 0).UTC(),
+				ArtistName:       "Кино",
+				TrackName:        "Группа крови",
+				ReleaseName:      "Группа крови",
+				DurationMs:       ms(245500),
+				RecordingMBID:    "3e0b3a4c-8f1d-4a7e-9c5b-1f2d3e4a5b6c",
+				SubmissionClient: "Navidrome",
+				AdditionalInfo: map[string]interface{}{
+					"duration":          245.5,
+					"recording_mbid":    "3e0b3a4c-8f1d-4a7e-9c5b-1f2d3e4a5b6c",
+					"submission_client": "Navidrome",
+				},
+			}},
+		},
+		{
+			name: "import",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeImport, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", unix(listenedAt)),
+				listenPayload("Аквариум", "Город золотой", unix(minListenTimestamp)),
+			}},
+			want: []models.Listen{
+				{ListenedAt: time.Unix(listenedAt, 0).UTC(), ArtistName: "Кино", TrackName: "Кукушка"},
+				{ListenedAt: time.Unix(minListenTimestamp, 0).UTC(), ArtistName: "Аквариум", TrackName: "Город золотой"},
+			},
+		},
+		{
+			name: "playing now",
+			sub: models.ListenSubmission{ListenType: models.ListenTypePlayingNow, Payload: []models.ListenPayload{{
+				TrackMetadata: models.TrackMetadata{
+					ArtistName:     "Кино",
+					TrackName:      "Кукушка",
+					AdditionalInfo: map[string]interface{}{"duration_ms": float64(401000), "duration": 1.0},
+				},
+			}}},
+			want: []models.Listen{{
+				ArtistName:     "Кино",
+				TrackName:      "Кукушка",
+				DurationMs:     ms(401000),
+				AdditionalInfo: map[string]interface{}{"duration_ms": float64(401000), "duration": 1.0},
+			}},
+		},
+		{
+			name:    "unknown listen type",
+			sub:     models.ListenSubmission{ListenType: "scrobble", Payload: []models.ListenPayload{listenPayload("Кино", "Кукушка", unix(listenedAt))}},
+			wantErr: "listen_type",
+		},
+		{name: "empty single", sub: models.ListenSubmission{ListenType: models.ListenTypeSingle}, wantErr: "exactly one"},
+		{name: "empty playing now", sub: models.ListenSubmission{ListenType: models.ListenTypePlayingNow}, wantErr: "exactly one"},
+		{name: "empty import", sub: models.ListenSubmission{ListenType: models.ListenTypeImport}, wantErr: "1 to 1000"},
+		{
+			name: "several singles",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", unix(listenedAt)),
+				listenPayload("Кино", "Звезда", unix(listenedAt)),
+			}},
+			wantErr: "exactly one",
+		},
+		{
+			name: "several playing now",
+			sub: models.ListenSubmission{ListenType: models.ListenTypePlayingNow, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", nil),
+				listenPayload("Кино", "Звезда", nil),
+			}},
+			wantErr: "exactly one",
+		},
+		{name: "import too large", sub: models.ListenSubmission{ListenType: models.ListenTypeImport, Payload: manyImports}, wantErr: "1 to 1000"},
+		{
+			name: "playing now with listened_at",
+			sub: models.ListenSubmission{ListenType: models.ListenTypePlayingNow, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", unix(listenedAt)),
+			}},
+			wantErr: "must not have listened_at",
+		},
+		{
+			name: "single without listened_at",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", nil),
+			}},
+			wantErr: "listened_at is required",
+		},
+		{
+			name: "listened_at before 2002",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", unix(minListenTimestamp-1)),
+			}},
+			wantErr: "out of range",
+		},
+		{
+			name: "listened_at in the future",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeImport, Payload: []models.ListenPayload{
+				listenPayload("Кино", "Кукушка", unix(listenedAt)),
+				listenPayload("Кино", "Звезда", unix(now.Add(25*time.Hour).Unix())),
+			}},
+			wantErr: "listen 1: listened_at",
+		},
+		{
+			name: "missing artist",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{
+				listenPayload(" \t", "Кукушка", unix(listenedAt)),
+			}},
+			wantErr: "artist_name and track_name are required",
+		},
+		{
+			name: "missing track",
+			sub: models.ListenSubmission{ListenType: models.ListenTypePlayingNow, Payload: []models.ListenPayload{
+				listenPayload("Кино", "", nil),
+			}},
+			wantErr: "artist_name and track_name are required",
+		},
+		{
+			name: "name too long",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{
+				listenPayload("Кино", strings.Repeat("я", maxListenNameLength+1), unix(listenedAt)),
+			}},
+			wantErr: "at most 512 characters",
+		},
+		{
+			name: "listen too large",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{{
+				ListenedAt: unix(listenedAt),
+				TrackMetadata: models.TrackMetadata{
+					ArtistName:     "Кино",
+					TrackName:      "Кукушка",
+					AdditionalInfo: map[string]interface{}{"comment": strings.Repeat("x", maxListenSize)},
+				},
+			}}},
+			wantErr: "larger than",
+		},
+		{
+			name: "invalid recording_mbid",
+			sub: models.ListenSubmission{ListenType: models.ListenTypeSingle, Payload: []models.ListenPayload{{
+				ListenedAt: unix(listenedAt),
+				TrackMetadata: models.TrackMetadata{
+					ArtistName:     "Кино",
+					TrackName:      "Кукушка",
+					AdditionalInfo: map[string]interface{}{"recording_mbid": strings.Repeat("a", 37)},
+				},
+			}}},
+			wantErr: "recording_mbid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSubmission(tt.sub, now)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalidListens) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseSubmission() error = %v, want ErrInvalidListens containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSubmission() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseSubmission() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/translit"
+	"strconv"
+	"strings"
+)
+
+// ErrLyricsVersionNotFound возвращается, когда у песни нет версии текста с запрошенным языком или видом
+var ErrLyricsVersionNotFound = errors.New("lyrics version not found")
+
+// ErrInvalidLyricsVersion возвращается при некорректных данных версии текста
+var ErrInvalidLyricsVersion = errors.New("invalid lyrics version")
+
+// Схема, которой транслитерация строится автоматически
+const defaultTranslitScheme = translit.SchemeGOST
+
+// LyricsQuery выбирает версию текста песни.
+// Version — идентификатор версии, ее вид (original, translation, ...) или схема транслитерации (gost, scientific).
+// Пустой запрос означает версию по умолчанию
+type LyricsQuery struct {
+	Lang    string
+	Version string
+}
+
+type lyricsService struct {
+	repo postgresrepo.LyricsRepository
+}
+
+func NewLyricsService(repo postgresrepo.LyricsRepository) LyricsService {
+	return &lyricsService{
+		repo: repo,
+	}
+}
+
+// Список версий текста песни без самих текстов
+func (s *lyricsService) ListLyricsVersions(ctx context.Context, songID int) ([]models.LyricsVersion, error) {
+	versions, err := s.repo.ListLyricsVersions(ctx, songID)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing lyrics versions: ", err)
+		return nil, err
+	}
+	for i := range versions {
+		versions[i].Text = ""
+	}
+	return versions, nil
+}
+
+// Добавление пользовательской версии текста. Оригинал задается только через текст песни
+func (s *lyricsService) AddLyricsVersion(ctx context.Context, v models.LyricsVersion) (int, error) {
+	switch v.Kind {
+	case models.LyricsTranslation, models.LyricsTransliteration, models.LyricsUser:
+	default:
+		return 0, fmt.Errorf("%w: kind must be translation, transliteration or user", ErrInvalidLyricsVersion)
+	}
+	if strings.TrimSpace(v.Lang) == "" || strings.TrimSpace(v.Text) == "" {
+		return 0, fmt.Errorf("%w: lang and text are required", ErrInvalidLyricsVersion)
+	}
+
+	v.Source = models.LyricsSourceUser
+	v.Variant = v.SubmittedBy
+
+	id, err := s.repo.SaveLyricsVersion(ctx, v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: id %d", ErrSongNotFound, v.SongID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error saving lyrics version: ", err)
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Infof("Lyrics version %d (%s, %s) saved for song %d", id, v.Lang, v.Kind, v.SongID)
+	return id, nil
+}
+
+func (s *lyricsService) SetDefaultLyricsVersion(ctx context.Context, songID, versionID int) error {
+	err := s.repo.SetDefaultLyricsVersion(ctx, songID, versionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLyricsVersionNotFound
+	}
+	return err
+}
+
+func (s *lyricsService) DeleteLyricsVersion(ctx context.Context, songID, versionID int) error {
+	err := s.repo.DeleteLyricsVersion(ctx, songID, versionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLyricsVersionNotFound
+	}
+	return err
+}
+
+// resolveLyrics выбирает версию текста по запросу. Транслитерация, которой еще нет, строится из оригинала и сохраняется
+func resolveLyrics(ctx context.Context, repo postgresrepo.LyricsRepository, songID int, q LyricsQuery) (models.LyricsVersion, error) {
+	if id, err := strconv.Atoi(q.Version); err == nil {
+		v, err := repo.GetLyricsVersion(ctx, songID, id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && q.Lang != "" && !strings.EqualFold(v.Lang, q.Lang)) {
+			return v, ErrLyricsVersionNotFound
+		}
+		return v, err
+	}
+
+	versions, err := repo.ListLyricsVersions(ctx, songID)
+	if err != nil {
+		return models.LyricsVersion{}, err
+	}
+	return pickLyrics(ctx, repo, versions, q)
+}
+
+// pickLyrics выбирает версию по запросу среди уже прочитанных версий песни.
+// Версии приходят в порядке предпочтения: по умолчанию, оригинал, переводы, транслитерации
+func pickLyrics(ctx context.Context, repo postgresrepo.LyricsRepository, versions []models.LyricsVersion, q LyricsQuery) (models.LyricsVersion, error) {
+	for _, v := range versions {
+		if q.Lang != "" && !strings.EqualFold(v.Lang, q.Lang) {
+			continue
+		}
+		if q.Version != "" && v.Kind != q.Version && v.Variant != q.Version {
+			continue
+		}
+		return v, nil
+	}
+
+	scheme, ok := translitScheme(q)
+	if !ok {
+		return models.LyricsVersion{}, ErrLyricsVersionNotFound
+	}
+	for _, v := range versions {
+		if v.Kind == models.LyricsOriginal {
+			if q.Lang != "" && !strings.EqualFold(q.Lang, v.Lang+"-Latn") {
+				break
+			}
+			return generateTransliteration(ctx, repo, v, scheme)
+		}
+	}
+
+	return models.LyricsVersion{}, ErrLyricsVersionNotFound
+}
+
+// translitScheme определяет, запрошена ли транслитерация, и какой схемой
+func translitScheme(q LyricsQuery) (translit.Scheme, bool) {
+	for _, scheme := range translit.Schemes {
+		if q.Version == string(scheme) {
+			return scheme, true
+		}
+	}
+	if q.Version == models.LyricsTransliteration || (q.Version == "" && strings.HasSuffix(strings.ToLower(q.Lang), "-latn")) {
+		return defaultTranslitScheme, true
+	}
+	return "", false
+}
+
+// generateTransliteration строит латинскую версию оригинала и сохраняет ее как сгенерированную
+func generateTransliteration(ctx context.Context, repo postgresrepo.LyricsRepository, original models.LyricsVersion, scheme translit.Scheme) (models.LyricsVersion, error) {
+	if !translit.HasCyrillic(original.Text) {
+		return models.LyricsVersion{}, ErrLyricsVersionNotFound
+	}
+
+	text, err := translit.Transliterate(original.Text, scheme)
+	if err != nil {
+		return models.LyricsVersion{}, err
+	}
+
+	v := models.LyricsVersion{
+		SongID:  original.SongID,
+		Lang:    original.Lang + "-Latn",
+		Kind:    models.LyricsTransliteration,
+		Variant: string(scheme),
+		Text:    text,
+		Source:  models.LyricsSourceTranslit,
+	}
+	if v.ID, err = repo.SaveLyricsVersion(ctx, v); err != nil {
+		return models.LyricsVersion{}, err
+	}
+
+	logger.FromContext(ctx).Infof("Generated %s transliteration for song %d", scheme, original.SongID)
+	return v, nil
+}
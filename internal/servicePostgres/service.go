@@ -2,24 +2,158 @@ package servicePostgres
 
 import (
 	"context"
+	"io"
 	"musPlayer/models"
+	"musPlayer/pkg/lyricstats"
 	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/tfidf"
+	"time"
 )
 
 type SongService interface {
 	AddSong(ctx context.Context, song postgresrepo.AddSongParams) (int, error)
-	GetSongText(ctx context.Context, songID, pageSize, pageNumber int) (string, error)
-	GetSongs(ctx context.Context, filter string, limit, offset int) ([]models.Song, error)
+	GetSongText(ctx context.Context, songID, pageSize, pageNumber int, q LyricsQuery) (string, error)
+	GetSongTextPages(ctx context.Context, songIDs []int, pageSize int, q LyricsQuery) (map[int][]string, error)
+	GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error)
+	GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error)
 	DeleteSong(ctx context.Context, songID int64) error
 	UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error
 }
 
+// LyricsService управляет версиями текста песни
+type LyricsService interface {
+	ListLyricsVersions(ctx context.Context, songID int) ([]models.LyricsVersion, error)
+	AddLyricsVersion(ctx context.Context, v models.LyricsVersion) (int, error)
+	SetDefaultLyricsVersion(ctx context.Context, songID, versionID int) error
+	DeleteLyricsVersion(ctx context.Context, songID, versionID int) error
+}
+
+// SyncedLyricsService хранит синхронизированные тексты (LRC) и ищет строку по времени воспроизведения
+type SyncedLyricsService interface {
+	ImportLRC(ctx context.Context, songID int, r io.Reader) (models.SyncedLyrics, error)
+	ExportLRC(ctx context.Context, songID int, enhanced bool) (string, error)
+	LinesAt(ctx context.Context, songID int, at time.Duration) (SyncedPosition, error)
+	ShiftSyncedLyrics(ctx context.Context, songID int, shift time.Duration) (int, error)
+}
+
+// AnalysisService считает статистику текстов песен и исполнителей
+type AnalysisService interface {
+	AnalyzeSong(ctx context.Context, songID int) (lyricstats.Analysis, error)
+	AnalyzeArtist(ctx context.Context, name string) (lyricstats.Analysis, error)
+}
+
+// SimilarityService ищет похожие по тексту песни по индексу TF-IDF
+type SimilarityService interface {
+	SimilarSongs(ctx context.Context, songID, limit int, filter tfidf.GroupFilter) ([]models.SimilarSong, error)
+	SimilarSongsBatch(ctx context.Context, songIDs []int, limit int, filter tfidf.GroupFilter) (map[int][]models.SimilarSong, error)
+	RunIndexer(ctx context.Context) error
+}
+
+// TagService управляет тегами песен и их массовым назначением
+type TagService interface {
+	ListTags(ctx context.Context, namespace string) ([]models.Tag, error)
+	CreateTag(ctx context.Context, name, description string) (models.Tag, error)
+	UpdateTag(ctx context.Context, tagID int, name, description string) (models.Tag, error)
+	DeleteTag(ctx context.Context, tagID int) error
+	AssignTags(ctx context.Context, tags []string, songs models.SongSelection) (int64, error)
+	UnassignTags(ctx context.Context, tags []string, songs models.SongSelection) (int64, error)
+	TagFacets(ctx context.Context, filter models.SongFilter, namespace string) ([]models.TagCount, error)
+}
+
+// LibraryService хранит личные библиотеки, избранное, оценки и заметки пользователей
+type LibraryService interface {
+	ListLibrary(ctx context.Context, userID string, filter models.SongFilter, favoritesOnly bool, limit, offset int) ([]models.LibrarySong, error)
+	AddToLibrary(ctx context.Context, userID string, songID int, note *string) error
+	RemoveFromLibrary(ctx context.Context, userID string, songID int) error
+	SetFavorite(ctx context.Context, userID string, songID int, favorite bool) error
+	RateSong(ctx context.Context, userID string, songID int, rating *int) (models.SongRating, error)
+	GetRating(ctx context.Context, userID string, songID int) (models.SongRating, error)
+}
+
+// ListenService принимает прослушивания в формате ListenBrainz и считает статистику по ним
+type ListenService interface {
+	SubmitListens(ctx context.Context, userID string, sub models.ListenSubmission) (ListenSubmitResult, error)
+	ListListens(ctx context.Context, userID string, minTS, maxTS *time.Time, count int) ([]models.Listen, error)
+	PlayingNow(ctx context.Context, userID string) (models.PlayingNow, error)
+	SongPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.SongPlayCount, error)
+	ArtistPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.ArtistPlayCount, error)
+	ListenStreaks(ctx context.Context, userID string, loc *time.Location) (models.ListenStreaks, error)
+	IngestQueue(ctx context.Context, status string, limit, offset int) ([]models.IngestQueueItem, error)
+	ResolveIngest(ctx context.Context, queueID, songID int) (int64, error)
+	RunIngester(ctx context.Context) error
+}
+
+// WebhookService управляет подписками на события каталога и доставляет события подписчикам
+type WebhookService interface {
+	ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int) (models.WebhookSubscription, error)
+	CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (models.WebhookDelivery, error)
+	RunDispatcher(ctx context.Context) error
+}
+
+// StreamService раздает живым подпискам события каталога всех экземпляров сервиса
+type StreamService interface {
+	SubscribeSongEvents(ctx context.Context, filter SongEventFilter, lastEventID *int64) (*SongSubscription, error)
+	CloseStreams()
+	RunStream(ctx context.Context) error
+}
+
+// ChangeFeedService отдает ленту изменений песен для инкрементальной синхронизации клиентов
+type ChangeFeedService interface {
+	ListChanges(ctx context.Context, since int64, limit int) (models.ChangeFeedPage, error)
+	RunTombstonePurger(ctx context.Context) error
+}
+
+// SyncService сверяет сохраненные песни с их исходными страницами
+type SyncService interface {
+	ResyncSong(ctx context.Context, songID int) (SyncResult, error)
+	RunRefresher(ctx context.Context) error
+}
+
+// Transactor выполняет функцию с репозиториями, привязанными к одной транзакции; реализуется *postgresrepo.Repository
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(r *postgresrepo.Repository) error) error
+	WithTxOptions(ctx context.Context, opts postgresrepo.TxOptions, fn func(r *postgresrepo.Repository) error) error
+}
+
 type Service struct {
 	SongService
+	LyricsService
+	SyncedLyricsService
+	AnalysisService
+	SimilarityService
+	TagService
+	LibraryService
+	ListenService
+	WebhookService
+	StreamService
+	ChangeFeedService
+	SyncService
 }
 
-func NewServicePostgres(repo *postgresrepo.Repository) *Service {
+func NewServicePostgres(repo *postgresrepo.Repository, source SongSource, cacheCfg models.CacheConfig, syncCfg models.SyncConfig,
+	similarityCfg models.SimilarityConfig, listensCfg models.ListensConfig, webhooksCfg models.WebhooksConfig, streamCfg models.StreamConfig, changesCfg models.ChangesConfig) *Service {
+	analysis := newAnalysisCache(cacheCfg.LRUSize, cacheCfg.AnalysisTTL)
+	similarity := newSimilarityService(repo.SimilarityRepository, similarityCfg)
+	listeners := songListeners{analysis, similarity}
+	songs := NewSongService(repo.SongRepository, repo.LyricsRepository, repo, listeners)
+
 	return &Service{
-		SongService: NewSongService(repo.SongRepository),
+		SongService:         songs,
+		LyricsService:       NewLyricsService(repo.LyricsRepository),
+		SyncedLyricsService: NewSyncedLyricsService(repo.SyncedLyricsRepository),
+		AnalysisService:     NewAnalysisService(repo.SongRepository, analysis),
+		SimilarityService:   similarity,
+		TagService:          NewTagService(repo.TagRepository),
+		LibraryService:      NewLibraryService(repo.LibraryRepository),
+		ListenService:       NewListenService(repo.ListenRepository, repo, listeners, source, listensCfg),
+		WebhookService:      NewWebhookService(repo.WebhookRepository, webhooksCfg),
+		StreamService:       NewStreamService(repo.SongEventRepository, streamCfg),
+		ChangeFeedService:   NewChangeFeedService(repo.ChangeFeedRepository, changesCfg),
+		SyncService:         NewSyncService(repo.SyncRepository, repo, source, syncCfg, listeners),
 	}
 }
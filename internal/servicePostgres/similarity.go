@@ -0,0 +1,262 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	"musPlayer/pkg/lyricstats"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/tfidf"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrIndexNotReady возвращается, пока индекс похожих песен загружается после старта
+var ErrIndexNotReady = errors.New("similarity index is not ready")
+
+// Сколько песен индексируется за один запрос к базе при догоняющей индексации
+const indexBatchSize = 500
+
+type similarityService struct {
+	repo   postgresrepo.SimilarityRepository
+	index  *tfidf.Index
+	cfg    models.SimilarityConfig
+	loaded atomic.Bool
+}
+
+func newSimilarityService(repo postgresrepo.SimilarityRepository, cfg models.SimilarityConfig) *similarityService {
+	return &similarityService{
+		repo:  repo,
+		index: tfidf.NewIndex(),
+		cfg:   cfg,
+	}
+}
+
+// SimilarSongs возвращает до limit песен библиотеки, самых похожих по тексту на песню songID
+func (s *similarityService) SimilarSongs(ctx context.Context, songID, limit int, filter tfidf.GroupFilter) ([]models.SimilarSong, error) {
+	if !s.loaded.Load() {
+		return nil, ErrIndexNotReady
+	}
+
+	// Песня могла быть добавлена другим экземпляром сервиса и еще не попасть в индекс
+	if !s.index.Contains(songID) {
+		if err := s.IndexSong(ctx, songID); err != nil {
+			return nil, err
+		}
+	}
+
+	matches, _ := s.index.Similar(songID, limit, filter)
+	similar, err := s.loadSimilar(ctx, map[int][]tfidf.Match{songID: matches})
+	if err != nil {
+		return nil, err
+	}
+	return similar[songID], nil
+}
+
+// SimilarSongsBatch ищет похожие песни сразу для нескольких песен, найденные песни читаются одним запросом.
+// Песни, которых нет в базе, в результат не попадают
+func (s *similarityService) SimilarSongsBatch(ctx context.Context, songIDs []int, limit int, filter tfidf.GroupFilter) (map[int][]models.SimilarSong, error) {
+	if !s.loaded.Load() {
+		return nil, ErrIndexNotReady
+	}
+
+	matches := make(map[int][]tfidf.Match, len(songIDs))
+	for _, id := range songIDs {
+		if !s.index.Contains(id) {
+			err := s.IndexSong(ctx, id)
+			if errors.Is(err, ErrSongNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		matches[id], _ = s.index.Similar(id, limit, filter)
+	}
+	return s.loadSimilar(ctx, matches)
+}
+
+// loadSimilar подставляет песни в совпадения, найденные по индексу
+func (s *similarityService) loadSimilar(ctx context.Context, matches map[int][]tfidf.Match) (map[int][]models.SimilarSong, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, list := range matches {
+		for _, m := range list {
+			if !seen[m.ID] {
+				seen[m.ID] = true
+				ids = append(ids, m.ID)
+			}
+		}
+	}
+
+	byID := make(map[int]models.Song, len(ids))
+	if len(ids) > 0 {
+		songs, err := s.repo.GetSongsByIDs(ctx, ids)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error loading similar songs: ", err)
+			return nil, err
+		}
+		for _, song := range songs {
+			byID[song.ID] = song
+		}
+	}
+
+	similar := make(map[int][]models.SimilarSong, len(matches))
+	for songID, list := range matches {
+		songs := make([]models.SimilarSong, 0, len(list))
+		for _, m := range list {
+			song, ok := byID[m.ID]
+			if !ok {
+				// Песню удалили, а индекс еще не узнал об этом
+				s.index.Remove(m.ID)
+				continue
+			}
+			songs = append(songs, models.SimilarSong{Song: song, Score: roundScore(m.Score)})
+		}
+		similar[songID] = songs
+	}
+	return similar, nil
+}
+
+// IndexSong строит вектор слов песни, сохраняет его и обновляет индекс
+func (s *similarityService) IndexSong(ctx context.Context, songID int) error {
+	song, err := s.repo.GetSongForIndex(ctx, songID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.index.Remove(songID)
+			return fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+		}
+		return err
+	}
+	return s.indexSong(ctx, song)
+}
+
+func (s *similarityService) indexSong(ctx context.Context, song models.Song) error {
+	v := models.TermVector{
+		SongID:    song.ID,
+		GroupName: song.GroupName,
+		Terms:     lyricstats.Terms(strings.ReplaceAll(song.Text, "\\n", "\n")),
+	}
+	if err := s.repo.SaveTermVector(ctx, v); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.index.Remove(song.ID)
+			return fmt.Errorf("%w: id %d", ErrSongNotFound, song.ID)
+		}
+		return err
+	}
+
+	s.index.Upsert(toDocument(v))
+	return nil
+}
+
+func (s *similarityService) songChanged(ctx context.Context, songID int) {
+	if err := s.IndexSong(ctx, songID); err != nil {
+		// Догоняющая индексация повторит попытку
+		logger.FromContext(ctx).Warnf("Failed to index song %d: %v", songID, err)
+	}
+}
+
+func (s *similarityService) songDeleted(_ context.Context, songID int) {
+	s.index.Remove(songID)
+}
+
+// RunIndexer загружает сохраненные векторы, затем периодически индексирует новые и измененные песни
+// (в том числе измененные другими экземплярами сервиса) и убирает из индекса удаленные
+func (s *similarityService) RunIndexer(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.loaded.Load() {
+			s.catchUp(ctx)
+		} else {
+			s.load(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// load заполняет индекс сохраненными векторами; при ошибке попытка повторится на следующем тике
+func (s *similarityService) load(ctx context.Context) {
+	startTime := time.Now()
+	vectors, err := s.repo.LoadTermVectors(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to load similarity index: %v", err)
+		}
+		return
+	}
+	for _, v := range vectors {
+		s.index.Upsert(toDocument(v))
+	}
+	s.loaded.Store(true)
+	logger.Logger.Infof("Similarity index loaded: %d songs in %s", len(vectors), time.Since(startTime))
+
+	s.catchUp(ctx)
+}
+
+func (s *similarityService) catchUp(ctx context.Context) {
+	indexed := 0
+	for ctx.Err() == nil {
+		songs, err := s.repo.ListSongsToIndex(ctx, indexBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Logger.Errorf("Failed to list songs to index: %v", err)
+			}
+			return
+		}
+
+		progress := false
+		for _, song := range songs {
+			if err := s.indexSong(ctx, song); err != nil {
+				logger.Logger.Warnf("Failed to index song %d: %v", song.ID, err)
+				continue
+			}
+			indexed++
+			progress = true
+		}
+		if len(songs) < indexBatchSize || !progress {
+			break
+		}
+	}
+
+	ids, err := s.repo.ListIndexedSongIDs(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to list indexed songs: %v", err)
+		}
+		return
+	}
+	exists := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		exists[id] = true
+	}
+	removed := 0
+	for _, id := range s.index.IDs() {
+		if !exists[id] {
+			s.index.Remove(id)
+			removed++
+		}
+	}
+
+	if indexed > 0 || removed > 0 {
+		logger.Logger.Infof("Similarity index updated: %d songs indexed, %d removed, %d total", indexed, removed, s.index.Len())
+	}
+}
+
+func toDocument(v models.TermVector) tfidf.Document {
+	return tfidf.Document{ID: v.SongID, Group: strings.ToLower(strings.TrimSpace(v.GroupName)), Terms: v.Terms}
+}
+
+func roundScore(score float64) float64 {
+	return float64(int(score*10000+0.5)) / 10000
+}
@@ -8,33 +8,82 @@ import (
 	"musPlayer/internal/logger"
 	"musPlayer/models"
 	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/translit"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// ErrInvalidPage — запрошенной страницы текста нет
+var ErrInvalidPage = errors.New("Invalid page number")
+
+// songNotFoundError сообщает id ненайденной песни и сопоставляется с ErrSongNotFound
+type songNotFoundError struct {
+	id int
+}
+
+func (e songNotFoundError) Error() string {
+	return fmt.Sprintf("song with id %d not found", e.id)
+}
+
+func (e songNotFoundError) Is(target error) bool {
+	return target == ErrSongNotFound
+}
+
 type songService struct {
-	repo postgresrepo.SongRepository
+	repo      postgresrepo.SongRepository
+	lyrics    postgresrepo.LyricsRepository
+	tx        Transactor
+	listeners songListeners
 }
 
-func NewSongService(repo postgresrepo.SongRepository) SongService {
+func NewSongService(repo postgresrepo.SongRepository, lyrics postgresrepo.LyricsRepository, tx Transactor, listeners songListeners) SongService {
 	return &songService{
-		repo: repo,
+		repo:      repo,
+		lyrics:    lyrics,
+		tx:        tx,
+		listeners: listeners,
 	}
 }
 func (s *songService) AddSong(ctx context.Context, song postgresrepo.AddSongParams) (int, error) {
-	logger.Logger.Debugf("Adding song: %+v", song)
+	logger.FromContext(ctx).Debugf("Adding song: %+v", song)
 	startTime := time.Now()
 
-	id, err := s.repo.AddSong(ctx, song)
+	// Песня, ее событие и транслитерация сохраняются в одной транзакции
+	var id int
+	err := s.tx.WithTx(ctx, func(r *postgresrepo.Repository) (err error) {
+		if id, err = r.AddSong(ctx, song); err != nil {
+			return err
+		}
+		transliterate(ctx, r, id, song.Text)
+		return nil
+	})
 	if err != nil {
-		logger.Logger.Error("Error adding song: ", err)
+		logger.FromContext(ctx).Error("Error adding song: ", err)
 		return 0, err
 	}
 
-	logger.Logger.Infof("Song added successfully with ID: %d, execution time: %s", id, time.Since(startTime))
+	s.listeners.changed(ctx, id)
+
+	logger.FromContext(ctx).Infof("Song added successfully with ID: %d, execution time: %s", id, time.Since(startTime))
 	return id, nil
 }
 
+// transliterate строит транслитерацию кириллического текста песни (оригинал сохраняется триггером).
+// Она сохраняется в точке сохранения: ошибка откатывает только ее, песня остается
+func transliterate(ctx context.Context, r *postgresrepo.Repository, songID int, text string) {
+	if !translit.HasCyrillic(text) {
+		return
+	}
+	err := r.WithTx(ctx, func(r *postgresrepo.Repository) error {
+		_, err := resolveLyrics(ctx, r.LyricsRepository, songID, LyricsQuery{Version: string(defaultTranslitScheme)})
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warnf("Failed to generate transliteration for song %d: %v", songID, err)
+	}
+}
+
 // Получение текста песни с обработкой
 func paginateText(text string, pageSize int) []string {
 	words := strings.Split(text, " ")
@@ -59,13 +108,21 @@ func paginateText(text string, pageSize int) []string {
 	return pages
 }
 
-func (s *songService) GetSongText(ctx context.Context, songID, pageSize, pageNumber int) (string, error) {
+func (s *songService) GetSongText(ctx context.Context, songID, pageSize, pageNumber int, q LyricsQuery) (string, error) {
 	startTime := time.Now()
-	logger.Logger.Debugf("Fetching song text for ID: %d, pageSize: %d, pageNumber: %d", songID, pageSize, pageNumber)
-	// Получаем текст песни
-	songText, err := s.repo.GetSongText(ctx, songID)
+	logger.FromContext(ctx).Debugf("Fetching song text for ID: %d, pageSize: %d, pageNumber: %d, lang: %q, version: %q", songID, pageSize, pageNumber, q.Lang, q.Version)
+	// Получаем нужную версию текста песни
+	var songText string
+	version, err := resolveLyrics(ctx, s.lyrics, songID, q)
+	switch {
+	case err == nil:
+		songText = version.Text
+	case errors.Is(err, ErrLyricsVersionNotFound) && q == LyricsQuery{}:
+		// У песни без текста нет версий — отдаем то, что хранится в songs
+		songText, err = s.repo.GetSongText(ctx, songID)
+	}
 	if err != nil {
-		logger.Logger.Error("Error retrieving song text: ", err)
+		logger.FromContext(ctx).Error("Error retrieving song text: ", err)
 		return "", err
 	}
 
@@ -75,61 +132,133 @@ func (s *songService) GetSongText(ctx context.Context, songID, pageSize, pageNum
 	pages := paginateText(songText, pageSize)
 
 	if pageNumber <= 0 || pageNumber > len(pages) {
-		return "", fmt.Errorf("Invalid page number")
+		return "", ErrInvalidPage
 	}
-	logger.Logger.Infof("GetSongText executed successfully, execution time: %s", time.Since(startTime))
+	logger.FromContext(ctx).Infof("GetSongText executed successfully, execution time: %s", time.Since(startTime))
 	return pages[pageNumber-1], nil
 }
 
+// Страницы текстов нескольких песен; версии всех песен читаются одним запросом.
+// Песни без подходящей версии текста в результат не попадают
+func (s *songService) GetSongTextPages(ctx context.Context, songIDs []int, pageSize int, q LyricsQuery) (map[int][]string, error) {
+	texts := make(map[int]string, len(songIDs))
+	if _, err := strconv.Atoi(q.Version); err == nil {
+		// Идентификатор версии относится к одной песне, пакетом такую версию не выбрать
+		for _, id := range songIDs {
+			v, err := resolveLyrics(ctx, s.lyrics, id, q)
+			if errors.Is(err, ErrLyricsVersionNotFound) {
+				continue
+			}
+			if err != nil {
+				logger.FromContext(ctx).Error("Error retrieving song text: ", err)
+				return nil, err
+			}
+			texts[id] = v.Text
+		}
+	} else {
+		versions, err := s.lyrics.ListLyricsVersionsBySongs(ctx, songIDs)
+		if err != nil {
+			logger.FromContext(ctx).Error("Error retrieving song texts: ", err)
+			return nil, err
+		}
+		for _, id := range songIDs {
+			v, err := pickLyrics(ctx, s.lyrics, versions[id], q)
+			if errors.Is(err, ErrLyricsVersionNotFound) {
+				continue
+			}
+			if err != nil {
+				logger.FromContext(ctx).Error("Error retrieving song text: ", err)
+				return nil, err
+			}
+			texts[id] = v.Text
+		}
+	}
+
+	pages := make(map[int][]string, len(texts))
+	for id, text := range texts {
+		pages[id] = paginateText(strings.ReplaceAll(text, "\\n", "\n"), pageSize)
+	}
+	return pages, nil
+}
+
 // Получение песен с фильтром и логированием
-func (s *songService) GetSongs(ctx context.Context, filter string, limit, offset int) ([]models.Song, error) {
+func (s *songService) GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
 	startTime := time.Now()
-	logger.Logger.Debugf("Retrieving songs with filter: %s, limit: %d, offset: %d", filter, limit, offset)
+	logger.FromContext(ctx).Debugf("Retrieving songs with filter: %+v, limit: %d, offset: %d", filter, limit, offset)
+
+	filter, err := normalizeSongFilter(filter)
+	if err != nil {
+		return nil, err
+	}
 
 	songs, err := s.repo.GetSongs(ctx, filter, limit, offset)
+	if errors.Is(err, postgresrepo.ErrInvalidSort) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSort, filter.Sort)
+	}
 	if err != nil {
-		logger.Logger.Error("Error retrieving songs: ", err)
+		logger.FromContext(ctx).Error("Error retrieving songs: ", err)
 		return nil, err
 	}
 
-	logger.Logger.Infof("GetSongs executed successfully, retrieved %d songs, execution time: %s", len(songs), time.Since(startTime))
+	logger.FromContext(ctx).Infof("GetSongs executed successfully, retrieved %d songs, execution time: %s", len(songs), time.Since(startTime))
+	return songs, nil
+}
+
+// Песни нескольких исполнителей (имя без учета регистра), по странице limit/offset на каждого
+func (s *songService) GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error) {
+	songs, err := s.repo.GetSongsByArtists(ctx, names, limit, offset)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error retrieving artist songs: ", err)
+		return nil, err
+	}
 	return songs, nil
 }
 
 // Удаление песни с логикой проверки
 func (s *songService) DeleteSong(ctx context.Context, songID int64) error {
 	startTime := time.Now()
-	logger.Logger.Debugf("Attempting to delete song with ID: %d", songID)
+	logger.FromContext(ctx).Debugf("Attempting to delete song with ID: %d", songID)
 
 	err := s.repo.DeleteSong(ctx, songID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Logger.Warnf("Song with ID %d not found", songID)
-			return fmt.Errorf("song with id %d not found", songID)
+			logger.FromContext(ctx).Warnf("Song with ID %d not found", songID)
+			return songNotFoundError{id: int(songID)}
 		}
-		logger.Logger.Error("Error deleting song: ", err)
+		logger.FromContext(ctx).Error("Error deleting song: ", err)
 		return err
 	}
 
-	logger.Logger.Infof("DeleteSong executed successfully, song ID: %d deleted, execution time: %s", songID, time.Since(startTime))
+	s.listeners.deleted(ctx, int(songID))
+
+	logger.FromContext(ctx).Infof("DeleteSong executed successfully, song ID: %d deleted, execution time: %s", songID, time.Since(startTime))
 	return nil
 }
 
 // Обновление песни с логикой проверки
 func (s *songService) UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error {
 	startTime := time.Now()
-	logger.Logger.Debugf("Updating song with ID: %d, data: %+v", updSong.ID, updSong)
+	logger.FromContext(ctx).Debugf("Updating song with ID: %d, data: %+v", updSong.ID, updSong)
 
-	err := s.repo.UpdateSong(ctx, updSong)
+	// Триггер удаляет устаревшие транслитерации, новая строится в той же транзакции
+	err := s.tx.WithTx(ctx, func(r *postgresrepo.Repository) error {
+		if err := r.UpdateSong(ctx, updSong); err != nil {
+			return err
+		}
+		transliterate(ctx, r, updSong.ID, updSong.Text)
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Logger.Warnf("Song with ID %d not found", updSong.ID)
-			return fmt.Errorf("song with id %d not found", updSong.ID)
+			logger.FromContext(ctx).Warnf("Song with ID %d not found", updSong.ID)
+			return songNotFoundError{id: updSong.ID}
 		}
-		logger.Logger.Error("Error updating song: ", err)
+		logger.FromContext(ctx).Error("Error updating song: ", err)
 		return err
 	}
 
-	logger.Logger.Infof("UpdateSong executed successfully, song ID: %d updated, execution time: %s", updSong.ID, time.Since(startTime))
+	s.listeners.changed(ctx, updSong.ID)
+
+	logger.FromContext(ctx).Infof("UpdateSong executed successfully, song ID: %d updated, execution time: %s", updSong.ID, time.Since(startTime))
 	return nil
 }
@@ -0,0 +1,45 @@
+package servicePostgres
+
+import (
+	"context"
+	"errors"
+	"musPlayer/models"
+	memoryrepo "musPlayer/pkg/memoryRepo"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"testing"
+)
+
+// countingTransactor считает транзакции, в которых сервис меняет данные
+type countingTransactor struct {
+	*postgresrepo.Repository
+	calls int
+}
+
+func (c *countingTransactor) WithTx(ctx context.Context, fn func(r *postgresrepo.Repository) error) error {
+	c.calls++
+	return c.Repository.WithTx(ctx, fn)
+}
+
+func TestSongWritesRunInTransaction(t *testing.T) {
+	repo := postgresrepo.NewCatalogRepository(memoryrepo.NewSongRepository())
+	tx := &countingTransactor{Repository: repo}
+	s := NewSongService(repo.SongRepository, repo.LyricsRepository, tx, nil)
+	ctx := context.Background()
+
+	id, err := s.AddSong(ctx, postgresrepo.AddSongParams{GroupName: "Кино", SongName: "Кукушка", Text: "Песен еще ненаписанных"})
+	if err != nil {
+		t.Fatalf("AddSong: %v", err)
+	}
+	err = s.UpdateSong(ctx, models.SongUpdateParams{ID: id, GroupName: "Кино", SongName: "Кукушка", Text: "Сколько?"})
+	if err != nil {
+		t.Fatalf("UpdateSong: %v", err)
+	}
+	if tx.calls != 2 {
+		t.Fatalf("%d transactions, want 2", tx.calls)
+	}
+
+	err = s.UpdateSong(ctx, models.SongUpdateParams{ID: id + 1, GroupName: "Кино", SongName: "Звезда"})
+	if !errors.Is(err, ErrSongNotFound) {
+		t.Fatalf("UpdateSong of a missing song = %v, want ErrSongNotFound", err)
+	}
+}
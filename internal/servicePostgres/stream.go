@@ -0,0 +1,298 @@
+package servicePostgres
+
+import (
+	"context"
+	"errors"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrStreamClosed возвращается живым подпискам, когда сервис останавливается
+	ErrStreamClosed = errors.New("song stream is closed")
+	// ErrSlowConsumer возвращается подписке, которая не успевала читать события; клиенту нужно
+	// переподключиться с Last-Event-ID
+	ErrSlowConsumer = errors.New("song stream subscriber is too slow")
+)
+
+// Пауза перед повторным подключением LISTEN после ошибки
+const streamRetryDelay = 5 * time.Second
+
+// SongEventFilter — фильтр живой подписки: событие проходит, если песня принадлежит одному из исполнителей
+// (без учета регистра) и отмечена одним из тегов. Пустой список не ограничивает
+type SongEventFilter struct {
+	Artists []string
+	Tags    []string
+}
+
+func (f SongEventFilter) match(e models.SongEvent) bool {
+	if len(f.Artists) > 0 {
+		found := false
+		for _, a := range f.Artists {
+			if strings.EqualFold(a, strings.TrimSpace(e.Song.GroupName)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.Tags) > 0 {
+		for _, t := range f.Tags {
+			for _, songTag := range e.Song.Tags {
+				if t == songTag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// SongSubscription — живая подписка на события каталога. Сначала отдает пропущенные события
+// после Last-Event-ID, затем новые
+type SongSubscription struct {
+	// Reset — клиент отстал больше чем на stream.resume_limit событий и должен перечитать список песен
+	Reset bool
+
+	stream    *songStream
+	filter    SongEventFilter
+	events    chan models.SongEvent
+	backlog   []models.SongEvent
+	replayed  map[int64]bool
+	replayMax int64
+	done      chan struct{}
+	err       error
+	closeOnce sync.Once
+}
+
+// Next возвращает следующее событие. Ошибка ctx означает, что событий не было до его отмены;
+// ErrStreamClosed и ErrSlowConsumer завершают подписку
+func (s *SongSubscription) Next(ctx context.Context) (models.SongEvent, error) {
+	if len(s.backlog) > 0 {
+		e := s.backlog[0]
+		s.backlog = s.backlog[1:]
+		return e, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return models.SongEvent{}, ctx.Err()
+		case <-s.done:
+			return models.SongEvent{}, s.err
+		case e := <-s.events:
+			// Событие могло прийти и живым уведомлением, и в пропущенных
+			if e.ID <= s.replayMax && s.replayed[e.ID] {
+				continue
+			}
+			return e, nil
+		}
+	}
+}
+
+// Close отписывается от событий
+func (s *SongSubscription) Close() {
+	s.stream.unsubscribe(s)
+	s.stop(nil)
+}
+
+func (s *SongSubscription) stop(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+	})
+}
+
+// songStream раздает события outbox живым подпискам этого экземпляра. О новых событиях всех экземпляров
+// он узнает через LISTEN/NOTIFY и читает их из outbox
+type songStream struct {
+	repo postgresrepo.SongEventRepository
+	cfg  models.StreamConfig
+
+	mu     sync.Mutex
+	subs   map[*SongSubscription]struct{}
+	lastID int64
+	closed bool
+}
+
+func NewStreamService(repo postgresrepo.SongEventRepository, cfg models.StreamConfig) StreamService {
+	return &songStream{
+		repo: repo,
+		cfg:  cfg,
+		subs: make(map[*SongSubscription]struct{}),
+	}
+}
+
+// SubscribeSongEvents создает живую подписку. Если задан lastEventID, сначала отдаются события после него
+func (h *songStream) SubscribeSongEvents(ctx context.Context, filter SongEventFilter, lastEventID *int64) (*SongSubscription, error) {
+	tags, err := parseTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	filter.Tags = fullNames(tags)
+	artists := filter.Artists[:0:0]
+	for _, a := range filter.Artists {
+		if a = strings.TrimSpace(a); a != "" {
+			artists = append(artists, a)
+		}
+	}
+	filter.Artists = artists
+
+	sub := &SongSubscription{
+		stream: h,
+		filter: filter,
+		events: make(chan models.SongEvent, h.cfg.BufferSize),
+		done:   make(chan struct{}),
+	}
+
+	// Подписка регистрируется до чтения пропущенных событий, чтобы не потерять пришедшие в это время
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrStreamClosed
+	}
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+
+	if lastEventID == nil {
+		return sub, nil
+	}
+
+	missed, err := h.repo.SongEventsAfter(ctx, *lastEventID, h.cfg.ResumeLimit+1)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	if len(missed) > h.cfg.ResumeLimit {
+		sub.Reset = true
+		return sub, nil
+	}
+
+	sub.replayed = make(map[int64]bool, len(missed))
+	for _, e := range missed {
+		sub.replayed[e.ID] = true
+		sub.replayMax = e.ID
+		if filter.match(e) {
+			sub.backlog = append(sub.backlog, e)
+		}
+	}
+
+	return sub, nil
+}
+
+func (h *songStream) unsubscribe(sub *SongSubscription) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+}
+
+// publish раздает события подпискам. Подписка с заполненным буфером закрывается, а не задерживает остальных
+func (h *songStream) publish(events []models.SongEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, e := range events {
+		if e.ID > h.lastID {
+			h.lastID = e.ID
+		}
+		for sub := range h.subs {
+			if !sub.filter.match(e) {
+				continue
+			}
+			select {
+			case sub.events <- e:
+			default:
+				delete(h.subs, sub)
+				sub.stop(ErrSlowConsumer)
+			}
+		}
+	}
+}
+
+// CloseStreams закрывает живые подписки и перестает принимать новые. Вызывается в начале остановки,
+// чтобы долгие запросы не задерживали остановку HTTP-сервера
+func (h *songStream) CloseStreams() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		sub.stop(ErrStreamClosed)
+	}
+}
+
+// RunStream слушает уведомления о новых событиях и раздает их подпискам, пока не отменен ctx
+func (h *songStream) RunStream(ctx context.Context) error {
+	for {
+		err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		logger.Logger.Errorf("Song event listener failed, retrying in %s: %v", streamRetryDelay, err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(streamRetryDelay):
+		}
+	}
+}
+
+func (h *songStream) listen(ctx context.Context) error {
+	lastID, err := h.repo.LastSongEventID(ctx)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	if lastID > h.lastID {
+		h.lastID = lastID
+	}
+	h.mu.Unlock()
+
+	return h.repo.ListenSongEvents(ctx, func(ids []int64) {
+		events, err := h.repo.SongEventsByIDs(ctx, ids)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Logger.Errorf("Failed to read song events %v: %v", ids, err)
+			}
+			return
+		}
+		h.publish(events)
+	}, func() {
+		h.catchUp(ctx)
+	})
+}
+
+// catchUp раздает события, уведомления о которых могли потеряться при обрыве соединения LISTEN
+func (h *songStream) catchUp(ctx context.Context) {
+	h.mu.Lock()
+	lastID := h.lastID
+	h.mu.Unlock()
+
+	for {
+		events, err := h.repo.SongEventsAfter(ctx, lastID, h.cfg.ResumeLimit)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Logger.Errorf("Failed to catch up song events after %d: %v", lastID, err)
+			}
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+		logger.Logger.Infof("Catching up %d song events after reconnect", len(events))
+		h.publish(events)
+		lastID = events[len(events)-1].ID
+		if len(events) < h.cfg.ResumeLimit {
+			return
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package servicePostgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"musPlayer/internal/cache"
+	"musPlayer/internal/logger"
+	servicegenius "musPlayer/internal/serviceGenius"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+	"time"
+)
+
+// ErrSongNotFound возвращается, когда песни с указанным идентификатором нет в базе
+var ErrSongNotFound = errors.New("song not found")
+
+// SongSource загружает песни из внешнего каталога: актуальную версию по ссылке на страницу или лучшее совпадение поиска
+type SongSource interface {
+	GetSongByURL(ctx context.Context, pageURL string) (*models.Song, error)
+	SearchSong(ctx context.Context, title, artist string) (*models.Song, error)
+}
+
+// SyncResult описывает результат синхронизации одной песни
+type SyncResult struct {
+	SongID        int      `json:"song_id"`
+	Status        string   `json:"status"`
+	ChangedFields []string `json:"changed_fields,omitempty"`
+	ManualFields  []string `json:"manual_fields,omitempty"` // поля, измененные в источнике, но оставленные из-за ручной правки
+	Error         string   `json:"error,omitempty"`
+}
+
+type syncService struct {
+	repo      postgresrepo.SyncRepository
+	tx        Transactor
+	source    SongSource
+	cfg       models.SyncConfig
+	listeners songListeners
+}
+
+func NewSyncService(repo postgresrepo.SyncRepository, tx Transactor, source SongSource, cfg models.SyncConfig, listeners songListeners) SyncService {
+	return &syncService{
+		repo:      repo,
+		tx:        tx,
+		source:    source,
+		cfg:       cfg,
+		listeners: listeners,
+	}
+}
+
+// ResyncSong заново загружает песню по ее ссылке и применяет изменения, кроме вручную отредактированных полей
+func (s *syncService) ResyncSong(ctx context.Context, songID int) (SyncResult, error) {
+	log := logger.FromContext(ctx)
+	startTime := time.Now()
+	result := SyncResult{SongID: songID}
+
+	state, err := s.repo.GetSongSyncState(ctx, songID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+		}
+		return result, err
+	}
+
+	if state.Link == "" {
+		result.Status = models.SyncStatusSkipped
+		return result, s.repo.MarkSongSync(ctx, songID, result.Status, "song has no source link")
+	}
+
+	// Кеш Genius обходим: нужна текущая версия страницы
+	remote, err := s.source.GetSongByURL(cache.WithRefresh(ctx), state.Link)
+	if err != nil {
+		if isTransient(err) {
+			return result, err
+		}
+		log.Warnf("Failed to resync song %d from %s: %v", songID, state.Link, err)
+		result.Status = models.SyncStatusFailed
+		result.Error = err.Error()
+		if markErr := s.repo.MarkSongSync(ctx, songID, result.Status, err.Error()); markErr != nil {
+			return result, markErr
+		}
+		return result, err
+	}
+
+	// Состояние перечитывается в транзакции REPEATABLE READ: если песню изменили вручную после загрузки
+	// страницы, запись изменений завершится ошибкой сериализации и повторится уже с новыми ручными полями
+	var upd models.SongSyncUpdate
+	err = s.tx.WithTxOptions(ctx, postgresrepo.TxOptions{Isolation: sql.LevelRepeatableRead}, func(r *postgresrepo.Repository) error {
+		state, err := r.GetSongSyncState(ctx, songID)
+		if err != nil {
+			return err
+		}
+		upd, result.ChangedFields, result.ManualFields = syncUpdate(state, remote)
+		return r.ApplySongSync(ctx, upd)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+	}
+	if err != nil {
+		log.Error("Error applying song sync: ", err)
+		return result, err
+	}
+	if len(upd.Changes) > 0 {
+		s.listeners.changed(ctx, songID)
+	}
+
+	result.Status = upd.Status
+	log.Infof("Song %d resynced with status %s, changed fields: %v, execution time: %s", songID, result.Status, result.ChangedFields, time.Since(startTime))
+	return result, nil
+}
+
+// syncUpdate сравнивает сохраненную песню с загруженной из источника. Возвращает изменения для записи,
+// примененные поля и поля, оставленные из-за ручной правки
+func syncUpdate(state models.SongSyncState, remote *models.Song) (upd models.SongSyncUpdate, changed, manual []string) {
+	upd = models.SongSyncUpdate{
+		ID:          state.ID,
+		ContentHash: contentHash(remote),
+		Status:      models.SyncStatusUnchanged,
+		Source:      "genius",
+		SourceURL:   state.Link,
+	}
+
+	// Если источник не менялся с прошлой синхронизации, расхождения — это ручные правки, их не трогаем
+	if upd.ContentHash == state.ContentHash {
+		return upd, nil, nil
+	}
+
+	manualFields := make(map[string]bool, len(state.ManualFields))
+	for _, f := range state.ManualFields {
+		manualFields[f] = true
+	}
+
+	upd.Changes = make(map[string]models.FieldChange)
+	for _, field := range postgresrepo.SyncFields {
+		current, next := syncFieldValue(&state.Song, field), syncFieldValue(remote, field)
+		if current == next || next == "" {
+			continue
+		}
+		if manualFields[field] {
+			manual = append(manual, field)
+			continue
+		}
+		upd.Changes[field] = models.FieldChange{Old: current, New: next}
+		changed = append(changed, field)
+	}
+
+	switch {
+	case len(upd.Changes) > 0:
+		upd.Status = models.SyncStatusUpdated
+	case len(manual) > 0:
+		upd.Status = models.SyncStatusManual
+	}
+	return upd, changed, manual
+}
+
+// RunRefresher периодически синхронизирует песни, которые дольше MaxAge не сверялись с источником,
+// начиная с самых старых и не чаще одного запроса в RequestDelay
+func (s *syncService) RunRefresher(ctx context.Context) error {
+	if !s.cfg.Enabled {
+		logger.Logger.Info("Song refresher is disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshBatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *syncService) refreshBatch(ctx context.Context) {
+	songs, err := s.repo.ListSongsForSync(ctx, time.Now().Add(-s.cfg.MaxAge), s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to list songs for resync: %v", err)
+		}
+		return
+	}
+	if len(songs) == 0 {
+		return
+	}
+	logger.Logger.Infof("Resyncing %d songs", len(songs))
+
+	limiter := time.NewTicker(s.cfg.RequestDelay)
+	defer limiter.Stop()
+
+	for i, song := range songs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-limiter.C:
+			}
+		}
+
+		if _, err := s.ResyncSong(ctx, song.ID); err != nil {
+			if isTransient(err) {
+				// Genius недоступен или ограничивает запросы — продолжим в следующем цикле
+				logger.Logger.Warnf("Stopping resync batch: %v", err)
+				return
+			}
+			logger.Logger.Warnf("Resync of song %d failed: %v", song.ID, err)
+		}
+	}
+}
+
+// isTransient сообщает, что ошибка временная и песню не нужно помечать как failed
+func isTransient(err error) bool {
+	return errors.Is(err, servicegenius.ErrCircuitOpen) ||
+		errors.Is(err, servicegenius.ErrRateLimited) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// contentHash вычисляет хеш разобранного текста и метаданных песни
+func contentHash(song *models.Song) string {
+	h := sha256.New()
+	for _, field := range postgresrepo.SyncFields {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+		h.Write([]byte(strings.TrimSpace(syncFieldValue(song, field))))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func syncFieldValue(song *models.Song, field string) string {
+	switch field {
+	case "group_name":
+		return song.GroupName
+	case "song_name":
+		return song.SongName
+	case "release_date":
+		return song.ReleaseDate
+	case "album":
+		return song.Album
+	case "text":
+		return song.Text
+	case "link":
+		return song.Link
+	}
+	return ""
+}
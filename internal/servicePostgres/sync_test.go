@@ -0,0 +1,423 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	servicegenius "musPlayer/internal/serviceGenius"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// syncRepoStub — репозиторий синхронизации в памяти. states — очередь состояний песни:
+// каждое чтение берет следующее, последнее повторяется
+type syncRepoStub struct {
+	states  map[int][]models.SongSyncState
+	listed  []models.SongSyncState
+	applied []models.SongSyncUpdate
+	marks   []string // "<id> <status>: <error>"
+}
+
+func (r *syncRepoStub) GetSongSyncState(_ context.Context, songID int) (models.SongSyncState, error) {
+	states := r.states[songID]
+	if len(states) == 0 {
+		return models.SongSyncState{}, sql.ErrNoRows
+	}
+	if len(states) > 1 {
+		r.states[songID] = states[1:]
+	}
+	return states[0], nil
+}
+
+func (r *syncRepoStub) ListSongsForSync(context.Context, time.Time, int) ([]models.SongSyncState, error) {
+	return r.listed, nil
+}
+
+func (r *syncRepoStub) ApplySongSync(_ context.Context, upd models.SongSyncUpdate) error {
+	r.applied = append(r.applied, upd)
+	return nil
+}
+
+func (r *syncRepoStub) MarkSongSync(_ context.Context, songID int, status, syncErr string) error {
+	r.marks = append(r.marks, fmt.Sprintf("%d %s: %s", songID, status, syncErr))
+	return nil
+}
+
+// syncTransactor выполняет функцию над репозиторием-заглушкой и запоминает уровни изоляции
+type syncTransactor struct {
+	repo       *postgresrepo.Repository
+	isolations []sql.IsolationLevel
+}
+
+func (t *syncTransactor) WithTx(ctx context.Context, fn func(r *postgresrepo.Repository) error) error {
+	return t.WithTxOptions(ctx, postgresrepo.TxOptions{}, fn)
+}
+
+func (t *syncTransactor) WithTxOptions(_ context.Context, opts postgresrepo.TxOptions, fn func(r *postgresrepo.Repository) error) error {
+	t.isolations = append(t.isolations, opts.Isolation)
+	return fn(t.repo)
+}
+
+// sourceStub отдает песни и ошибки по ссылке и запоминает запрошенные ссылки
+type sourceStub struct {
+	songs   map[string]*models.Song
+	errs    map[string]error
+	fetched []string
+}
+
+func (s *sourceStub) GetSongByURL(_ context.Context, pageURL string) (*models.Song, error) {
+	s.fetched = append(s.fetched, pageURL)
+	if err := s.errs[pageURL]; err != nil {
+		return nil, err
+	}
+	song, ok := s.songs[pageURL]
+	if !ok {
+		return nil, servicegenius.ErrNotFound
+	}
+	copied := *song
+	return &copied, nil
+}
+
+func (s *sourceStub) SearchSong(context.Context, string, string) (*models.Song, error) {
+	return nil, errors.New("not expected")
+}
+
+// changeRecorder — слушатель, который запоминает измененные песни
+type changeRecorder struct{ changed []int }
+
+func (c *changeRecorder) songChanged(_ context.Context, songID int) {
+	c.changed = append(c.changed, songID)
+}
+func (c *changeRecorder) songDeleted(context.Context, int) {}
+
+type syncFixture struct {
+	repo     *syncRepoStub
+	tx       *syncTransactor
+	source   *sourceStub
+	listener *changeRecorder
+	service  *syncService
+}
+
+func newSyncFixture(cfg models.SyncConfig) *syncFixture {
+	f := &syncFixture{
+		repo:     &syncRepoStub{states: make(map[int][]models.SongSyncState)},
+		source:   &sourceStub{songs: make(map[string]*models.Song), errs: make(map[string]error)},
+		listener: &changeRecorder{},
+	}
+	f.tx = &syncTransactor{repo: &postgresrepo.Repository{SyncRepository: f.repo}}
+	f.service = NewSyncService(f.repo, f.tx, f.source, cfg, songListeners{f.listener}).(*syncService)
+	return f
+}
+
+const syncTestLink = "https://genius.com/Kino-kukushka-lyrics"
+
+func syncTestSong() models.Song {
+	return models.Song{
+		ID:          1,
+		GroupName:   "Кино",
+		SongName:    "Кукушка",
+		ReleaseDate: "1990",
+		Album:       "Черный альбом",
+		Text:        "Песен еще ненаписанных",
+		Link:        syncTestLink,
+	}
+}
+
+// syncedState — состояние песни, синхронизированной с song
+func syncedState(song models.Song, manual ...string) models.SongSyncState {
+	return models.SongSyncState{Song: song, ContentHash: contentHash(&song), ManualFields: manual}
+}
+
+func TestSyncUpdate(t *testing.T) {
+	local := syncTestSong()
+	remote := syncTestSong()
+	remote.Text = "Сколько их? Ответь, кукушка"
+	remote.Album = "Кино"
+
+	tests := []struct {
+		name        string
+		state       models.SongSyncState
+		remote      models.Song
+		wantStatus  string
+		wantChanged []string
+		wantManual  []string
+	}{
+		{
+			name:       "page did not change",
+			state:      syncedState(local),
+			remote:     local,
+			wantStatus: models.SyncStatusUnchanged,
+		},
+		{
+			// Хеш страницы не изменился, поэтому расхождение с ней — ручная правка, которую нельзя откатывать
+			name: "unchanged page keeps local edits",
+			state: func() models.SongSyncState {
+				s := syncedState(local)
+				s.Text = "исправленный вручную текст"
+				return s
+			}(),
+			remote:     local,
+			wantStatus: models.SyncStatusUnchanged,
+		},
+		{
+			name:        "changed fields are applied",
+			state:       syncedState(local),
+			remote:      remote,
+			wantStatus:  models.SyncStatusUpdated,
+			wantChanged: []string{"album", "text"},
+		},
+		{
+			name:        "manual field is protected",
+			state:       syncedState(local, "text"),
+			remote:      remote,
+			wantStatus:  models.SyncStatusUpdated,
+			wantChanged: []string{"album"},
+			wantManual:  []string{"text"},
+		},
+		{
+			name:       "only manual fields changed",
+			state:      syncedState(local, "album", "text"),
+			remote:     remote,
+			wantStatus: models.SyncStatusManual,
+			wantManual: []string{"album", "text"},
+		},
+		{
+			name:  "empty remote field does not clear local value",
+			state: syncedState(local),
+			remote: func() models.Song {
+				s := remote
+				s.Album = ""
+				return s
+			}(),
+			wantStatus:  models.SyncStatusUpdated,
+			wantChanged: []string{"text"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := tt.remote
+			upd, changed, manual := syncUpdate(tt.state, &remote)
+			if upd.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", upd.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(changed, tt.wantChanged) {
+				t.Errorf("changed = %q, want %q", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(manual, tt.wantManual) {
+				t.Errorf("manual = %q, want %q", manual, tt.wantManual)
+			}
+			if len(upd.Changes) != len(tt.wantChanged) {
+				t.Errorf("changes = %v, want fields %q", upd.Changes, tt.wantChanged)
+			}
+			for _, field := range tt.wantChanged {
+				if got, want := upd.Changes[field].New, syncFieldValue(&remote, field); got != want {
+					t.Errorf("change of %s = %q, want %q", field, got, want)
+				}
+			}
+			if upd.ContentHash != contentHash(&remote) || upd.SourceURL != tt.state.Link {
+				t.Errorf("update hash %q and source %q do not describe the fetched page", upd.ContentHash, upd.SourceURL)
+			}
+		})
+	}
+}
+
+func TestResyncSong(t *testing.T) {
+	song := syncTestSong()
+	updated := syncTestSong()
+	updated.Text = "Сколько их? Ответь, кукушка"
+
+	tests := []struct {
+		name       string
+		states     []models.SongSyncState
+		remote     *models.Song
+		fetchErr   error
+		wantErr    error
+		wantStatus string
+		wantFields []string
+		wantManual []string
+		wantApply  bool
+		wantMarks  []string
+		wantNotify bool
+	}{
+		{
+			name:    "missing song",
+			wantErr: ErrSongNotFound,
+		},
+		{
+			name: "song without link is skipped",
+			states: func() []models.SongSyncState {
+				s := syncedState(song)
+				s.Link = ""
+				return []models.SongSyncState{s}
+			}(),
+			wantStatus: models.SyncStatusSkipped,
+			wantMarks:  []string{"1 skipped: song has no source link"},
+		},
+		{
+			name:       "unchanged page",
+			states:     []models.SongSyncState{syncedState(song)},
+			remote:     &song,
+			wantStatus: models.SyncStatusUnchanged,
+			wantApply:  true,
+		},
+		{
+			name:       "updated page",
+			states:     []models.SongSyncState{syncedState(song)},
+			remote:     &updated,
+			wantStatus: models.SyncStatusUpdated,
+			wantFields: []string{"text"},
+			wantApply:  true,
+			wantNotify: true,
+		},
+		{
+			// Ручная правка между загрузкой страницы и записью: поля сверяются с состоянием из транзакции
+			name:       "manual edit during fetch",
+			states:     []models.SongSyncState{syncedState(song), syncedState(song, "text")},
+			remote:     &updated,
+			wantStatus: models.SyncStatusManual,
+			wantManual: []string{"text"},
+			wantApply:  true,
+		},
+		{
+			name:       "permanent source error marks song failed",
+			states:     []models.SongSyncState{syncedState(song)},
+			fetchErr:   servicegenius.ErrNotFound,
+			wantErr:    servicegenius.ErrNotFound,
+			wantStatus: models.SyncStatusFailed,
+			wantMarks:  []string{"1 failed: " + servicegenius.ErrNotFound.Error()},
+		},
+		{
+			name:     "rate limit leaves song untouched",
+			states:   []models.SongSyncState{syncedState(song)},
+			fetchErr: fmt.Errorf("search: %w", servicegenius.ErrRateLimited),
+			wantErr:  servicegenius.ErrRateLimited,
+		},
+		{
+			name:     "open circuit leaves song untouched",
+			states:   []models.SongSyncState{syncedState(song)},
+			fetchErr: servicegenius.ErrCircuitOpen,
+			wantErr:  servicegenius.ErrCircuitOpen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSyncFixture(models.SyncConfig{})
+			if tt.states != nil {
+				f.repo.states[1] = tt.states
+			}
+			if tt.remote != nil {
+				f.source.songs[syncTestLink] = tt.remote
+			}
+			if tt.fetchErr != nil {
+				f.source.errs[syncTestLink] = tt.fetchErr
+			}
+
+			result, err := f.service.ResyncSong(context.Background(), 1)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("ResyncSong() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", result.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(result.ChangedFields, tt.wantFields) || !reflect.DeepEqual(result.ManualFields, tt.wantManual) {
+				t.Errorf("changed %q, manual %q, want %q and %q", result.ChangedFields, result.ManualFields, tt.wantFields, tt.wantManual)
+			}
+			if !reflect.DeepEqual(f.repo.marks, tt.wantMarks) {
+				t.Errorf("marks = %q, want %q", f.repo.marks, tt.wantMarks)
+			}
+
+			if !tt.wantApply {
+				if len(f.repo.applied) != 0 {
+					t.Errorf("applied %v, want nothing", f.repo.applied)
+				}
+			} else {
+				if len(f.repo.applied) != 1 || f.repo.applied[0].Status != tt.wantStatus {
+					t.Fatalf("applied %v, want one update with status %q", f.repo.applied, tt.wantStatus)
+				}
+				if want := []sql.IsolationLevel{sql.LevelRepeatableRead}; !reflect.DeepEqual(f.tx.isolations, want) {
+					t.Errorf("transactions %v, want one at REPEATABLE READ", f.tx.isolations)
+				}
+			}
+			if notified := len(f.listener.changed) > 0; notified != tt.wantNotify {
+				t.Errorf("listeners notified about %v, want notification %v", f.listener.changed, tt.wantNotify)
+			}
+		})
+	}
+}
+
+func TestRefreshBatch(t *testing.T) {
+	songs := make([]models.Song, 4)
+	for i := range songs {
+		songs[i] = syncTestSong()
+		songs[i].ID = i + 1
+		songs[i].Link = fmt.Sprintf("https://genius.com/song-%d", i+1)
+	}
+
+	tests := []struct {
+		name        string
+		errs        map[int]error // ошибка загрузки по номеру песни
+		wantFetched int
+		wantMarks   []string
+	}{
+		{name: "all songs", wantFetched: 4},
+		{
+			name:        "permanent error continues with the next song",
+			errs:        map[int]error{2: servicegenius.ErrNotFound},
+			wantFetched: 4,
+			wantMarks:   []string{"2 failed: " + servicegenius.ErrNotFound.Error()},
+		},
+		{
+			name:        "rate limit stops the batch",
+			errs:        map[int]error{2: servicegenius.ErrRateLimited},
+			wantFetched: 2,
+		},
+		{
+			name:        "open circuit stops the batch",
+			errs:        map[int]error{1: servicegenius.ErrCircuitOpen},
+			wantFetched: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSyncFixture(models.SyncConfig{MaxAge: time.Hour, BatchSize: 10, RequestDelay: time.Millisecond})
+			for _, song := range songs {
+				state := syncedState(song)
+				f.repo.states[song.ID] = []models.SongSyncState{state}
+				f.repo.listed = append(f.repo.listed, state)
+				f.source.songs[song.Link] = &songs[song.ID-1]
+				if err := tt.errs[song.ID]; err != nil {
+					f.source.errs[song.Link] = err
+				}
+			}
+
+			f.service.refreshBatch(context.Background())
+			if len(f.source.fetched) != tt.wantFetched {
+				t.Errorf("fetched %q, want %d songs", f.source.fetched, tt.wantFetched)
+			}
+			if !reflect.DeepEqual(f.repo.marks, tt.wantMarks) {
+				t.Errorf("marks = %q, want %q", f.repo.marks, tt.wantMarks)
+			}
+		})
+	}
+
+	t.Run("cancelled context stops between songs", func(t *testing.T) {
+		f := newSyncFixture(models.SyncConfig{MaxAge: time.Hour, BatchSize: 10, RequestDelay: time.Hour})
+		for _, song := range songs {
+			state := syncedState(song)
+			f.repo.states[song.ID] = []models.SongSyncState{state}
+			f.repo.listed = append(f.repo.listed, state)
+			f.source.songs[song.Link] = &songs[song.ID-1]
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		f.service.refreshBatch(ctx)
+		if len(f.source.fetched) != 1 {
+			t.Fatalf("fetched %q, want only the first song before the delay", f.source.fetched)
+		}
+	})
+}
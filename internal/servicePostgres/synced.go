@@ -0,0 +1,150 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	"musPlayer/pkg/lrc"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"time"
+)
+
+var (
+	// ErrSyncedLyricsNotFound возвращается, когда для песни не загружен синхронизированный текст
+	ErrSyncedLyricsNotFound = errors.New("synced lyrics not found")
+	// ErrInvalidLRC возвращается, когда файл LRC не удалось разобрать или в нем нет строк с временем
+	ErrInvalidLRC = errors.New("invalid lrc")
+)
+
+// SyncedPosition — строки, которые звучат в момент воспроизведения и следуют за ним.
+// Время строк приведено к времени трека с учетом смещения
+type SyncedPosition struct {
+	TimeMs  int                `json:"time_ms"`
+	Current *models.SyncedLine `json:"current"`
+	Next    *models.SyncedLine `json:"next"`
+}
+
+type syncedLyricsService struct {
+	repo postgresrepo.SyncedLyricsRepository
+}
+
+func NewSyncedLyricsService(repo postgresrepo.SyncedLyricsRepository) SyncedLyricsService {
+	return &syncedLyricsService{
+		repo: repo,
+	}
+}
+
+// ImportLRC разбирает LRC или enhanced LRC и заменяет синхронизированный текст песни
+func (s *syncedLyricsService) ImportLRC(ctx context.Context, songID int, r io.Reader) (models.SyncedLyrics, error) {
+	parsed, err := lrc.Parse(r)
+	if err != nil {
+		return models.SyncedLyrics{}, fmt.Errorf("%w: %v", ErrInvalidLRC, err)
+	}
+	if len(parsed.Lines) == 0 {
+		return models.SyncedLyrics{}, fmt.Errorf("%w: no timed lines", ErrInvalidLRC)
+	}
+
+	synced := models.SyncedLyrics{
+		SongID:   songID,
+		OffsetMs: int(parsed.Offset.Milliseconds()),
+		Tags:     parsed.Tags,
+	}
+	for i, line := range parsed.Lines {
+		sl := models.SyncedLine{
+			LineNo: i + 1,
+			TimeMs: int(line.Time.Milliseconds()),
+			Text:   line.Text,
+		}
+		for _, w := range line.Words {
+			sl.Words = append(sl.Words, models.SyncedWord{TimeMs: int(w.Time.Milliseconds()), Text: w.Text})
+		}
+		synced.Lines = append(synced.Lines, sl)
+	}
+
+	if err := s.repo.SaveSyncedLyrics(ctx, synced); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SyncedLyrics{}, fmt.Errorf("%w: id %d", ErrSongNotFound, songID)
+		}
+		logger.FromContext(ctx).Error("Error saving synced lyrics: ", err)
+		return models.SyncedLyrics{}, err
+	}
+
+	logger.FromContext(ctx).Infof("Imported %d synced lines for song %d", len(synced.Lines), songID)
+	return synced, nil
+}
+
+// ExportLRC записывает синхронизированный текст песни в LRC; enhanced сохраняет время слов
+func (s *syncedLyricsService) ExportLRC(ctx context.Context, songID int, enhanced bool) (string, error) {
+	synced, err := s.repo.GetSyncedLyrics(ctx, songID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrSyncedLyricsNotFound
+		}
+		return "", err
+	}
+
+	l := &lrc.Lyrics{
+		Tags:   synced.Tags,
+		Offset: time.Duration(synced.OffsetMs) * time.Millisecond,
+	}
+	for _, line := range synced.Lines {
+		ll := lrc.Line{Time: time.Duration(line.TimeMs) * time.Millisecond, Text: line.Text}
+		for _, w := range line.Words {
+			ll.Words = append(ll.Words, lrc.Word{Time: time.Duration(w.TimeMs) * time.Millisecond, Text: w.Text})
+		}
+		l.Lines = append(l.Lines, ll)
+	}
+
+	return lrc.Format(l, enhanced), nil
+}
+
+// LinesAt возвращает текущую и следующую строки для момента воспроизведения at
+func (s *syncedLyricsService) LinesAt(ctx context.Context, songID int, at time.Duration) (SyncedPosition, error) {
+	pos := SyncedPosition{TimeMs: int(at.Milliseconds())}
+
+	current, next, offsetMs, err := s.repo.GetSyncedLinesAt(ctx, songID, pos.TimeMs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pos, ErrSyncedLyricsNotFound
+		}
+		return pos, err
+	}
+
+	pos.Current = applyOffset(current, offsetMs)
+	pos.Next = applyOffset(next, offsetMs)
+	return pos, nil
+}
+
+// ShiftSyncedLyrics сдвигает все строки песни: положительный shift показывает их позже. Возвращает новое смещение в мс
+func (s *syncedLyricsService) ShiftSyncedLyrics(ctx context.Context, songID int, shift time.Duration) (int, error) {
+	offsetMs, err := s.repo.ShiftSyncedLyrics(ctx, songID, int(shift.Milliseconds()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrSyncedLyricsNotFound
+		}
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Infof("Synced lyrics of song %d shifted by %s, offset is now %dms", songID, shift, offsetMs)
+	return offsetMs, nil
+}
+
+// applyOffset переводит время строки и ее слов из времени файла во время трека
+func applyOffset(line *models.SyncedLine, offsetMs int) *models.SyncedLine {
+	if line == nil || offsetMs == 0 {
+		return line
+	}
+	line.TimeMs -= offsetMs
+	words := make([]models.SyncedWord, len(line.Words))
+	for i, w := range line.Words {
+		words[i] = models.SyncedWord{TimeMs: w.TimeMs - offsetMs, Text: w.Text}
+	}
+	if len(words) > 0 {
+		line.Words = words
+	}
+	return line
+}
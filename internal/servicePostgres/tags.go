@@ -0,0 +1,246 @@
+package servicePostgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/internal/logger"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrTagNotFound возвращается, когда тега с таким идентификатором нет
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists возвращается при создании или переименовании тега в уже занятое имя
+	ErrTagExists = errors.New("tag already exists")
+	// ErrInvalidTag возвращается при некорректном имени тега или параметрах массовой операции
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+// Пространство имен — латиница, цифры, «-» и «_»: genre, mood, era, team-x
+var tagNamespacePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+const maxTagNameLength = 128
+
+// ParseTag разбирает тег вида namespace:name или name. Имя приводится к нижнему регистру, лишние пробелы убираются
+func ParseTag(s string) (models.Tag, error) {
+	var t models.Tag
+	namespace, name, ok := strings.Cut(s, ":")
+	if ok {
+		t.Namespace = strings.ToLower(strings.TrimSpace(namespace))
+		if !tagNamespacePattern.MatchString(t.Namespace) {
+			return models.Tag{}, fmt.Errorf("%w: namespace of %q must contain only latin letters, digits, '-' and '_'", ErrInvalidTag, s)
+		}
+	} else {
+		name = namespace
+	}
+
+	t.Name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if t.Name == "" {
+		return models.Tag{}, fmt.Errorf("%w: empty name in %q", ErrInvalidTag, s)
+	}
+	if utf8.RuneCountInString(t.Name) > maxTagNameLength || strings.ContainsAny(t.Name, ":,") {
+		return models.Tag{}, fmt.Errorf("%w: name of %q must be at most %d characters without ':' and ','", ErrInvalidTag, s, maxTagNameLength)
+	}
+
+	t.FullName = t.Name
+	if t.Namespace != "" {
+		t.FullName = t.Namespace + ":" + t.Name
+	}
+	return t, nil
+}
+
+// parseTags разбирает список тегов, убирая повторы
+func parseTags(names []string) ([]models.Tag, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		t, err := ParseTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[t.FullName] {
+			continue
+		}
+		seen[t.FullName] = true
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+func fullNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.FullName
+	}
+	return names
+}
+
+// normalizeSongFilter приводит теги фильтра к полным именам, под которыми они хранятся
+func normalizeSongFilter(f models.SongFilter) (models.SongFilter, error) {
+	for _, list := range []*[]string{&f.TagsAll, &f.TagsAny, &f.TagsNone} {
+		tags, err := parseTags(*list)
+		if err != nil {
+			return models.SongFilter{}, err
+		}
+		*list = fullNames(tags)
+	}
+	return f, nil
+}
+
+type tagService struct {
+	repo postgresrepo.TagRepository
+}
+
+func NewTagService(repo postgresrepo.TagRepository) TagService {
+	return &tagService{
+		repo: repo,
+	}
+}
+
+// Список тегов с числом песен
+func (s *tagService) ListTags(ctx context.Context, namespace string) ([]models.Tag, error) {
+	tags, err := s.repo.ListTags(ctx, strings.ToLower(strings.TrimSpace(namespace)))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error listing tags: ", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Создание тега по имени вида namespace:name
+func (s *tagService) CreateTag(ctx context.Context, name, description string) (models.Tag, error) {
+	t, err := ParseTag(name)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	t.Description = strings.TrimSpace(description)
+
+	created, err := s.repo.CreateTag(ctx, t)
+	if errors.Is(err, postgresrepo.ErrTagExists) {
+		return models.Tag{}, fmt.Errorf("%w: %s", ErrTagExists, t.FullName)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error creating tag: ", err)
+		return models.Tag{}, err
+	}
+
+	logger.FromContext(ctx).Infof("Tag %q created with ID: %d", created.FullName, created.ID)
+	return created, nil
+}
+
+// Переименование тега и изменение описания
+func (s *tagService) UpdateTag(ctx context.Context, tagID int, name, description string) (models.Tag, error) {
+	t, err := ParseTag(name)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	t.ID = tagID
+	t.Description = strings.TrimSpace(description)
+
+	updated, err := s.repo.UpdateTag(ctx, t)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return models.Tag{}, fmt.Errorf("%w: id %d", ErrTagNotFound, tagID)
+	case errors.Is(err, postgresrepo.ErrTagExists):
+		return models.Tag{}, fmt.Errorf("%w: %s", ErrTagExists, t.FullName)
+	case err != nil:
+		logger.FromContext(ctx).Error("Error updating tag: ", err)
+		return models.Tag{}, err
+	}
+
+	logger.FromContext(ctx).Infof("Tag %d updated: %q", tagID, updated.FullName)
+	return updated, nil
+}
+
+// Удаление тега со всех песен
+func (s *tagService) DeleteTag(ctx context.Context, tagID int) error {
+	err := s.repo.DeleteTag(ctx, tagID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: id %d", ErrTagNotFound, tagID)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("Error deleting tag: ", err)
+		return err
+	}
+
+	logger.FromContext(ctx).Infof("Tag %d deleted", tagID)
+	return nil
+}
+
+// Массовое назначение тегов песням по идентификаторам или фильтру; отсутствующие теги создаются
+func (s *tagService) AssignTags(ctx context.Context, names []string, songs models.SongSelection) (int64, error) {
+	tags, songs, err := prepareTagOperation(names, songs)
+	if err != nil {
+		return 0, err
+	}
+
+	assigned, err := s.repo.AssignTags(ctx, tags, songs)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error assigning tags: ", err)
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Infof("Tags %v assigned: %d new assignments", fullNames(tags), assigned)
+	return assigned, nil
+}
+
+// Массовое снятие тегов с песен по идентификаторам или фильтру
+func (s *tagService) UnassignTags(ctx context.Context, names []string, songs models.SongSelection) (int64, error) {
+	tags, songs, err := prepareTagOperation(names, songs)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := s.repo.UnassignTags(ctx, fullNames(tags), songs)
+	if err != nil {
+		logger.FromContext(ctx).Error("Error unassigning tags: ", err)
+		return 0, err
+	}
+
+	logger.FromContext(ctx).Infof("Tags %v unassigned: %d assignments removed", fullNames(tags), removed)
+	return removed, nil
+}
+
+// Число песен с каждым тегом среди отобранных фильтром, для фасетов в интерфейсе
+func (s *tagService) TagFacets(ctx context.Context, filter models.SongFilter, namespace string) ([]models.TagCount, error) {
+	filter, err := normalizeSongFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.repo.TagFacets(ctx, filter, strings.ToLower(strings.TrimSpace(namespace)))
+	if err != nil {
+		logger.FromContext(ctx).Error("Error counting tag facets: ", err)
+		return nil, err
+	}
+	return facets, nil
+}
+
+// prepareTagOperation проверяет теги и выбор песен массовой операции: нужен ровно один из списка идентификаторов и фильтра
+func prepareTagOperation(names []string, songs models.SongSelection) ([]models.Tag, models.SongSelection, error) {
+	if len(names) == 0 {
+		return nil, songs, fmt.Errorf("%w: tags are required", ErrInvalidTag)
+	}
+	tags, err := parseTags(names)
+	if err != nil {
+		return nil, songs, err
+	}
+
+	if (len(songs.SongIDs) > 0) == (songs.Filter != nil) {
+		return nil, songs, fmt.Errorf("%w: either song_ids or filter must be set", ErrInvalidTag)
+	}
+	if songs.Filter != nil {
+		filter, err := normalizeSongFilter(*songs.Filter)
+		if err != nil {
+			return nil, songs, err
+		}
+		songs.Filter = &filter
+	}
+	return tags, songs, nil
+}
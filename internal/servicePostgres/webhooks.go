@@ -0,0 +1,430 @@
+package servicePostgres
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"musPlayer/internal/logger"
+	"musPlayer/internal/metrics"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrWebhookNotFound возвращается, когда подписки с таким идентификатором нет
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound возвращается, когда доставки с таким идентификатором нет
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidWebhook возвращается при неверном адресе или списке событий подписки
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrForbiddenWebhookTarget возвращается, когда адрес подписки ведет во внутреннюю сеть
+	ErrForbiddenWebhookTarget = errors.New("webhook target address is not allowed")
+)
+
+// Заголовки запроса доставки
+const (
+	WebhookEventHeader     = "X-MusPlayer-Event"
+	WebhookEventIDHeader   = "X-MusPlayer-Event-ID"
+	WebhookDeliveryHeader  = "X-MusPlayer-Delivery"
+	WebhookTimestampHeader = "X-MusPlayer-Timestamp"
+	WebhookSignatureHeader = "X-MusPlayer-Signature"
+)
+
+const (
+	// Сколько доставок отправляется одновременно
+	webhookWorkers    = 8
+	maxWebhookSecret  = 255
+	webhookPurgeEvery = time.Hour
+)
+
+// sharedAddressSpace — 100.64.0.0/10 (RFC 6598): адреса операторов и облачных сервисов метаданных
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// allowedWebhookAddr сообщает, можно ли отправлять доставки на адрес: петлевые, частные, локальные
+// для канала, групповые и неуказанные адреса закрыты, иначе подписка позволила бы обращаться
+// к внутренним сервисам и метаданным облака от имени musPlayer
+func allowedWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
+
+// SignWebhook возвращает подпись тела доставки: HMAC-SHA256 от "<timestamp>.<тело>" на секрете подписки
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookService struct {
+	repo   postgresrepo.WebhookRepository
+	client *http.Client
+	cfg    models.WebhooksConfig
+}
+
+func NewWebhookService(repo postgresrepo.WebhookRepository, cfg models.WebhooksConfig) WebhookService {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		// Адрес проверяется после разрешения имени, непосредственно перед соединением:
+		// проверка при создании подписки не защищает от смены DNS-записи
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowedWebhookAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrForbiddenWebhookTarget, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	return &webhookService{
+		repo: repo,
+		client: &http.Client{
+			Transport: &http.Transport{
+				// Прокси из окружения не используется: через него проверка адреса потеряла бы смысл
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   cfg.Timeout,
+				ResponseHeaderTimeout: cfg.Timeout,
+				MaxIdleConns:          webhookWorkers * 4,
+				IdleConnTimeout:       90 * time.Second,
+			},
+			Timeout: cfg.Timeout,
+			// Перенаправление POST превращается в GET; подписчик должен указать окончательный адрес
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg: cfg,
+	}
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subs, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhook(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sub, ErrWebhookNotFound
+	}
+	sub.Secret = ""
+	return sub, err
+}
+
+// CreateWebhook создает подписку. Если секрет не задан, он генерируется; секрет возвращается только здесь
+func (s *webhookService) CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if err := s.validateWebhook(ctx, &sub); err != nil {
+		return sub, err
+	}
+	if sub.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return sub, err
+		}
+		sub.Secret = hex.EncodeToString(secret)
+	}
+
+	created, err := s.repo.CreateWebhook(ctx, sub)
+	if err != nil {
+		return created, err
+	}
+
+	logger.FromContext(ctx).Infof("Webhook %d created for %s", created.ID, created.URL)
+	return created, nil
+}
+
+// UpdateWebhook заменяет адрес, события, активность и описание подписки; пустой секрет оставляет прежний
+func (s *webhookService) UpdateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if err := s.validateWebhook(ctx, &sub); err != nil {
+		return sub, err
+	}
+
+	updated, err := s.repo.UpdateWebhook(ctx, sub)
+	if errors.Is(err, sql.ErrNoRows) {
+		return updated, ErrWebhookNotFound
+	}
+	updated.Secret = ""
+	return updated, err
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	err := s.repo.DeleteWebhook(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	if _, err := s.repo.GetWebhook(ctx, subscriptionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, subscriptionID, status, limit, offset)
+}
+
+// Redeliver ставит доставку в очередь заново, в том числе уже доставленную или исчерпавшую попытки
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID int64) (models.WebhookDelivery, error) {
+	d, err := s.repo.Redeliver(ctx, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+func (s *webhookService) validateWebhook(ctx context.Context, sub *models.WebhookSubscription) error {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: url must not contain credentials", ErrInvalidWebhook)
+	}
+	if !s.cfg.AllowPrivateTargets {
+		if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+			return err
+		}
+	}
+	if len(sub.Secret) > maxWebhookSecret {
+		return fmt.Errorf("%w: secret must be at most %d bytes", ErrInvalidWebhook, maxWebhookSecret)
+	}
+
+	events := make([]string, 0, len(sub.Events))
+	seen := make(map[string]bool, len(sub.Events))
+	for _, e := range sub.Events {
+		if !isWebhookEvent(e) {
+			return fmt.Errorf("%w: unknown event %q, expected one of %s", ErrInvalidWebhook, e, strings.Join(models.WebhookEventTypes, ", "))
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	sub.Events = events
+
+	return nil
+}
+
+// checkWebhookHost проверяет, что все адреса хоста подписки доступны для доставки
+func checkWebhookHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !allowedWebhookAddr(addr) {
+			return fmt.Errorf("%w: %w: %s", ErrInvalidWebhook, ErrForbiddenWebhookTarget, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve host %q", ErrInvalidWebhook, host)
+	}
+	for _, addr := range addrs {
+		if !allowedWebhookAddr(addr) {
+			return fmt.Errorf("%w: %w: %s resolves to %s", ErrInvalidWebhook, ErrForbiddenWebhookTarget, host, addr)
+		}
+	}
+	return nil
+}
+
+func isWebhookEvent(e string) bool {
+	for _, t := range models.WebhookEventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// RunDispatcher доставляет события подписчикам, пока не отменен ctx
+func (s *webhookService) RunDispatcher(ctx context.Context) error {
+	if !s.cfg.Enabled {
+		logger.Logger.Info("Webhook dispatcher is disabled")
+		return nil
+	}
+
+	ticker := time.NewTicker(s.cfg.DispatchInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(webhookPurgeEvery)
+	defer purge.Stop()
+
+	for {
+		s.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-purge.C:
+			s.purgeEvents(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch отправляет доставки, которым пора уходить, пока очередь не опустеет
+func (s *webhookService) dispatch(ctx context.Context) {
+	// Доставка держится за диспетчером, пока ее могут отправлять все воркеры по очереди
+	lease := s.cfg.Timeout*time.Duration(s.cfg.BatchSize/webhookWorkers+1) + time.Minute
+
+	for ctx.Err() == nil {
+		batch, err := s.repo.ClaimDeliveries(ctx, s.cfg.BatchSize, lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Logger.Errorf("Failed to claim webhook deliveries: %v", err)
+			}
+			return
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, webhookWorkers)
+		for _, p := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(p models.PendingDelivery) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				s.deliver(ctx, p)
+			}(p)
+		}
+		wg.Wait()
+
+		if len(batch) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver отправляет одну доставку и записывает результат
+func (s *webhookService) deliver(ctx context.Context, p models.PendingDelivery) {
+	code, retryAfter, err := s.send(ctx, p)
+	if ctx.Err() != nil {
+		// Остановка сервиса: доставка повторится после истечения lease
+		return
+	}
+
+	attempt := models.DeliveryAttempt{DeliveryID: p.ID, Status: models.DeliveryStatusDelivered}
+	if code != 0 {
+		attempt.ResponseCode = &code
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.Status = models.DeliveryStatusFailed
+		attempt.RetryAfter = s.backoff(p.Attempts + 1)
+		if retryAfter > attempt.RetryAfter {
+			attempt.RetryAfter = min(retryAfter, s.cfg.RetryMaxDelay)
+		}
+		if p.Attempts+1 >= s.cfg.MaxAttempts {
+			attempt.Status = models.DeliveryStatusDead
+		}
+	}
+
+	switch attempt.Status {
+	case models.DeliveryStatusDelivered:
+		metrics.Webhooks.Add(metrics.WebhooksDelivered, 1)
+	case models.DeliveryStatusFailed:
+		metrics.Webhooks.Add(metrics.WebhooksFailed, 1)
+		logger.Logger.Warnf("Webhook delivery %d of event %d failed (attempt %d), retrying in %s: %v", p.ID, p.Event.ID, p.Attempts+1, attempt.RetryAfter, err)
+	case models.DeliveryStatusDead:
+		metrics.Webhooks.Add(metrics.WebhooksFailed, 1)
+		metrics.Webhooks.Add(metrics.WebhooksDead, 1)
+		logger.Logger.Errorf("Webhook delivery %d of event %d is dead after %d attempts: %v", p.ID, p.Event.ID, p.Attempts+1, err)
+	}
+
+	if err := s.repo.RecordAttempt(ctx, attempt); err != nil && ctx.Err() == nil {
+		logger.Logger.Errorf("Failed to record webhook delivery %d: %v", p.ID, err)
+	}
+}
+
+// send выполняет запрос доставки. Возвращает код ответа (0, если ответа нет) и паузу из Retry-After
+func (s *webhookService) send(ctx context.Context, p models.PendingDelivery) (int, time.Duration, error) {
+	body, err := json.Marshal(p.Event)
+	if err != nil {
+		return 0, 0, err
+	}
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "musPlayer-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, p.Event.Type)
+	req.Header.Set(WebhookEventIDHeader, strconv.FormatInt(p.Event.ID, 10))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(p.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(p.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	// Тело ответа подписчика не сохраняется: журнал доставок не должен пересказывать чужие ответы
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, 0, nil
+	}
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return resp.StatusCode, retryAfter, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// backoff — пауза перед повтором после attempt неудачных попыток: база удваивается до потолка
+func (s *webhookService) backoff(attempt int) time.Duration {
+	delay := s.cfg.RetryBaseDelay
+	for i := 1; i < attempt && delay < s.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, s.cfg.RetryMaxDelay)
+}
+
+func (s *webhookService) purgeEvents(ctx context.Context) {
+	n, err := s.repo.PurgeEvents(ctx, time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Logger.Errorf("Failed to purge webhook events: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		logger.Logger.Infof("Purged %d delivered webhook events", n)
+	}
+}
@@ -1,24 +1,68 @@
 package models
 
+import "time"
+
+// Хранилища, которые можно выбрать в database.driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 type DatabaseConfig struct {
+	Driver   string // postgres, sqlite или memory
+	Path     string // файл базы SQLite
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
+
+	URL         string // строка подключения или postgres:// URL; заменяет host, port, user, password, name и TLS
+	SSLMode     string // disable, require, verify-ca или verify-full
+	SSLRootCert string // CA для verify-ca и verify-full
+	SSLCert     string // клиентский сертификат
+	SSLKey      string
+
+	PgDriver           string // pq или pgx
+	StatementCacheSize int    // подготовленных запросов на соединение в pgx; 0 — без кеша
+	StatementTimeout   time.Duration
+	ConnectTimeout     time.Duration
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	ConnectAttempts   int           // попыток подключения при старте
+	ConnectRetryDelay time.Duration // пауза после первой неудачной попытки, затем удваивается
 }
 
+// Драйверы Postgres для database/sql
+const (
+	PgDriverPQ  = "pq"
+	PgDriverPgx = "pgx"
+)
+
 type APIConfig struct {
 	BaseURL string
 	Key     string
 }
 
 type LoggingConfig struct {
-	Level string
+	Level          string
+	Format         string
+	File           string
+	MaxSizeMB      int
+	RotateInterval time.Duration
+	MaxBackups     int
 }
 
 type AppConfig struct {
-	Port string
+	Port             string
+	ReadinessTimeout time.Duration
+	ShutdownTimeout  time.Duration
+	DrainDelay       time.Duration
 }
 
 type GeniusConfig struct {
@@ -29,4 +73,78 @@ type GeniusConfig struct {
 	AuthURL     string
 	TokenURL    string
 	Scope       string
+
+	ConnectTimeout   time.Duration
+	ReadTimeout      time.Duration
+	RequestTimeout   time.Duration
+	MaxRetries       int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	MatchThreshold   float64
+}
+
+type CacheConfig struct {
+	LRUSize         int
+	SearchTTL       time.Duration
+	LyricsTTL       time.Duration
+	AnalysisTTL     time.Duration
+	CleanupInterval time.Duration
+}
+
+type SyncConfig struct {
+	Enabled      bool
+	Interval     time.Duration
+	MaxAge       time.Duration
+	BatchSize    int
+	RequestDelay time.Duration
+}
+
+type ListensConfig struct {
+	IngestEnabled      bool
+	IngestInterval     time.Duration
+	IngestBatchSize    int
+	IngestRequestDelay time.Duration
+	PlayingNowTTL      time.Duration
+	UserTokens         map[string]string // токен ListenBrainz -> пользователь
+}
+
+type WebhooksConfig struct {
+	Enabled          bool
+	DispatchInterval time.Duration
+	BatchSize        int
+	Timeout          time.Duration
+	MaxAttempts      int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	Retention        time.Duration
+	// AllowPrivateTargets разрешает подписки на петлевые и частные адреса (локальная разработка)
+	AllowPrivateTargets bool
+}
+
+type StreamConfig struct {
+	HeartbeatInterval time.Duration
+	BufferSize        int
+	ResumeLimit       int
+}
+
+type ChangesConfig struct {
+	Retention time.Duration
+}
+
+type GraphQLConfig struct {
+	Introspection bool
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type GRPCConfig struct {
+	Enabled    bool
+	Port       string
+	Reflection bool
+}
+
+type SimilarityConfig struct {
+	RefreshInterval time.Duration
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Операции ленты изменений
+const (
+	ChangeOpUpsert = "upsert"
+	ChangeOpDelete = "delete"
+)
+
+// SongChange — последнее изменение песни: upsert с текущим состоянием песни или delete (надгробие)
+type SongChange struct {
+	Seq       int64     `json:"seq"`
+	Op        string    `json:"op"`
+	SongID    int       `json:"song_id"`
+	Song      *Song     `json:"song,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ChangeFeedPage — страница ленты изменений. Cursor передается в since следующего запроса.
+// ResyncRequired — курсор старше хранимых надгробий: клиенту нужна полная синхронизация, после которой
+// продолжать с Cursor
+type ChangeFeedPage struct {
+	Changes        []SongChange `json:"changes"`
+	Cursor         int64        `json:"cursor"`
+	HasMore        bool         `json:"has_more"`
+	ResyncRequired bool         `json:"resync_required,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LibrarySong — песня в личной библиотеке пользователя
+type LibrarySong struct {
+	Song
+	Favorite bool      `json:"favorite"`
+	MyRating *int      `json:"my_rating"`
+	Note     string    `json:"note,omitempty"` // видна только владельцу библиотеки
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// SongRating — оценки песни: средняя по всем пользователям и оценка текущего пользователя
+type SongRating struct {
+	SongID      int      `json:"song_id"`
+	RatingAvg   *float64 `json:"rating_avg"`
+	RatingCount int      `json:"rating_count"`
+	MyRating    *int     `json:"my_rating"`
+}
@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// Типы отправки прослушиваний ListenBrainz
+const (
+	ListenTypeSingle     = "single"
+	ListenTypePlayingNow = "playing_now"
+	ListenTypeImport     = "import"
+)
+
+// Статусы очереди песен, найденных в прослушиваниях, но отсутствующих в каталоге
+const (
+	IngestStatusPending   = "pending"
+	IngestStatusMatched   = "matched"
+	IngestStatusNotFound  = "not_found"
+	IngestStatusAmbiguous = "ambiguous"
+	IngestStatusFailed    = "failed"
+)
+
+// ListenSubmission — тело запроса submit-listens ListenBrainz
+type ListenSubmission struct {
+	ListenType string          `json:"listen_type"`
+	Payload    []ListenPayload `json:"payload"`
+}
+
+// ListenPayload — одно прослушивание; у playing_now нет listened_at
+type ListenPayload struct {
+	ListenedAt    *int64        `json:"listened_at,omitempty"` // Unix-время в секундах
+	TrackMetadata TrackMetadata `json:"track_metadata"`
+}
+
+// TrackMetadata — описание трека ListenBrainz. Из additional_info используются duration_ms, duration,
+// recording_mbid и submission_client; остальные поля сохраняются как есть
+type TrackMetadata struct {
+	ArtistName     string                 `json:"artist_name"`
+	TrackName      string                 `json:"track_name"`
+	ReleaseName    string                 `json:"release_name,omitempty"`
+	AdditionalInfo map[string]interface{} `json:"additional_info,omitempty"`
+}
+
+// Listen — сохраненное прослушивание. SongID пустой, пока трек не сопоставлен с песней каталога
+type Listen struct {
+	ListenedAt       time.Time              `json:"listened_at"`
+	SongID           *int                   `json:"song_id"`
+	ArtistName       string                 `json:"artist_name"`
+	TrackName        string                 `json:"track_name"`
+	ReleaseName      string                 `json:"release_name,omitempty"`
+	DurationMs       *int                   `json:"duration_ms,omitempty"`
+	RecordingMBID    string                 `json:"recording_mbid,omitempty"`
+	SubmissionClient string                 `json:"submission_client,omitempty"`
+	AdditionalInfo   map[string]interface{} `json:"additional_info,omitempty"`
+}
+
+// PlayingNow — трек, который пользователь слушает сейчас
+type PlayingNow struct {
+	Listen
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SongPlayCount — число прослушиваний трека. Для сопоставленных треков имя и название берутся из каталога
+type SongPlayCount struct {
+	SongID         *int      `json:"song_id"`
+	ArtistName     string    `json:"artist_name"`
+	TrackName      string    `json:"track_name"`
+	Count          int       `json:"count"`
+	LastListenedAt time.Time `json:"last_listened_at"`
+}
+
+// ArtistPlayCount — число прослушиваний исполнителя и разных его треков
+type ArtistPlayCount struct {
+	ArtistName string `json:"artist_name"`
+	Count      int    `json:"count"`
+	Tracks     int    `json:"tracks"`
+}
+
+// ListenStreak — непрерывная серия дней, в каждый из которых было хотя бы одно прослушивание
+type ListenStreak struct {
+	Start string `json:"start"` // даты в формате YYYY-MM-DD в часовом поясе запроса
+	End   string `json:"end"`
+	Days  int    `json:"days"`
+}
+
+// ListenStreaks — текущая и самая длинная серии. Текущая серия продолжается, если последнее прослушивание было сегодня или вчера
+type ListenStreaks struct {
+	Current    *ListenStreak `json:"current"`
+	Longest    *ListenStreak `json:"longest"`
+	ActiveDays int           `json:"active_days"`
+	TimeZone   string        `json:"time_zone"`
+}
+
+// IngestQueueItem — трек из прослушиваний, который ищется в каталоге Genius
+type IngestQueueItem struct {
+	ID          int       `json:"id"`
+	ArtistName  string    `json:"artist_name"`
+	TrackName   string    `json:"track_name"`
+	ListenCount int       `json:"listen_count"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	SongID      *int      `json:"song_id"`
+	LastError   string    `json:"last_error,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// ListenStatsRange — период статистики прослушиваний
+type ListenStatsRange struct {
+	From  time.Time
+	To    time.Time
+	Limit int
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Виды версий текста песни
+const (
+	LyricsOriginal        = "original"
+	LyricsTranslation     = "translation"
+	LyricsTransliteration = "transliteration"
+	LyricsUser            = "user"
+)
+
+// Источники версий текста
+const (
+	LyricsSourceGenius   = "genius"
+	LyricsSourceManual   = "manual"
+	LyricsSourceTranslit = "translit" // сгенерирована из оригинала, пересоздается при его изменении
+	LyricsSourceUser     = "user"
+)
+
+// LyricsVersion — версия текста песни: оригинал, перевод или транслитерация
+type LyricsVersion struct {
+	ID          int       `json:"id"`
+	SongID      int       `json:"song_id"`
+	Lang        string    `json:"lang"` // тег BCP 47: ru, en, ru-Latn
+	Kind        string    `json:"kind"`
+	Variant     string    `json:"variant,omitempty"` // схема транслитерации или автор
+	Text        string    `json:"text,omitempty"`
+	Source      string    `json:"source"`
+	SourceURL   string    `json:"source_url,omitempty"`
+	SubmittedBy string    `json:"submitted_by,omitempty"`
+	IsDefault   bool      `json:"is_default"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SyncedLyrics — текст песни с временем каждой строки (LRC)
+type SyncedLyrics struct {
+	SongID    int               `json:"song_id"`
+	OffsetMs  int               `json:"offset_ms"` // как заголовок [offset:]: положительное значение показывает строки раньше
+	Tags      map[string]string `json:"tags,omitempty"`
+	Lines     []SyncedLine      `json:"lines"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// SyncedLine — строка синхронизированного текста. Время указано без учета смещения
+type SyncedLine struct {
+	LineNo int          `json:"line_no"`
+	TimeMs int          `json:"time_ms"`
+	Text   string       `json:"text"`
+	Words  []SyncedWord `json:"words,omitempty"`
+}
+
+// SyncedWord — слово enhanced LRC
+type SyncedWord struct {
+	TimeMs int    `json:"time_ms"`
+	Text   string `json:"text"`
+}
+
+// TermVector — частоты слов текста песни для индекса похожих песен
+type TermVector struct {
+	SongID    int
+	GroupName string
+	Terms     map[string]int
+}
+
+// SimilarSong — песня из библиотеки, похожая по тексту
+type SimilarSong struct {
+	Song
+	Score float64 `json:"score"`
+}
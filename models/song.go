@@ -7,12 +7,29 @@ type Song struct {
 	GroupName   string    `json:"group"`
 	SongName    string    `json:"song"`
 	ReleaseDate string    `json:"release_date"`
+	Album       string    `json:"album,omitempty"`
 	Text        string    `json:"text"`
 	Link        string    `json:"link"`
+	Tags        []string  `json:"tags,omitempty"`
+	RatingAvg   *float64  `json:"rating_avg"` // средняя оценка пользователей, null — оценок нет
+	RatingCount int       `json:"rating_count"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// SongFilter — условия отбора песен. Group и Song ищутся как подстрока без учета регистра,
+// теги задаются полными именами (genre:rock)
+type SongFilter struct {
+	IDs      []int // только песни с этими идентификаторами
+	AfterID  int   // только песни с id больше заданного, для постраничной выгрузки по id
+	Group    string
+	Song     string
+	TagsAll  []string // песня отмечена всеми тегами
+	TagsAny  []string // хотя бы одним
+	TagsNone []string // ни одним
+	Sort     string   // поле сортировки (id, group, song, release_date, rating, ...), «-» в начале — по убыванию
+}
+
 type SongUpdateParams struct {
 	ID          int    `json:"id"`
 	GroupName   string `json:"group"`
@@ -20,3 +37,39 @@ type SongUpdateParams struct {
 	ReleaseDate string `json:"release_date"`
 	Text        string `json:"text"`
 }
+
+// Статусы синхронизации песни с исходной страницей
+const (
+	SyncStatusPending   = "pending"
+	SyncStatusUnchanged = "unchanged"
+	SyncStatusUpdated   = "updated"
+	SyncStatusManual    = "manual_override"
+	SyncStatusSkipped   = "skipped"
+	SyncStatusFailed    = "failed"
+)
+
+// SongSyncState — песня вместе с состоянием синхронизации
+type SongSyncState struct {
+	Song
+	ManualFields []string   `json:"manual_fields"`
+	ContentHash  string     `json:"content_hash"`
+	LastSyncedAt *time.Time `json:"last_synced_at"`
+	SyncStatus   string     `json:"sync_status"`
+	SyncError    string     `json:"sync_error,omitempty"`
+}
+
+// FieldChange — изменение одного поля песни
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// SongSyncUpdate — результат синхронизации, который применяется к песне вместе с записью ревизии
+type SongSyncUpdate struct {
+	ID          int
+	Changes     map[string]FieldChange // ключ — имя колонки
+	ContentHash string
+	Status      string
+	Source      string
+	SourceURL   string
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Tag — тег песни. Namespace группирует теги (genre, mood, era); у произвольных тегов команды он пустой
+type Tag struct {
+	ID          int       `json:"id"`
+	Namespace   string    `json:"namespace,omitempty"`
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"` // namespace:name или name
+	Description string    `json:"description,omitempty"`
+	SongCount   int       `json:"song_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TagCount — число песен с тегом среди отобранных фильтром
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// SongSelection — песни, к которым применяется массовая операция: список идентификаторов или фильтр
+type SongSelection struct {
+	SongIDs []int
+	Filter  *SongFilter
+}
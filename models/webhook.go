@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// События каталога, на которые можно подписаться
+const (
+	EventSongCreated = "song.created"
+	EventSongUpdated = "song.updated"
+	EventSongDeleted = "song.deleted"
+)
+
+// WebhookEventTypes — все события в порядке документации
+var WebhookEventTypes = []string{EventSongCreated, EventSongUpdated, EventSongDeleted}
+
+// Источники изменения песни в событиях
+const (
+	EventSourceAPI  = "api"
+	EventSourceSync = "sync"
+)
+
+// Статусы доставки события подписке
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed" // попытка не удалась, будет повтор
+	DeliveryStatusDead      = "dead"   // попытки исчерпаны
+)
+
+// WebhookSubscription — подписка на события. Пустой Events — все события.
+// Секрет отдается только при создании подписки
+type WebhookSubscription struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SongEventData — данные события песни; у song.deleted — песня перед удалением
+type SongEventData struct {
+	Song   Song   `json:"song"`
+	Source string `json:"source"`
+}
+
+// WebhookEvent — тело запроса, которое получает подписчик
+type WebhookEvent struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// WebhookDelivery — доставка события подписке и результат последней попытки
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	SongID         int        `json:"song_id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseCode   *int       `json:"response_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// PendingDelivery — доставка, взятая диспетчером в работу, вместе с подпиской и событием
+type PendingDelivery struct {
+	ID       int64
+	Attempts int
+	URL      string
+	Secret   string
+	Event    WebhookEvent
+}
+
+// DeliveryAttempt — результат попытки доставки
+type DeliveryAttempt struct {
+	DeliveryID   int64
+	Status       string
+	ResponseCode *int
+	Error        string
+	RetryAfter   time.Duration // для статуса failed
+}
+
+// SongEvent — событие каталога для живых подписок; ID совпадает с идентификатором события outbox
+type SongEvent struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	SongEventData
+}
@@ -0,0 +1,248 @@
+// Package lrc читает и записывает синхронизированные тексты в форматах LRC и enhanced LRC
+package lrc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lyrics — разобранный LRC-файл
+type Lyrics struct {
+	// Tags — заголовки файла (ti, ar, al, by, ...), кроме offset
+	Tags map[string]string
+	// Offset — значение заголовка [offset:]: положительное смещение показывает строки раньше
+	Offset time.Duration
+	Lines  []Line
+}
+
+// Line — строка текста с временем начала
+type Line struct {
+	Time  time.Duration
+	Text  string
+	Words []Word // заполнено только для enhanced LRC
+}
+
+// Word — слово enhanced LRC с собственным временем начала
+type Word struct {
+	Time time.Duration
+	Text string
+}
+
+// ParseError описывает ошибку разбора с номером строки файла
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("lrc: line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Порядок стандартных заголовков при записи
+var tagOrder = []string{"ti", "ar", "al", "au", "lr", "by", "length", "re", "ve"}
+
+// Parse разбирает LRC или enhanced LRC. Строка с несколькими метками времени ([00:12.00][00:40.00]...)
+// повторяется для каждой метки; строки без меток игнорируются. Строки возвращаются отсортированными по времени
+func Parse(r io.Reader) (*Lyrics, error) {
+	l := &Lyrics{Tags: map[string]string{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if raw == "" {
+			continue
+		}
+
+		var times []time.Duration
+		rest := raw
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				break
+			}
+			tag := rest[1:end]
+			rest = rest[end+1:]
+
+			if t, ok := parseTimestamp(tag); ok {
+				times = append(times, t)
+				continue
+			}
+			key, value, ok := strings.Cut(tag, ":")
+			if !ok {
+				continue
+			}
+			key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+			if key == "offset" {
+				ms, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+				if err != nil {
+					return nil, &ParseError{Line: lineNo, Err: fmt.Errorf("invalid offset %q", value)}
+				}
+				l.Offset = time.Duration(ms) * time.Millisecond
+				continue
+			}
+			l.Tags[key] = value
+		}
+
+		if len(times) == 0 {
+			continue
+		}
+
+		text, words, err := parseWords(rest)
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Err: err}
+		}
+		for _, t := range times {
+			l.Lines = append(l.Lines, Line{Time: t, Text: text, Words: words})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(l.Lines, func(i, j int) bool {
+		return l.Lines[i].Time < l.Lines[j].Time
+	})
+	return l, nil
+}
+
+// parseWords выделяет слова с метками <mm:ss.xx>. Текст до первой метки входит только в текст строки
+func parseWords(s string) (string, []Word, error) {
+	if !strings.Contains(s, "<") {
+		return strings.TrimSpace(s), nil, nil
+	}
+
+	var words []Word
+	var plain strings.Builder
+	for s != "" {
+		start := strings.IndexByte(s, '<')
+		end := strings.IndexByte(s, '>')
+		if start < 0 || end < start {
+			plain.WriteString(s)
+			if len(words) > 0 {
+				words[len(words)-1].Text += s
+			}
+			break
+		}
+
+		before := s[:start]
+		plain.WriteString(before)
+		if len(words) > 0 {
+			words[len(words)-1].Text += before
+		}
+
+		t, ok := parseTimestamp(s[start+1 : end])
+		if !ok {
+			return "", nil, fmt.Errorf("invalid word timestamp %q", s[start:end+1])
+		}
+		words = append(words, Word{Time: t})
+		s = s[end+1:]
+	}
+
+	// Последняя метка enhanced LRC часто отмечает конец строки и не содержит слова
+	for len(words) > 0 && strings.TrimSpace(words[len(words)-1].Text) == "" {
+		words = words[:len(words)-1]
+	}
+	for i := range words {
+		words[i].Text = strings.TrimSpace(words[i].Text)
+	}
+
+	return strings.Join(strings.Fields(plain.String()), " "), words, nil
+}
+
+// parseTimestamp разбирает mm:ss, mm:ss.xx, mm:ss.xxx и mm:ss:xx
+func parseTimestamp(s string) (time.Duration, bool) {
+	minutes, rest, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	seconds, fraction, _ := strings.Cut(strings.Replace(rest, ":", ".", 1), ".")
+
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return 0, false
+	}
+	sec, err := strconv.Atoi(seconds)
+	if err != nil || sec < 0 || sec > 59 || len(seconds) > 2 {
+		return 0, false
+	}
+
+	t := time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+	if fraction != "" {
+		if len(fraction) > 3 {
+			return 0, false
+		}
+		f, err := strconv.Atoi(fraction)
+		if err != nil {
+			return 0, false
+		}
+		for i := len(fraction); i < 3; i++ {
+			f *= 10
+		}
+		t += time.Duration(f) * time.Millisecond
+	}
+	return t, true
+}
+
+// Format записывает текст в LRC. При enhanced слова записываются с метками <mm:ss.xx>
+func Format(l *Lyrics, enhanced bool) string {
+	var b strings.Builder
+
+	written := map[string]bool{}
+	for _, key := range tagOrder {
+		if value, ok := l.Tags[key]; ok {
+			fmt.Fprintf(&b, "[%s:%s]\n", key, value)
+			written[key] = true
+		}
+	}
+	var other []string
+	for key := range l.Tags {
+		if !written[key] && key != "offset" {
+			other = append(other, key)
+		}
+	}
+	sort.Strings(other)
+	for _, key := range other {
+		fmt.Fprintf(&b, "[%s:%s]\n", key, l.Tags[key])
+	}
+	if l.Offset != 0 {
+		fmt.Fprintf(&b, "[offset:%+d]\n", l.Offset.Milliseconds())
+	}
+
+	for _, line := range l.Lines {
+		b.WriteString("[" + FormatTimestamp(line.Time) + "]")
+		if enhanced && len(line.Words) > 0 {
+			for i, w := range line.Words {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString("<" + FormatTimestamp(w.Time) + ">" + w.Text)
+			}
+		} else {
+			b.WriteString(line.Text)
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// FormatTimestamp записывает время как mm:ss.xx
+func FormatTimestamp(t time.Duration) string {
+	if t < 0 {
+		t = 0
+	}
+	cs := (t.Milliseconds() + 5) / 10
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
@@ -0,0 +1,229 @@
+package lrc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{in: "00:12", want: ms(12000), ok: true},
+		{in: "01:02.5", want: ms(62500), ok: true},
+		{in: "01:02.50", want: ms(62500), ok: true},
+		{in: "01:02.505", want: ms(62505), ok: true},
+		{in: "01:02:50", want: ms(62500), ok: true},
+		{in: "123:00.00", want: 123 * time.Minute, ok: true},
+		{in: "00:60.00", ok: false},
+		{in: "00:1234", ok: false},
+		{in: "00:12.3456", ok: false},
+		{in: "-1:00", ok: false},
+		{in: "ti:Title", ok: false},
+		{in: "12", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTimestamp(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseTimestamp(%q) = %s, %v; want %s, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{in: 0, want: "00:00.00"},
+		{in: ms(62505), want: "01:02.51"},
+		{in: ms(62504), want: "01:02.50"},
+		{in: ms(59999), want: "01:00.00"},
+		{in: 100 * time.Minute, want: "100:00.00"},
+		{in: -time.Second, want: "00:00.00"},
+	}
+	for _, tt := range tests {
+		if got := FormatTimestamp(tt.in); got != tt.want {
+			t.Errorf("FormatTimestamp(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         string
+		wantTags   map[string]string
+		wantOffset time.Duration
+		wantLines  []Line
+	}{
+		{
+			name: "tags and lines",
+			in: "\ufeff[ti:Группа крови]\n[ar:Кино]\n\n[00:12.00]Теплое место\n" +
+				"Строка без метки\n[00:15.50]Но улицы ждут\n",
+			wantTags: map[string]string{"ti": "Группа крови", "ar": "Кино"},
+			wantLines: []Line{
+				{Time: ms(12000), Text: "Теплое место"},
+				{Time: ms(15500), Text: "Но улицы ждут"},
+			},
+		},
+		{
+			name:     "multiple timestamps per line are sorted",
+			in:       "[00:40.00][00:10.00]Припев\n[00:20.00]Куплет\n",
+			wantTags: map[string]string{},
+			wantLines: []Line{
+				{Time: ms(10000), Text: "Припев"},
+				{Time: ms(20000), Text: "Куплет"},
+				{Time: ms(40000), Text: "Припев"},
+			},
+		},
+		{
+			name:       "positive offset",
+			in:         "[offset:+500]\n[00:01.00]a\n",
+			wantTags:   map[string]string{},
+			wantOffset: ms(500),
+			wantLines:  []Line{{Time: ms(1000), Text: "a"}},
+		},
+		{
+			name:       "negative offset",
+			in:         "[OFFSET: -250]\n[00:01.00]a\n",
+			wantTags:   map[string]string{},
+			wantOffset: ms(-250),
+			wantLines:  []Line{{Time: ms(1000), Text: "a"}},
+		},
+		{
+			name:     "enhanced word timestamps",
+			in:       "[00:01.00]<00:01.00>Группа <00:01.60>крови <00:02.40>\n",
+			wantTags: map[string]string{},
+			wantLines: []Line{{
+				Time: ms(1000),
+				Text: "Группа крови",
+				Words: []Word{
+					{Time: ms(1000), Text: "Группа"},
+					{Time: ms(1600), Text: "крови"},
+				},
+			}},
+		},
+		{
+			name:     "empty line keeps its time",
+			in:       "[00:05.00]\n",
+			wantTags: map[string]string{},
+			wantLines: []Line{
+				{Time: ms(5000), Text: ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := Parse(strings.NewReader(tt.in))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(l.Tags, tt.wantTags) {
+				t.Errorf("Tags = %v, want %v", l.Tags, tt.wantTags)
+			}
+			if l.Offset != tt.wantOffset {
+				t.Errorf("Offset = %s, want %s", l.Offset, tt.wantOffset)
+			}
+			if !reflect.DeepEqual(l.Lines, tt.wantLines) {
+				t.Errorf("Lines = %+v, want %+v", l.Lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		wantLine int
+	}{
+		{name: "invalid offset", in: "[ti:a]\n[offset:soon]\n", wantLine: 2},
+		{name: "invalid word timestamp", in: "[00:01.00]a\n[00:02.00]<1:xx>b\n", wantLine: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.in))
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error = %v, want ParseError", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Fatalf("error line = %d, want %d", parseErr.Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestFormatParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		lyrics   Lyrics
+		enhanced bool
+		want     string
+	}{
+		{
+			name: "plain",
+			lyrics: Lyrics{
+				Tags:   map[string]string{"by": "musPlayer", "ar": "Кино", "ti": "Кукушка", "x-note": "test"},
+				Offset: ms(-300),
+				Lines: []Line{
+					{Time: ms(1000), Text: "Песен еще ненаписанных"},
+					{Time: ms(62500), Text: "Сколько?"},
+				},
+			},
+			want: "[ti:Кукушка]\n[ar:Кино]\n[by:musPlayer]\n[x-note:test]\n[offset:-300]\n" +
+				"[00:01.00]Песен еще ненаписанных\n[01:02.50]Сколько?\n",
+		},
+		{
+			name: "enhanced",
+			lyrics: Lyrics{
+				Tags:   map[string]string{},
+				Offset: ms(200),
+				Lines: []Line{{
+					Time:  ms(1000),
+					Text:  "Группа крови",
+					Words: []Word{{Time: ms(1000), Text: "Группа"}, {Time: ms(1600), Text: "крови"}},
+				}},
+			},
+			enhanced: true,
+			want:     "[offset:+200]\n[00:01.00]<00:01.00>Группа <00:01.60>крови\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := Format(&tt.lyrics, tt.enhanced)
+			if out != tt.want {
+				t.Fatalf("Format =\n%s\nwant\n%s", out, tt.want)
+			}
+
+			parsed, err := Parse(strings.NewReader(out))
+			if err != nil {
+				t.Fatalf("Parse(Format()): %v", err)
+			}
+			if !reflect.DeepEqual(*parsed, tt.lyrics) {
+				t.Fatalf("round trip = %+v, want %+v", *parsed, tt.lyrics)
+			}
+		})
+	}
+}
+
+func TestFormatWithoutEnhancedDropsWords(t *testing.T) {
+	l := &Lyrics{Lines: []Line{{
+		Time:  ms(1000),
+		Text:  "Группа крови",
+		Words: []Word{{Time: ms(1000), Text: "Группа"}, {Time: ms(1600), Text: "крови"}},
+	}}}
+	if got, want := Format(l, false), "[00:01.00]Группа крови\n"; got != want {
+		t.Fatalf("Format = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,292 @@
+// Package lyricstats считает статистику текстов песен: частоты слов, разнообразие словаря,
+// повторяющиеся блоки строк (кандидаты в припев) и оценку времени чтения и исполнения
+package lyricstats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Скорость чтения текста про себя, слов в минуту
+	readingWordsPerMinute = 200
+	// Средний темп исполнения, слогов в секунду
+	singingSyllablesPerSecond = 3.5
+)
+
+// Analysis — статистика одного или нескольких текстов
+type Analysis struct {
+	Songs            int         `json:"songs"`
+	Lines            int         `json:"lines"`
+	Tokens           int         `json:"tokens"`
+	UniqueWords      int         `json:"unique_words"`
+	LexicalDiversity float64     `json:"lexical_diversity"` // доля уникальных слов среди всех
+	TopWords         []WordCount `json:"top_words"`
+	Choruses         []Chorus    `json:"chorus_candidates"`
+	ReadingSeconds   float64     `json:"reading_seconds"`
+	SingingSeconds   float64     `json:"singing_seconds"`
+}
+
+// WordCount — слово и число его употреблений
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Chorus — группа подряд идущих строк, которая повторяется в тексте
+type Chorus struct {
+	Lines       []string `json:"lines"`
+	Occurrences int      `json:"occurrences"`
+	Label       string   `json:"label,omitempty"` // заголовок секции, например [Chorus], если он был
+}
+
+// Analyze считает статистику по текстам. Заголовки секций ([Verse 1]) в подсчет слов не входят,
+// стоп-слова русского и английского языков не попадают в топ. Припевы ищутся внутри каждого текста отдельно
+func Analyze(texts []string, topN int) Analysis {
+	a := Analysis{Songs: len(texts)}
+
+	counts := map[string]int{}
+	syllables := 0
+	for _, text := range texts {
+		lines := splitLines(text)
+		for _, line := range lines {
+			if line.header {
+				continue
+			}
+			a.Lines++
+			for _, word := range Tokenize(line.text) {
+				a.Tokens++
+				counts[word]++
+				syllables += countSyllables(word)
+			}
+		}
+		a.Choruses = append(a.Choruses, findChoruses(lines)...)
+	}
+
+	a.UniqueWords = len(counts)
+	if a.Tokens > 0 {
+		a.LexicalDiversity = round(float64(a.UniqueWords)/float64(a.Tokens), 3)
+	}
+	a.ReadingSeconds = round(float64(a.Tokens)/readingWordsPerMinute*60, 1)
+	a.SingingSeconds = round(float64(syllables)/singingSyllablesPerSecond, 1)
+
+	for word, count := range counts {
+		if stopWords[word] {
+			continue
+		}
+		a.TopWords = append(a.TopWords, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(a.TopWords, func(i, j int) bool {
+		if a.TopWords[i].Count != a.TopWords[j].Count {
+			return a.TopWords[i].Count > a.TopWords[j].Count
+		}
+		return a.TopWords[i].Word < a.TopWords[j].Word
+	})
+	if topN > 0 && len(a.TopWords) > topN {
+		a.TopWords = a.TopWords[:topN]
+	}
+	if a.TopWords == nil {
+		a.TopWords = []WordCount{}
+	}
+	if a.Choruses == nil {
+		a.Choruses = []Chorus{}
+	}
+
+	return a
+}
+
+// Tokenize разбивает строку на слова в нижнем регистре. Апостроф и дефис внутри слова сохраняются (don't, кто-то), ё заменяется на е
+func Tokenize(s string) []string {
+	var words []string
+	var b strings.Builder
+
+	runes := []rune(s)
+	flush := func() {
+		if b.Len() > 0 {
+			words = append(words, b.String())
+			b.Reset()
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			r = unicode.ToLower(r)
+			if r == 'ё' {
+				r = 'е'
+			}
+			b.WriteRune(r)
+		case (r == '\'' || r == '’' || r == '-') && b.Len() > 0 && i+1 < len(runes) && unicode.IsLetter(runes[i+1]):
+			if r == '’' {
+				r = '\''
+			}
+			b.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return words
+}
+
+// Terms возвращает частоты значимых слов текста: без заголовков секций, стоп-слов и однобуквенных слов
+func Terms(text string) map[string]int {
+	terms := map[string]int{}
+	for _, line := range splitLines(text) {
+		if line.header {
+			continue
+		}
+		for _, word := range strings.Fields(line.norm) {
+			if stopWords[word] || len([]rune(word)) < 2 {
+				continue
+			}
+			terms[word]++
+		}
+	}
+	return terms
+}
+
+type textLine struct {
+	text   string
+	norm   string
+	header bool
+	label  string // заголовок секции, к которой относится строка
+}
+
+func splitLines(text string) []textLine {
+	var lines []textLine
+	label := ""
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]") {
+			label = raw
+			lines = append(lines, textLine{text: raw, header: true})
+			continue
+		}
+		lines = append(lines, textLine{text: raw, norm: strings.Join(Tokenize(raw), " "), label: label})
+	}
+	return lines
+}
+
+type run struct {
+	start, length int
+}
+
+// findChoruses ищет самые длинные повторяющиеся последовательности из двух и более строк
+func findChoruses(all []textLine) []Chorus {
+	var lines []textLine
+	for _, l := range all {
+		if !l.header && l.norm != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	// Для каждой пары одинаковых строк — длина совпадающей последовательности, начиная с них
+	occurrences := map[string]map[int]bool{}
+	runs := map[string]run{}
+	for i := range lines {
+		for j := i + 1; j < len(lines); j++ {
+			k := 0
+			for i+k < j && j+k < len(lines) && lines[i+k].norm == lines[j+k].norm {
+				k++
+			}
+			if k < 2 {
+				continue
+			}
+			key := joinNorm(lines[i : i+k])
+			if occurrences[key] == nil {
+				occurrences[key] = map[int]bool{}
+				runs[key] = run{start: i, length: k}
+			}
+			occurrences[key][i] = true
+			occurrences[key][j] = true
+		}
+	}
+
+	keys := make([]string, 0, len(runs))
+	for key := range runs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		ra, rb := runs[keys[a]], runs[keys[b]]
+		if ra.length != rb.length {
+			return ra.length > rb.length
+		}
+		if len(occurrences[keys[a]]) != len(occurrences[keys[b]]) {
+			return len(occurrences[keys[a]]) > len(occurrences[keys[b]])
+		}
+		return ra.start < rb.start
+	})
+
+	// Короткие повторы, которые целиком входят в уже найденный припев, не показываем
+	var choruses []Chorus
+	var selected []string
+	for _, key := range keys {
+		covered := false
+		for _, s := range selected {
+			if strings.Contains(s, key) {
+				covered = true
+				break
+			}
+		}
+		if covered {
+			continue
+		}
+		selected = append(selected, key)
+
+		r := runs[key]
+		c := Chorus{Occurrences: len(occurrences[key]), Label: lines[r.start].label}
+		for _, l := range lines[r.start : r.start+r.length] {
+			c.Lines = append(c.Lines, l.text)
+		}
+		choruses = append(choruses, c)
+	}
+
+	return choruses
+}
+
+func joinNorm(lines []textLine) string {
+	parts := make([]string, len(lines))
+	for i, l := range lines {
+		parts[i] = l.norm
+	}
+	return "\n" + strings.Join(parts, "\n") + "\n"
+}
+
+// countSyllables оценивает число слогов: в русском слове слог дает каждая гласная, в английском — группа гласных
+func countSyllables(word string) int {
+	n := 0
+	prevVowel := false
+	cyrillic := false
+	for _, r := range word {
+		if strings.ContainsRune("аеиоуыэюя", r) {
+			n++
+			cyrillic = true
+			prevVowel = false
+			continue
+		}
+		v := strings.ContainsRune("aeiouy", r)
+		if v && !prevVowel {
+			n++
+		}
+		prevVowel = v
+	}
+	// Немое e в конце английских слов (love, time) не образует слога
+	if !cyrillic && n > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		n--
+	}
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,127 @@
+package lyricstats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "Группа крови на рукаве", want: []string{"группа", "крови", "на", "рукаве"}},
+		{in: "Ёлка, ёж и Ещё!", want: []string{"елка", "еж", "и", "еще"}},
+		{in: "Don’t stop — кто-то ждет", want: []string{"don't", "stop", "кто-то", "ждет"}},
+		{in: "'quoted' -dash- 1979", want: []string{"quoted", "dash", "1979"}},
+		{in: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		word string
+		want int
+	}{
+		{word: "группа", want: 2},
+		{word: "звезда", want: 2},
+		{word: "вкл", want: 1},
+		{word: "love", want: 1},
+		{word: "time", want: 1},
+		{word: "little", want: 2},
+		{word: "beautiful", want: 3},
+		{word: "the", want: 1},
+	}
+	for _, tt := range tests {
+		if got := countSyllables(tt.word); got != tt.want {
+			t.Errorf("countSyllables(%q) = %d, want %d", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	got := Terms("[Куплет 1]\nЯ и ты — звезда, звезда\nа в небе звезда\n")
+	want := map[string]int{"звезда": 3, "небе": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Terms() = %v, want %v", got, want)
+	}
+}
+
+const song = `[Verse 1]
+Теплое место, но улицы ждут
+Отпечатков наших ног
+
+[Chorus]
+Группа крови на рукаве
+Мой порядковый номер на рукаве
+Пожелай мне удачи в бою
+
+[Verse 2]
+И есть чем платить, но я не хочу
+Победы любой ценой
+
+[Chorus]
+Группа крови на рукаве
+Мой порядковый номер на рукаве
+Пожелай мне удачи в бою`
+
+func TestAnalyze(t *testing.T) {
+	a := Analyze([]string{song}, 3)
+
+	if a.Songs != 1 || a.Lines != 10 {
+		t.Errorf("Songs, Lines = %d, %d; want 1, 10", a.Songs, a.Lines)
+	}
+	if a.Tokens != 47 || a.UniqueWords != 30 {
+		t.Errorf("Tokens, UniqueWords = %d, %d; want 47, 30", a.Tokens, a.UniqueWords)
+	}
+	if a.LexicalDiversity != 0.638 {
+		t.Errorf("LexicalDiversity = %v, want 0.638", a.LexicalDiversity)
+	}
+	if a.ReadingSeconds != 14.1 {
+		t.Errorf("ReadingSeconds = %v, want 14.1", a.ReadingSeconds)
+	}
+
+	wantTop := []WordCount{{Word: "рукаве", Count: 4}, {Word: "бою", Count: 2}, {Word: "группа", Count: 2}}
+	if !reflect.DeepEqual(a.TopWords, wantTop) {
+		t.Errorf("TopWords = %v, want %v", a.TopWords, wantTop)
+	}
+
+	wantChoruses := []Chorus{{
+		Lines: []string{
+			"Группа крови на рукаве",
+			"Мой порядковый номер на рукаве",
+			"Пожелай мне удачи в бою",
+		},
+		Occurrences: 2,
+		Label:       "[Chorus]",
+	}}
+	if !reflect.DeepEqual(a.Choruses, wantChoruses) {
+		t.Errorf("Choruses = %+v, want %+v", a.Choruses, wantChoruses)
+	}
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	a := Analyze(nil, 10)
+	if a.TopWords == nil || a.Choruses == nil {
+		t.Fatal("empty analysis must have empty, not nil, slices for JSON")
+	}
+	if a.LexicalDiversity != 0 || a.Tokens != 0 {
+		t.Fatalf("empty analysis = %+v", a)
+	}
+}
+
+func TestFindChorusesSkipsCoveredRepeats(t *testing.T) {
+	text := "a b\nc d\ne f\nx\na b\nc d\ne f\ny\na b\nc d\n"
+	got := findChoruses(splitLines(text))
+	if len(got) != 1 {
+		t.Fatalf("findChoruses() = %+v, want a single chorus", got)
+	}
+	if len(got[0].Lines) != 3 || got[0].Occurrences != 2 {
+		t.Fatalf("chorus = %+v, want 3 lines repeated twice", got[0])
+	}
+}
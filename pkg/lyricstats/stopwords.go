@@ -0,0 +1,28 @@
+package lyricstats
+
+// stopWords — служебные слова русского и английского языков, которые не показываются в топе слов
+var stopWords = toSet(
+	// Русский
+	"а", "без", "бы", "был", "была", "были", "было", "быть", "в", "вам", "вас", "весь", "во", "вот", "все", "всего",
+	"всех", "вы", "где", "да", "даже", "для", "до", "его", "ее", "ей", "ему", "если", "есть", "еще", "же", "за",
+	"здесь", "и", "из", "или", "им", "их", "к", "как", "ко", "когда", "кто", "ли", "либо", "мне", "меня", "мы",
+	"мой", "моя", "мое", "мои", "на", "над", "нам", "нас", "не", "него", "нее", "нет", "ни", "них", "но", "ну",
+	"о", "об", "он", "она", "они", "оно", "от", "по", "под", "при", "с", "со", "так", "там", "те", "тебе", "тебя",
+	"то", "того", "тоже", "той", "только", "том", "ты", "у", "уже", "чем", "что", "чтобы", "эта", "эти", "это",
+	"этот", "я", "твой", "твоя", "твои", "свой", "своя", "себя", "сам", "лишь", "ведь", "вдруг", "будто", "пусть",
+	// Английский
+	"a", "about", "all", "am", "an", "and", "are", "as", "at", "be", "been", "but", "by", "can", "do", "don't",
+	"for", "from", "get", "got", "had", "has", "have", "he", "her", "him", "his", "how", "i", "i'm", "if", "in",
+	"into", "is", "it", "it's", "just", "me", "my", "no", "not", "now", "of", "oh", "on", "or", "our", "out", "so",
+	"she", "that", "the", "their", "them", "then", "there", "they", "this", "to", "up", "us", "was", "we", "were",
+	"what", "when", "where", "who", "will", "with", "you", "you're", "your", "yeah", "ya", "gonna", "wanna",
+	"i'll", "can't", "won't", "ain't",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
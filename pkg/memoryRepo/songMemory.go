@@ -0,0 +1,296 @@
+package memoryrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNegativePage повторяет ошибку Postgres на отрицательный LIMIT или OFFSET
+var errNegativePage = errors.New("limit and offset must not be negative")
+
+// songRecord — песня и время ее добавления, которое Postgres не отдает в models.Song, но использует для сортировки
+type songRecord struct {
+	song      models.Song
+	createdAt time.Time
+}
+
+// songRepository хранит песни в памяти процесса и повторяет поведение songRepository из postgresRepo.
+// Тегов и оценок в нем нет, поэтому песни всегда без тегов и без средней оценки
+type songRepository struct {
+	mu     sync.RWMutex
+	lastID int
+	songs  []*songRecord // по возрастанию id
+}
+
+func NewSongRepository() postgresrepo.SongRepository {
+	return &songRepository{}
+}
+
+func (r *songRepository) AddSong(ctx context.Context, song postgresrepo.AddSongParams) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	r.songs = append(r.songs, &songRecord{
+		song: models.Song{
+			ID:          r.lastID,
+			GroupName:   song.GroupName,
+			SongName:    song.SongName,
+			ReleaseDate: song.ReleaseDate,
+			Album:       song.Album,
+			Text:        song.Text,
+			Link:        song.Link,
+		},
+		createdAt: time.Now(),
+	})
+	return r.lastID, nil
+}
+
+// find возвращает позицию песни в r.songs; вызывается под блокировкой
+func (r *songRepository) find(songID int) (int, bool) {
+	i := sort.Search(len(r.songs), func(i int) bool { return r.songs[i].song.ID >= songID })
+	return i, i < len(r.songs) && r.songs[i].song.ID == songID
+}
+
+func (r *songRepository) GetSongText(ctx context.Context, songID int) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i, ok := r.find(songID)
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return r.songs[i].song.Text, nil
+}
+
+func (r *songRepository) GetArtistTexts(ctx context.Context, groupName string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var texts []string
+	for _, rec := range r.songs {
+		if strings.EqualFold(rec.song.GroupName, groupName) {
+			texts = append(texts, rec.song.Text)
+		}
+	}
+	return texts, nil
+}
+
+func (r *songRepository) GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
+	less, err := songLess(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 0 || offset < 0 {
+		return nil, errNegativePage
+	}
+	match := songMatcher(filter)
+
+	r.mu.RLock()
+	var found []*songRecord
+	for _, rec := range r.songs {
+		if match(rec) {
+			found = append(found, rec)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.SliceStable(found, func(i, j int) bool { return less(found[i], found[j]) })
+	return page(found, limit, offset), nil
+}
+
+func (r *songRepository) GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error) {
+	if limit < 0 || offset < 0 {
+		return nil, errNegativePage
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	songs := make(map[string][]models.Song, len(names))
+	for _, name := range names {
+		var found []*songRecord
+		for _, rec := range r.songs {
+			if strings.EqualFold(rec.song.GroupName, name) {
+				found = append(found, rec)
+			}
+		}
+		if list := page(found, limit, offset); len(list) > 0 {
+			songs[name] = list
+		}
+	}
+	return songs, nil
+}
+
+func (r *songRepository) DeleteSong(ctx context.Context, songID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(int(songID))
+	if !ok {
+		return sql.ErrNoRows
+	}
+	r.songs = append(r.songs[:i], r.songs[i+1:]...)
+	return nil
+}
+
+func (r *songRepository) UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(updSong.ID)
+	if !ok {
+		return sql.ErrNoRows
+	}
+	// Записи не меняются на месте: песни, уже отданные читателям, остаются прежними
+	rec := *r.songs[i]
+	rec.song.GroupName = updSong.GroupName
+	rec.song.SongName = updSong.SongName
+	rec.song.Text = updSong.Text
+	rec.song.ReleaseDate = updSong.ReleaseDate
+	r.songs[i] = &rec
+	return nil
+}
+
+// page отрезает страницу limit/offset и копирует песни
+func page(found []*songRecord, limit, offset int) []models.Song {
+	if offset >= len(found) {
+		return nil
+	}
+	found = found[offset:]
+	if limit < len(found) {
+		found = found[:limit]
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	songs := make([]models.Song, len(found))
+	for i, rec := range found {
+		songs[i] = rec.song
+		songs[i].Tags = []string{}
+	}
+	return songs
+}
+
+// songMatcher повторяет songFilterWhere: group и song сравниваются как ILIKE '%...%'.
+// Тегов у песен нет, поэтому tags_all и tags_any не пропускают ни одной песни, а tags_none — любую
+func songMatcher(f models.SongFilter) func(*songRecord) bool {
+	group := ilike("%" + f.Group + "%")
+	var song *regexp.Regexp
+	if f.Song != "" {
+		song = ilike("%" + f.Song + "%")
+	}
+	var ids map[int]bool
+	if len(f.IDs) > 0 {
+		ids = make(map[int]bool, len(f.IDs))
+		for _, id := range f.IDs {
+			ids[id] = true
+		}
+	}
+	noMatch := len(f.TagsAll) > 0 || len(f.TagsAny) > 0
+
+	return func(rec *songRecord) bool {
+		switch {
+		case noMatch,
+			!group.MatchString(rec.song.GroupName),
+			song != nil && !song.MatchString(rec.song.SongName),
+			ids != nil && !ids[rec.song.ID],
+			rec.song.ID <= f.AfterID:
+			return false
+		}
+		return true
+	}
+}
+
+// ilike переводит шаблон LIKE в регулярное выражение без учета регистра:
+// % — любая последовательность, _ — любой символ, \ экранирует следующий символ
+func ilike(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString(`(?is)^`)
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '%':
+			b.WriteString(`.*`)
+		case '_':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String())
+}
+
+// Сравнения песен по полям сортировки; aNull и bNull сообщают, что у песни нет значения (NULL)
+var songSortFields = map[string]func(a, b *songRecord) (cmp int, aNull, bNull bool){
+	"group": func(a, b *songRecord) (int, bool, bool) {
+		return strings.Compare(strings.ToLower(a.song.GroupName), strings.ToLower(b.song.GroupName)), false, false
+	},
+	"song": func(a, b *songRecord) (int, bool, bool) {
+		return strings.Compare(strings.ToLower(a.song.SongName), strings.ToLower(b.song.SongName)), false, false
+	},
+	"release_date": func(a, b *songRecord) (int, bool, bool) {
+		return strings.Compare(a.song.ReleaseDate, b.song.ReleaseDate), false, false
+	},
+	"created_at": func(a, b *songRecord) (int, bool, bool) {
+		return a.createdAt.Compare(b.createdAt), false, false
+	},
+	"rating": func(a, b *songRecord) (int, bool, bool) {
+		if a.song.RatingAvg == nil || b.song.RatingAvg == nil {
+			return 0, a.song.RatingAvg == nil, b.song.RatingAvg == nil
+		}
+		switch {
+		case *a.song.RatingAvg < *b.song.RatingAvg:
+			return -1, false, false
+		case *a.song.RatingAvg > *b.song.RatingAvg:
+			return 1, false, false
+		}
+		return 0, false, false
+	},
+	"rating_count": func(a, b *songRecord) (int, bool, bool) {
+		return a.song.RatingCount - b.song.RatingCount, false, false
+	},
+}
+
+// songLess повторяет songOrderBy: «-» в начале — по убыванию, значения NULL в конце, при равенстве — по id
+func songLess(sortField string) (func(a, b *songRecord) bool, error) {
+	if sortField == "" {
+		sortField = "id"
+	}
+	field, desc := strings.TrimPrefix(sortField, "-"), strings.HasPrefix(sortField, "-")
+
+	if field == "id" {
+		return func(a, b *songRecord) bool {
+			return (a.song.ID < b.song.ID) != desc
+		}, nil
+	}
+	compare, ok := songSortFields[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", postgresrepo.ErrInvalidSort, sortField)
+	}
+	return func(a, b *songRecord) bool {
+		cmp, aNull, bNull := compare(a, b)
+		switch {
+		case aNull != bNull:
+			return bNull
+		case cmp != 0:
+			return (cmp < 0) != desc
+		}
+		return a.song.ID < b.song.ID
+	}, nil
+}
@@ -0,0 +1,10 @@
+package memoryrepo
+
+import (
+	"musPlayer/pkg/repotest"
+	"testing"
+)
+
+func TestSongRepository(t *testing.T) {
+	repotest.RunSongRepositoryChecks(t, NewSongRepository())
+}
@@ -0,0 +1,334 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"musPlayer/models"
+	"regexp"
+	"time"
+)
+
+// ErrNotSupported возвращается хранилищами, в которых есть только каталог песен (DB_DRIVER=sqlite или memory)
+var ErrNotSupported = errors.New("not supported by the configured database driver")
+
+// NewCatalogRepository собирает репозиторий вокруг хранилища песен без Postgres.
+// Версии текста сводятся к оригиналу из songs, как его поддерживает триггер в Postgres. Остальные данные
+// (теги, библиотеки, прослушивания, вебхуки, события) такому хранилищу недоступны: их методы возвращают ErrNotSupported.
+// Ping и CheckMigrations относятся только к Postgres
+func NewCatalogRepository(songs SongRepository) *Repository {
+	return &Repository{
+		SongRepository:          songs,
+		ProviderCacheRepository: unsupportedRepository{},
+		SyncRepository:          unsupportedRepository{},
+		LyricsRepository:        catalogLyrics{songs: songs},
+		SyncedLyricsRepository:  unsupportedRepository{},
+		SimilarityRepository:    unsupportedRepository{},
+		TagRepository:           unsupportedRepository{},
+		LibraryRepository:       unsupportedRepository{},
+		ListenRepository:        unsupportedRepository{},
+		WebhookRepository:       unsupportedRepository{},
+		SongEventRepository:     unsupportedRepository{},
+		ChangeFeedRepository:    unsupportedRepository{},
+	}
+}
+
+// catalogLyrics отдает единственную версию текста — оригинал, построенный из текста песни.
+// Идентификатор оригинала совпадает с идентификатором песни
+type catalogLyrics struct {
+	songs SongRepository
+}
+
+func (l catalogLyrics) ListLyricsVersions(ctx context.Context, songID int) ([]models.LyricsVersion, error) {
+	versions, err := l.ListLyricsVersionsBySongs(ctx, []int{songID})
+	if err != nil {
+		return nil, err
+	}
+	return versions[songID], nil
+}
+
+func (l catalogLyrics) ListLyricsVersionsBySongs(ctx context.Context, songIDs []int) (map[int][]models.LyricsVersion, error) {
+	versions := make(map[int][]models.LyricsVersion, len(songIDs))
+	if len(songIDs) == 0 {
+		return versions, nil
+	}
+	songs, err := l.songs.GetSongs(ctx, models.SongFilter{IDs: songIDs}, len(songIDs), 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, song := range songs {
+		if v, ok := originalLyrics(song); ok {
+			versions[song.ID] = []models.LyricsVersion{v}
+		}
+	}
+	return versions, nil
+}
+
+func (l catalogLyrics) GetLyricsVersion(ctx context.Context, songID, versionID int) (models.LyricsVersion, error) {
+	versions, err := l.ListLyricsVersions(ctx, songID)
+	if err != nil {
+		return models.LyricsVersion{}, err
+	}
+	for _, v := range versions {
+		if v.ID == versionID {
+			return v, nil
+		}
+	}
+	return models.LyricsVersion{}, sql.ErrNoRows
+}
+
+// SaveLyricsVersion не хранит сгенерированные транслитерации: они строятся заново при каждом запросе
+func (l catalogLyrics) SaveLyricsVersion(ctx context.Context, v models.LyricsVersion) (int, error) {
+	if v.Source == models.LyricsSourceTranslit {
+		return 0, nil
+	}
+	return 0, ErrNotSupported
+}
+
+func (l catalogLyrics) SetDefaultLyricsVersion(ctx context.Context, songID, versionID int) error {
+	return ErrNotSupported
+}
+
+func (l catalogLyrics) DeleteLyricsVersion(ctx context.Context, songID, versionID int) error {
+	return ErrNotSupported
+}
+
+var (
+	ukrainianLetters = regexp.MustCompile(`[іїєґІЇЄҐ]`)
+	russianLetters   = regexp.MustCompile(`[а-яёА-ЯЁ]`)
+)
+
+// originalLyrics повторяет триггер sync_original_lyrics: язык определяется по буквам текста
+func originalLyrics(song models.Song) (models.LyricsVersion, bool) {
+	if song.Text == "" {
+		return models.LyricsVersion{}, false
+	}
+	v := models.LyricsVersion{
+		ID:        song.ID,
+		SongID:    song.ID,
+		Lang:      "en",
+		Kind:      models.LyricsOriginal,
+		Text:      song.Text,
+		Source:    models.LyricsSourceGenius,
+		SourceURL: song.Link,
+		IsDefault: true,
+		CreatedAt: song.CreatedAt,
+		UpdatedAt: song.UpdatedAt,
+	}
+	switch {
+	case ukrainianLetters.MatchString(song.Text):
+		v.Lang = "uk"
+	case russianLetters.MatchString(song.Text):
+		v.Lang = "ru"
+	}
+	if song.Link == "" {
+		v.Source = models.LyricsSourceManual
+	}
+	return v, true
+}
+
+// unsupportedRepository заменяет репозитории, которым нужен Postgres
+type unsupportedRepository struct{}
+
+// ProviderCacheRepository: кеш провайдеров
+func (unsupportedRepository) GetCacheEntry(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, ErrNotSupported
+}
+func (unsupportedRepository) SetCacheEntry(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) PurgeCacheEntries(ctx context.Context, prefix string) (int64, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) DeleteExpiredCacheEntries(ctx context.Context) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// SyncRepository: синхронизация с Genius
+func (unsupportedRepository) GetSongSyncState(ctx context.Context, songID int) (models.SongSyncState, error) {
+	return models.SongSyncState{}, ErrNotSupported
+}
+func (unsupportedRepository) ListSongsForSync(ctx context.Context, before time.Time, limit int) ([]models.SongSyncState, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) ApplySongSync(ctx context.Context, upd models.SongSyncUpdate) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) MarkSongSync(ctx context.Context, songID int, status, syncErr string) error {
+	return ErrNotSupported
+}
+
+// SyncedLyricsRepository: синхронизированные тексты
+func (unsupportedRepository) SaveSyncedLyrics(ctx context.Context, l models.SyncedLyrics) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) GetSyncedLyrics(ctx context.Context, songID int) (models.SyncedLyrics, error) {
+	return models.SyncedLyrics{}, ErrNotSupported
+}
+func (unsupportedRepository) GetSyncedLinesAt(ctx context.Context, songID, atMs int) (current, next *models.SyncedLine, offsetMs int, err error) {
+	return nil, nil, 0, ErrNotSupported
+}
+func (unsupportedRepository) ShiftSyncedLyrics(ctx context.Context, songID, shiftMs int) (int, error) {
+	return 0, ErrNotSupported
+}
+
+// SimilarityRepository: индекс похожих песен
+func (unsupportedRepository) SaveTermVector(ctx context.Context, v models.TermVector) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) LoadTermVectors(ctx context.Context) ([]models.TermVector, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) ListSongsToIndex(ctx context.Context, limit int) ([]models.Song, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) GetSongForIndex(ctx context.Context, songID int) (models.Song, error) {
+	return models.Song{}, ErrNotSupported
+}
+func (unsupportedRepository) ListIndexedSongIDs(ctx context.Context) ([]int, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) GetSongsByIDs(ctx context.Context, ids []int) ([]models.Song, error) {
+	return nil, ErrNotSupported
+}
+
+// TagRepository: теги
+func (unsupportedRepository) ListTags(ctx context.Context, namespace string) ([]models.Tag, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) GetTag(ctx context.Context, tagID int) (models.Tag, error) {
+	return models.Tag{}, ErrNotSupported
+}
+func (unsupportedRepository) CreateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+	return models.Tag{}, ErrNotSupported
+}
+func (unsupportedRepository) UpdateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+	return models.Tag{}, ErrNotSupported
+}
+func (unsupportedRepository) DeleteTag(ctx context.Context, tagID int) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) AssignTags(ctx context.Context, tags []models.Tag, songs models.SongSelection) (int64, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) UnassignTags(ctx context.Context, tags []string, songs models.SongSelection) (int64, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) TagFacets(ctx context.Context, filter models.SongFilter, namespace string) ([]models.TagCount, error) {
+	return nil, ErrNotSupported
+}
+
+// LibraryRepository: личные библиотеки
+func (unsupportedRepository) ListLibrary(ctx context.Context, userID string, filter models.SongFilter, favoritesOnly bool, limit, offset int) ([]models.LibrarySong, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) AddToLibrary(ctx context.Context, userID string, songID int, note *string) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) RemoveFromLibrary(ctx context.Context, userID string, songID int) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) SetFavorite(ctx context.Context, userID string, songID int, favorite bool) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) SetRating(ctx context.Context, userID string, songID int, rating *int) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) GetRating(ctx context.Context, userID string, songID int) (models.SongRating, error) {
+	return models.SongRating{}, ErrNotSupported
+}
+
+// ListenRepository: прослушивания
+func (unsupportedRepository) SaveListens(ctx context.Context, userID string, listens []models.Listen) (inserted, matched int, err error) {
+	return 0, 0, ErrNotSupported
+}
+func (unsupportedRepository) ListListens(ctx context.Context, userID string, after, before time.Time, limit int, ascending bool) ([]models.Listen, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) SetPlayingNow(ctx context.Context, userID string, l models.Listen, ttl time.Duration) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) GetPlayingNow(ctx context.Context, userID string) (models.PlayingNow, error) {
+	return models.PlayingNow{}, ErrNotSupported
+}
+func (unsupportedRepository) SongPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.SongPlayCount, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) ArtistPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.ArtistPlayCount, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) ListenStreaks(ctx context.Context, userID, timeZone string) ([]models.ListenStreak, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) ListIngestQueue(ctx context.Context, status string, limit, offset int) ([]models.IngestQueueItem, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) NextIngestBatch(ctx context.Context, limit, maxAttempts int) ([]models.IngestQueueItem, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) FindSongByArtistTitle(ctx context.Context, artist, title string) (int, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) FindSongByLink(ctx context.Context, link string) (int, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) ResolveIngest(ctx context.Context, queueID, songID int) (int64, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) MarkIngest(ctx context.Context, queueID int, status, errMsg string, retryAfter time.Duration) error {
+	return ErrNotSupported
+}
+
+// WebhookRepository: вебхуки
+func (unsupportedRepository) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) GetWebhook(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, ErrNotSupported
+}
+func (unsupportedRepository) CreateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, ErrNotSupported
+}
+func (unsupportedRepository) UpdateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, ErrNotSupported
+}
+func (unsupportedRepository) DeleteWebhook(ctx context.Context, id int) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) Redeliver(ctx context.Context, deliveryID int64) (models.WebhookDelivery, error) {
+	return models.WebhookDelivery{}, ErrNotSupported
+}
+func (unsupportedRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) RecordAttempt(ctx context.Context, a models.DeliveryAttempt) error {
+	return ErrNotSupported
+}
+func (unsupportedRepository) PurgeEvents(ctx context.Context, before time.Time) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// SongEventRepository: поток событий
+func (unsupportedRepository) SongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) SongEventsByIDs(ctx context.Context, ids []int64) ([]models.SongEvent, error) {
+	return nil, ErrNotSupported
+}
+func (unsupportedRepository) LastSongEventID(ctx context.Context) (int64, error) {
+	return 0, ErrNotSupported
+}
+func (unsupportedRepository) ListenSongEvents(ctx context.Context, onEvents func(ids []int64), onGap func()) error {
+	return ErrNotSupported
+}
+
+// ChangeFeedRepository: лента изменений
+func (unsupportedRepository) ListSongChanges(ctx context.Context, since int64, limit int) (models.ChangeFeedPage, error) {
+	return models.ChangeFeedPage{}, ErrNotSupported
+}
+func (unsupportedRepository) PurgeTombstones(ctx context.Context, before time.Time) (int64, error) {
+	return 0, ErrNotSupported
+}
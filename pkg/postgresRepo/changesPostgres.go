@@ -0,0 +1,125 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"musPlayer/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type changeFeedRepository struct {
+	db Querier
+}
+
+func NewChangeFeedRepository(db Querier) ChangeFeedRepository {
+	return &changeFeedRepository{
+		db: db,
+	}
+}
+
+// Изменения с номером больше since по возрастанию номера. Номера и песни читаются из одного снимка,
+// поэтому upsert всегда содержит песню в состоянии на момент своего номера или позже
+func (r *changeFeedRepository) ListSongChanges(ctx context.Context, since int64, limit int) (models.ChangeFeedPage, error) {
+	page := models.ChangeFeedPage{Cursor: since}
+
+	tx, err := begin(ctx, r.db, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return page, err
+	}
+	defer tx.Rollback()
+
+	var lastSeq, purgedSeq int64
+	if err := tx.QueryRowContext(ctx, `SELECT last_seq, purged_seq FROM change_feed_state`).Scan(&lastSeq, &purgedSeq); err != nil {
+		return page, err
+	}
+	// Нулевой курсор — первая синхронизация, надгробия ей не нужны
+	if since > 0 && (since < purgedSeq || since > lastSeq) {
+		page.ResyncRequired = true
+		page.Cursor = lastSeq
+		return page, nil
+	}
+
+	query := `SELECT seq, song_id, deleted, changed_at FROM song_changes WHERE seq > $1 ORDER BY seq LIMIT $2`
+	rows, err := tx.QueryContext(ctx, query, since, limit+1)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var upserts []int
+	for rows.Next() {
+		var c models.SongChange
+		var deleted bool
+		if err := rows.Scan(&c.Seq, &c.SongID, &deleted, &c.ChangedAt); err != nil {
+			return page, err
+		}
+		c.Op = models.ChangeOpUpsert
+		if deleted {
+			c.Op = models.ChangeOpDelete
+		} else {
+			upserts = append(upserts, c.SongID)
+		}
+		page.Changes = append(page.Changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Changes) > limit {
+		page.Changes = page.Changes[:limit]
+		page.HasMore = true
+	}
+	if len(page.Changes) > 0 {
+		page.Cursor = page.Changes[len(page.Changes)-1].Seq
+	}
+
+	songs, err := selectSongsTx(ctx, tx, upserts)
+	if err != nil {
+		return page, err
+	}
+	for i := range page.Changes {
+		if song, ok := songs[page.Changes[i].SongID]; ok && page.Changes[i].Op == models.ChangeOpUpsert {
+			page.Changes[i].Song = &song
+		}
+	}
+
+	return page, tx.Commit()
+}
+
+func selectSongsTx(ctx context.Context, tx Querier, ids []int) (map[int]models.Song, error) {
+	songs := make(map[int]models.Song, len(ids))
+	if len(ids) == 0 {
+		return songs, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+songColumns+` FROM songs WHERE songs.id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song models.Song
+		if err := scanSong(rows, &song); err != nil {
+			return nil, err
+		}
+		songs[song.ID] = song
+	}
+
+	return songs, rows.Err()
+}
+
+// Удаление надгробий старше before. Наибольший удаленный номер запоминается, чтобы отличать устаревшие курсоры
+func (r *changeFeedRepository) PurgeTombstones(ctx context.Context, before time.Time) (int64, error) {
+	query := `WITH purged AS (
+                  DELETE FROM song_changes WHERE deleted AND changed_at < $1 RETURNING seq
+              )
+              UPDATE change_feed_state SET purged_seq = GREATEST(purged_seq, (SELECT MAX(seq) FROM purged))
+              RETURNING (SELECT COUNT(*) FROM purged)`
+
+	var n int64
+	err := r.db.QueryRowContext(ctx, query, before).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,107 @@
+package postgresrepo_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"testing"
+	"time"
+)
+
+func TestListSongChanges(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		songs := postgresrepo.NewSongRepository(db)
+		feed := postgresrepo.NewChangeFeedRepository(db)
+
+		// Курсор из будущего (например, после восстановления базы из резервной копии) требует полной синхронизации
+		page, err := feed.ListSongChanges(ctx, math.MaxInt64, 10)
+		if err != nil {
+			t.Fatalf("ListSongChanges: %v", err)
+		}
+		if !page.ResyncRequired || len(page.Changes) != 0 || page.Cursor < 0 || page.Cursor == math.MaxInt64 {
+			t.Fatalf("cursor after last_seq: page = %+v, want resync_required with the current cursor", page)
+		}
+		head := page.Cursor
+
+		name := fmt.Sprintf("changes-%d", time.Now().UnixNano())
+		id, err := songs.AddSong(ctx, postgresrepo.AddSongParams{GroupName: name, SongName: "Kukushka", Text: "Pesen eshche nenapisannykh"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { songs.DeleteSong(ctx, int64(id)) })
+
+		page, err = feed.ListSongChanges(ctx, head, 10)
+		if err != nil {
+			t.Fatalf("ListSongChanges: %v", err)
+		}
+		if page.ResyncRequired || page.HasMore || len(page.Changes) != 1 {
+			t.Fatalf("after add: page = %+v, want one change", page)
+		}
+		added := page.Changes[0]
+		if added.Op != models.ChangeOpUpsert || added.SongID != id || added.Song == nil || added.Song.GroupName != name ||
+			added.Seq <= head || page.Cursor != added.Seq {
+			t.Fatalf("after add: change = %+v, cursor %d, want upsert of song %d", added, page.Cursor, id)
+		}
+
+		// Пересчет средней оценки не попадает в ленту
+		if _, err := db.ExecContext(ctx, `UPDATE songs SET rating_avg = 4.5, rating_count = 2 WHERE id = $1`, id); err != nil {
+			t.Fatal(err)
+		}
+		page, err = feed.ListSongChanges(ctx, added.Seq, 10)
+		if err != nil {
+			t.Fatalf("ListSongChanges: %v", err)
+		}
+		if len(page.Changes) != 0 || page.Cursor != added.Seq {
+			t.Fatalf("after rating update: page = %+v, want no changes", page)
+		}
+
+		if err := songs.DeleteSong(ctx, int64(id)); err != nil {
+			t.Fatal(err)
+		}
+		page, err = feed.ListSongChanges(ctx, head, 10)
+		if err != nil {
+			t.Fatalf("ListSongChanges: %v", err)
+		}
+		// Надгробие заменяет upsert той же песни
+		if page.ResyncRequired || len(page.Changes) != 1 {
+			t.Fatalf("after delete: page = %+v, want one change", page)
+		}
+		deleted := page.Changes[0]
+		if deleted.Op != models.ChangeOpDelete || deleted.SongID != id || deleted.Song != nil || deleted.Seq <= added.Seq {
+			t.Fatalf("after delete: change = %+v, want tombstone of song %d", deleted, id)
+		}
+
+		// Запас в сутки на случай, если часовой пояс базы отличается от часового пояса теста
+		purged, err := feed.PurgeTombstones(ctx, time.Now().Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("PurgeTombstones: %v", err)
+		}
+		if purged < 1 {
+			t.Fatalf("PurgeTombstones removed %d tombstones, want at least 1", purged)
+		}
+
+		// Клиент с курсором до удаленного надгробия не узнает об удалении и должен синхронизироваться заново
+		page, err = feed.ListSongChanges(ctx, added.Seq, 10)
+		if err != nil {
+			t.Fatalf("ListSongChanges: %v", err)
+		}
+		if !page.ResyncRequired || len(page.Changes) != 0 || page.Cursor < deleted.Seq {
+			t.Fatalf("cursor before purged tombstone: page = %+v, want resync_required with cursor >= %d", page, deleted.Seq)
+		}
+
+		// Курсор, который уже видел надгробие, и первая синхронизация продолжают работать
+		for _, since := range []int64{deleted.Seq, 0} {
+			page, err = feed.ListSongChanges(ctx, since, 1)
+			if err != nil {
+				t.Fatalf("ListSongChanges(%d): %v", since, err)
+			}
+			if page.ResyncRequired {
+				t.Fatalf("ListSongChanges(%d) requires resync after purge", since)
+			}
+		}
+	})
+}
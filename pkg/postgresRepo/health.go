@@ -0,0 +1,46 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion — версия последней миграции из schema/migrations, которую ожидает код
+const SchemaVersion = 13
+
+// Ping проверяет соединение с базой данных
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion возвращает версию схемы из таблицы schema_migrations, которую ведет golang-migrate
+func (r *Repository) MigrationVersion(ctx context.Context) (version int, dirty bool, err error) {
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+
+	err = r.db.QueryRowContext(ctx, query).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, fmt.Errorf("no migrations applied")
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// CheckMigrations проверяет, что схема применена полностью и не находится в состоянии dirty
+func (r *Repository) CheckMigrations(ctx context.Context) error {
+	version, dirty, err := r.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < SchemaVersion {
+		return fmt.Errorf("schema version %d is older than required %d", version, SchemaVersion)
+	}
+	return nil
+}
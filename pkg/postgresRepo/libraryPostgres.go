@@ -0,0 +1,146 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"musPlayer/models"
+)
+
+type libraryRepository struct {
+	db Querier
+}
+
+func NewLibraryRepository(db Querier) LibraryRepository {
+	return &libraryRepository{
+		db: db,
+	}
+}
+
+// Поля сортировки, доступные только в библиотеке
+var librarySortColumns = map[string]string{
+	"added_at":  "us.added_at",
+	"my_rating": "us.rating",
+}
+
+// Песни библиотеки пользователя с тем же фильтром, что и общий список
+func (r *libraryRepository) ListLibrary(ctx context.Context, userID string, filter models.SongFilter, favoritesOnly bool, limit, offset int) ([]models.LibrarySong, error) {
+	orderBy, err := songOrderBy(filter.Sort, librarySortColumns)
+	if err != nil {
+		return nil, err
+	}
+	where, args := songFilterWhere(filter, []interface{}{userID, favoritesOnly})
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`SELECT %s, us.favorite, us.rating, us.note, us.added_at
+              FROM songs JOIN user_songs us ON us.song_id = songs.id AND us.user_id = $1
+              WHERE us.in_library AND (NOT $2 OR us.favorite) AND %s
+              ORDER BY %s LIMIT $%d OFFSET $%d`, songColumns, where, orderBy, len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.LibrarySong
+	for rows.Next() {
+		var s models.LibrarySong
+		if err := scanSong(rows, &s.Song, &s.Favorite, &s.MyRating, &s.Note, &s.AddedAt); err != nil {
+			return nil, err
+		}
+		songs = append(songs, s)
+	}
+
+	return songs, rows.Err()
+}
+
+// Добавление песни в библиотеку; nil-заметка сохраняет прежнюю
+func (r *libraryRepository) AddToLibrary(ctx context.Context, userID string, songID int, note *string) error {
+	query := `INSERT INTO user_songs (user_id, song_id, in_library, note, added_at) VALUES ($1, $2, TRUE, COALESCE($3, ''), NOW())
+              ON CONFLICT (user_id, song_id) DO UPDATE
+                  SET in_library = TRUE,
+                      added_at = CASE WHEN user_songs.in_library THEN user_songs.added_at ELSE NOW() END,
+                      note = COALESCE($3, user_songs.note),
+                      updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query, userID, songID, note)
+	return mapForeignKeyError(err)
+}
+
+// Удаление песни из библиотеки вместе с отметкой избранного и заметкой; оценка сохраняется
+func (r *libraryRepository) RemoveFromLibrary(ctx context.Context, userID string, songID int) error {
+	query := `UPDATE user_songs SET in_library = FALSE, favorite = FALSE, favorited_at = NULL, note = '', added_at = NULL, updated_at = NOW()
+              WHERE user_id = $1 AND song_id = $2 AND in_library`
+
+	result, err := r.db.ExecContext(ctx, query, userID, songID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Отметка избранного. Избранная песня всегда находится в библиотеке
+func (r *libraryRepository) SetFavorite(ctx context.Context, userID string, songID int, favorite bool) error {
+	if !favorite {
+		_, err := r.db.ExecContext(ctx, `UPDATE user_songs SET favorite = FALSE, favorited_at = NULL, updated_at = NOW()
+              WHERE user_id = $1 AND song_id = $2 AND favorite`, userID, songID)
+		return err
+	}
+
+	query := `INSERT INTO user_songs (user_id, song_id, in_library, favorite, added_at, favorited_at) VALUES ($1, $2, TRUE, TRUE, NOW(), NOW())
+              ON CONFLICT (user_id, song_id) DO UPDATE
+                  SET added_at = CASE WHEN user_songs.in_library THEN user_songs.added_at ELSE NOW() END,
+                      favorited_at = CASE WHEN user_songs.favorite THEN user_songs.favorited_at ELSE NOW() END,
+                      in_library = TRUE, favorite = TRUE, updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query, userID, songID)
+	return mapForeignKeyError(err)
+}
+
+// Оценка песни пользователем; nil снимает оценку. Средняя оценка песни пересчитывается триггером
+func (r *libraryRepository) SetRating(ctx context.Context, userID string, songID int, rating *int) error {
+	if rating == nil {
+		_, err := r.db.ExecContext(ctx, `UPDATE user_songs SET rating = NULL, rated_at = NULL, updated_at = NOW()
+              WHERE user_id = $1 AND song_id = $2 AND rating IS NOT NULL`, userID, songID)
+		return err
+	}
+
+	query := `INSERT INTO user_songs (user_id, song_id, rating, rated_at) VALUES ($1, $2, $3, NOW())
+              ON CONFLICT (user_id, song_id) DO UPDATE SET rating = EXCLUDED.rating, rated_at = NOW(), updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query, userID, songID, *rating)
+	return mapForeignKeyError(err)
+}
+
+// Средняя оценка песни и оценка пользователя
+func (r *libraryRepository) GetRating(ctx context.Context, userID string, songID int) (models.SongRating, error) {
+	query := `SELECT s.id, s.rating_avg, s.rating_count, us.rating FROM songs s
+              LEFT JOIN user_songs us ON us.song_id = s.id AND us.user_id = $1
+              WHERE s.id = $2`
+
+	var rating models.SongRating
+	err := r.db.QueryRowContext(ctx, query, userID, songID).Scan(&rating.SongID, &rating.RatingAvg, &rating.RatingCount, &rating.MyRating)
+	return rating, err
+}
+
+// mapForeignKeyError переводит нарушение внешнего ключа (песни нет) в sql.ErrNoRows
+func mapForeignKeyError(err error) error {
+	if isForeignKeyViolation(err) {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+func isForeignKeyViolation(err error) bool {
+	return sqlState(err) == "23503"
+}
@@ -0,0 +1,384 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	"sync"
+	"time"
+)
+
+// ErrSongReference возвращается, когда указанной песни нет в каталоге
+var ErrSongReference = errors.New("referenced song does not exist")
+
+type listenRepository struct {
+	db Querier
+	// Месяцы, для которых секция listens уже создана этим процессом
+	partitions sync.Map
+}
+
+func NewListenRepository(db Querier) ListenRepository {
+	return &listenRepository{
+		db: db,
+	}
+}
+
+// matchSongSQL — подзапрос, который находит песню каталога по исполнителю и названию из параметров с номерами artist и track.
+// Если песня была найдена обработчиком очереди под другим названием, используется результат очереди
+func matchSongSQL(artist, track int) string {
+	return fmt.Sprintf(`COALESCE(
+                  (SELECT MIN(id) FROM songs WHERE LOWER(group_name) = LOWER($%[1]d) AND LOWER(song_name) = LOWER($%[2]d)),
+                  (SELECT song_id FROM listen_ingest_queue WHERE artist_key = LOWER($%[1]d) AND track_key = LOWER($%[2]d) AND status = 'matched'))`, artist, track)
+}
+
+// Сохранение прослушиваний. Повторы пропускаются; несопоставленные треки попадают в очередь поиска.
+// Возвращает число сохраненных и сопоставленных прослушиваний
+func (r *listenRepository) SaveListens(ctx context.Context, userID string, listens []models.Listen) (int, int, error) {
+	if err := r.ensurePartitions(ctx, listens); err != nil {
+		return 0, 0, err
+	}
+
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx, `INSERT INTO listens (user_id, listened_at, song_id, artist_name, track_name, release_name,
+                  duration_ms, recording_mbid, submission_client, additional_info)
+              VALUES ($1, $2, `+matchSongSQL(3, 4)+`, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9)
+              ON CONFLICT DO NOTHING
+              RETURNING song_id`)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer insert.Close()
+
+	// Запись в очередь; если найденную ранее песню удалили, трек ищется заново
+	queue, err := tx.PrepareContext(ctx, `INSERT INTO listen_ingest_queue AS q (artist_key, track_key, artist_name, track_name, listen_count)
+              VALUES (LOWER($1), LOWER($2), $1, $2, 1)
+              ON CONFLICT (artist_key, track_key) DO UPDATE
+                  SET listen_count = q.listen_count + 1, last_seen_at = NOW(),
+                      status = CASE WHEN q.status = 'matched' THEN 'pending' ELSE q.status END,
+                      next_attempt_at = CASE WHEN q.status = 'matched' THEN NOW() ELSE q.next_attempt_at END`)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer queue.Close()
+
+	inserted, matched := 0, 0
+	for _, l := range listens {
+		info, err := json.Marshal(l.AdditionalInfo)
+		if err != nil {
+			return 0, 0, err
+		}
+		if l.AdditionalInfo == nil {
+			info = []byte("{}")
+		}
+
+		var songID sql.NullInt64
+		err = insert.QueryRowContext(ctx, userID, l.ListenedAt.UTC(), l.ArtistName, l.TrackName, l.ReleaseName,
+			l.DurationMs, l.RecordingMBID, l.SubmissionClient, info).Scan(&songID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Такое прослушивание уже сохранено
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		inserted++
+
+		if songID.Valid {
+			matched++
+			continue
+		}
+		if _, err := queue.ExecContext(ctx, l.ArtistName, l.TrackName); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return inserted, matched, tx.Commit()
+}
+
+// ensurePartitions создает месячные секции listens для всех прослушиваний пачки
+func (r *listenRepository) ensurePartitions(ctx context.Context, listens []models.Listen) error {
+	for _, l := range listens {
+		t := l.ListenedAt.UTC()
+		month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if _, ok := r.partitions.Load(month); ok {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, `SELECT ensure_listens_partition($1)`, month.Format("2006-01-02")); err != nil {
+			return fmt.Errorf("create listens partition for %s: %w", month.Format("2006-01"), err)
+		}
+		r.partitions.Store(month, true)
+	}
+	return nil
+}
+
+// История прослушиваний пользователя в интервале (after, before). При ascending выбираются самые ранние прослушивания
+func (r *listenRepository) ListListens(ctx context.Context, userID string, after, before time.Time, limit int, ascending bool) ([]models.Listen, error) {
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+	query := `SELECT listened_at, song_id, artist_name, track_name, release_name, duration_ms,
+                     COALESCE(recording_mbid, ''), COALESCE(submission_client, ''), additional_info
+              FROM listens
+              WHERE user_id = $1 AND listened_at > $2 AND listened_at < $3
+              ORDER BY listened_at ` + direction + `
+              LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, after.UTC(), before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var listens []models.Listen
+	for rows.Next() {
+		var l models.Listen
+		var info []byte
+		if err := rows.Scan(&l.ListenedAt, &l.SongID, &l.ArtistName, &l.TrackName, &l.ReleaseName, &l.DurationMs,
+			&l.RecordingMBID, &l.SubmissionClient, &info); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(info, &l.AdditionalInfo); err != nil {
+			return nil, err
+		}
+		listens = append(listens, l)
+	}
+
+	return listens, rows.Err()
+}
+
+// Сохранение трека, который пользователь слушает сейчас
+func (r *listenRepository) SetPlayingNow(ctx context.Context, userID string, l models.Listen, ttl time.Duration) error {
+	info, err := json.Marshal(l.AdditionalInfo)
+	if err != nil {
+		return err
+	}
+	if l.AdditionalInfo == nil {
+		info = []byte("{}")
+	}
+
+	query := `INSERT INTO listens_playing_now (user_id, song_id, artist_name, track_name, release_name, additional_info, started_at, expires_at)
+              VALUES ($1, ` + matchSongSQL(2, 3) + `, $2, $3, $4, $5, NOW(), NOW() + $6 * INTERVAL '1 millisecond')
+              ON CONFLICT (user_id) DO UPDATE
+                  SET song_id = EXCLUDED.song_id, artist_name = EXCLUDED.artist_name, track_name = EXCLUDED.track_name,
+                      release_name = EXCLUDED.release_name, additional_info = EXCLUDED.additional_info,
+                      started_at = EXCLUDED.started_at, expires_at = EXCLUDED.expires_at`
+
+	_, err = r.db.ExecContext(ctx, query, userID, l.ArtistName, l.TrackName, l.ReleaseName, info, ttl.Milliseconds())
+	return err
+}
+
+// Трек, который пользователь слушает сейчас, если время его показа не истекло
+func (r *listenRepository) GetPlayingNow(ctx context.Context, userID string) (models.PlayingNow, error) {
+	query := `SELECT song_id, artist_name, track_name, release_name, additional_info, started_at, expires_at
+              FROM listens_playing_now WHERE user_id = $1 AND expires_at > NOW()`
+
+	var p models.PlayingNow
+	var info []byte
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&p.SongID, &p.ArtistName, &p.TrackName, &p.ReleaseName, &info, &p.ListenedAt, &p.ExpiresAt)
+	if err != nil {
+		return p, err
+	}
+	return p, json.Unmarshal(info, &p.AdditionalInfo)
+}
+
+// Число прослушиваний по трекам за период
+func (r *listenRepository) SongPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.SongPlayCount, error) {
+	query := `SELECT l.song_id, COALESCE(MAX(s.group_name), MAX(l.artist_name)), COALESCE(MAX(s.song_name), MAX(l.track_name)),
+                     COUNT(*), MAX(l.listened_at)
+              FROM listens l LEFT JOIN songs s ON s.id = l.song_id
+              WHERE l.user_id = $1 AND l.listened_at >= $2 AND l.listened_at < $3
+              GROUP BY l.song_id,
+                       CASE WHEN l.song_id IS NULL THEN LOWER(l.artist_name) END,
+                       CASE WHEN l.song_id IS NULL THEN LOWER(l.track_name) END
+              ORDER BY COUNT(*) DESC, MAX(l.listened_at) DESC
+              LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, period.From.UTC(), period.To.UTC(), period.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.SongPlayCount
+	for rows.Next() {
+		var c models.SongPlayCount
+		if err := rows.Scan(&c.SongID, &c.ArtistName, &c.TrackName, &c.Count, &c.LastListenedAt); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// Число прослушиваний по исполнителям за период
+func (r *listenRepository) ArtistPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.ArtistPlayCount, error) {
+	query := `SELECT MAX(COALESCE(s.group_name, l.artist_name)), COUNT(*),
+                     COUNT(DISTINCT COALESCE(l.song_id::TEXT, LOWER(l.track_name)))
+              FROM listens l LEFT JOIN songs s ON s.id = l.song_id
+              WHERE l.user_id = $1 AND l.listened_at >= $2 AND l.listened_at < $3
+              GROUP BY LOWER(COALESCE(s.group_name, l.artist_name))
+              ORDER BY COUNT(*) DESC, 1
+              LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, period.From.UTC(), period.To.UTC(), period.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.ArtistPlayCount
+	for rows.Next() {
+		var c models.ArtistPlayCount
+		if err := rows.Scan(&c.ArtistName, &c.Count, &c.Tracks); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// Серии дней подряд с прослушиваниями в часовом поясе timeZone, от последней к первой
+func (r *listenRepository) ListenStreaks(ctx context.Context, userID, timeZone string) ([]models.ListenStreak, error) {
+	query := `WITH days AS (
+                  SELECT DISTINCT (listened_at AT TIME ZONE 'UTC' AT TIME ZONE $2)::DATE AS day FROM listens WHERE user_id = $1
+              ), islands AS (
+                  SELECT day, day - (ROW_NUMBER() OVER (ORDER BY day))::INT AS island FROM days
+              )
+              SELECT TO_CHAR(MIN(day), 'YYYY-MM-DD'), TO_CHAR(MAX(day), 'YYYY-MM-DD'), COUNT(*)
+              FROM islands GROUP BY island ORDER BY MAX(day) DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, timeZone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var streaks []models.ListenStreak
+	for rows.Next() {
+		var s models.ListenStreak
+		if err := rows.Scan(&s.Start, &s.End, &s.Days); err != nil {
+			return nil, err
+		}
+		streaks = append(streaks, s)
+	}
+
+	return streaks, rows.Err()
+}
+
+const ingestQueueColumns = `id, artist_name, track_name, listen_count, status, attempts, song_id, COALESCE(last_error, ''), first_seen_at, last_seen_at`
+
+// Очередь несопоставленных треков; пустой статус — все записи
+func (r *listenRepository) ListIngestQueue(ctx context.Context, status string, limit, offset int) ([]models.IngestQueueItem, error) {
+	query := `SELECT ` + ingestQueueColumns + ` FROM listen_ingest_queue
+              WHERE $1 = '' OR status = $1
+              ORDER BY listen_count DESC, id
+              LIMIT $2 OFFSET $3`
+
+	return r.queryIngestQueue(ctx, query, status, limit, offset)
+}
+
+// Треки, которые пора искать: новые и неудачные попытки, у которых подошло время повтора. Сначала самые прослушиваемые
+func (r *listenRepository) NextIngestBatch(ctx context.Context, limit, maxAttempts int) ([]models.IngestQueueItem, error) {
+	query := `SELECT ` + ingestQueueColumns + ` FROM listen_ingest_queue
+              WHERE status IN ('pending', 'failed') AND next_attempt_at <= NOW() AND attempts < $2
+              ORDER BY listen_count DESC, id
+              LIMIT $1`
+
+	return r.queryIngestQueue(ctx, query, limit, maxAttempts)
+}
+
+func (r *listenRepository) queryIngestQueue(ctx context.Context, query string, args ...interface{}) ([]models.IngestQueueItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.IngestQueueItem
+	for rows.Next() {
+		var it models.IngestQueueItem
+		if err := rows.Scan(&it.ID, &it.ArtistName, &it.TrackName, &it.ListenCount, &it.Status, &it.Attempts, &it.SongID,
+			&it.LastError, &it.FirstSeenAt, &it.LastSeenAt); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
+
+// Песня каталога по исполнителю и названию без учета регистра
+func (r *listenRepository) FindSongByArtistTitle(ctx context.Context, artist, title string) (int, error) {
+	var id sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `SELECT MIN(id) FROM songs WHERE LOWER(group_name) = LOWER($1) AND LOWER(song_name) = LOWER($2)`, artist, title).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	if !id.Valid {
+		return 0, sql.ErrNoRows
+	}
+	return int(id.Int64), nil
+}
+
+// Песня каталога по ссылке на исходную страницу
+func (r *listenRepository) FindSongByLink(ctx context.Context, link string) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM songs WHERE link = $1 ORDER BY id LIMIT 1`, link).Scan(&id)
+	return id, err
+}
+
+// Привязка трека из очереди к песне: запись очереди помечается найденной, прослушивания трека сопоставляются с песней.
+// Возвращает число сопоставленных прослушиваний
+func (r *listenRepository) ResolveIngest(ctx context.Context, queueID, songID int) (int64, error) {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var artistKey, trackKey string
+	err = tx.QueryRowContext(ctx, `UPDATE listen_ingest_queue
+              SET status = 'matched', song_id = $2, last_error = NULL
+              WHERE id = $1
+              RETURNING artist_key, track_key`, queueID, songID).Scan(&artistKey, &trackKey)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, ErrSongReference
+		}
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE listens SET song_id = $1
+              WHERE song_id IS NULL AND LOWER(artist_name) = $2 AND LOWER(track_name) = $3`, songID, artistKey, trackKey)
+	if err != nil {
+		return 0, err
+	}
+	linked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return linked, tx.Commit()
+}
+
+// Результат неудачного поиска трека. retryAfter > 0 оставляет трек в очереди и планирует повтор
+func (r *listenRepository) MarkIngest(ctx context.Context, queueID int, status, errMsg string, retryAfter time.Duration) error {
+	query := `UPDATE listen_ingest_queue
+              SET status = $2, last_error = NULLIF($3, ''), attempts = attempts + 1,
+                  next_attempt_at = NOW() + $4 * INTERVAL '1 second'
+              WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, queueID, status, errMsg, int64(retryAfter.Seconds()))
+	return err
+}
@@ -0,0 +1,152 @@
+package postgresrepo_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+	"testing"
+	"time"
+)
+
+// findIngestItem ищет в очереди запись трека проверки; статус пустой — любая запись
+func findIngestItem(ctx context.Context, repo postgresrepo.ListenRepository, status, artist string) (models.IngestQueueItem, bool, error) {
+	items, err := repo.ListIngestQueue(ctx, status, 10000, 0)
+	if err != nil {
+		return models.IngestQueueItem{}, false, err
+	}
+	for _, it := range items {
+		if strings.EqualFold(it.ArtistName, artist) {
+			return it, true, nil
+		}
+	}
+	return models.IngestQueueItem{}, false, nil
+}
+
+func TestSaveListens(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *sql.DB) {
+		ctx := context.Background()
+		songs := postgresrepo.NewSongRepository(db)
+		repo := postgresrepo.NewListenRepository(db)
+
+		// Уникальный префикс отделяет данные проверки от уже лежащих в базе. Имена латиницей:
+		// LOWER в базе с локалью C не меняет регистр кириллицы
+		prefix := fmt.Sprintf("listens-%d", time.Now().UnixNano())
+		user := prefix
+		known := prefix + " Kino"
+		unknown := prefix + " Akvarium"
+
+		knownID, err := songs.AddSong(ctx, postgresrepo.AddSongParams{GroupName: known, SongName: "Kukushka", Text: "Pesen eshche nenapisannykh"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		foundID, err := songs.AddSong(ctx, postgresrepo.AddSongParams{GroupName: unknown, SongName: "Gorod zolotoy (Live)", Text: "Nad nebom golubym"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			db.Exec(`DELETE FROM listens WHERE user_id = $1`, user)
+			db.Exec(`DELETE FROM listen_ingest_queue WHERE artist_key LIKE $1`, strings.ToLower(prefix)+"%")
+			songs.DeleteSong(ctx, int64(knownID))
+			songs.DeleteSong(ctx, int64(foundID))
+		})
+
+		t0 := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+		batch := []models.Listen{
+			// Сопоставляется с каталогом без учета регистра
+			{ListenedAt: t0, ArtistName: strings.ToUpper(known), TrackName: "KUKUSHKA"},
+			{ListenedAt: t0, ArtistName: unknown, TrackName: "Gorod zolotoy", AdditionalInfo: map[string]interface{}{"submission_client": "test"}},
+			{ListenedAt: t0.Add(time.Minute), ArtistName: unknown, TrackName: "gorod zolotoy"},
+			// Повтор первого прослушивания в той же пачке
+			{ListenedAt: t0, ArtistName: known, TrackName: "KUKUSHKA"},
+		}
+
+		inserted, matched, err := repo.SaveListens(ctx, user, batch)
+		if err != nil {
+			t.Fatalf("SaveListens: %v", err)
+		}
+		if inserted != 3 || matched != 1 {
+			t.Fatalf("SaveListens = %d inserted, %d matched, want 3, 1", inserted, matched)
+		}
+
+		// Повторная отправка той же пачки ничего не сохраняет и не увеличивает счетчик очереди
+		inserted, matched, err = repo.SaveListens(ctx, user, batch)
+		if err != nil {
+			t.Fatalf("SaveListens again: %v", err)
+		}
+		if inserted != 0 || matched != 0 {
+			t.Fatalf("SaveListens again = %d inserted, %d matched, want 0, 0", inserted, matched)
+		}
+
+		item, ok, err := findIngestItem(ctx, repo, models.IngestStatusPending, unknown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("unmatched track %q is not in the ingest queue", unknown)
+		}
+		if item.ListenCount != 2 || item.SongID != nil || item.TrackName != "Gorod zolotoy" {
+			t.Fatalf("queue item = %+v, want 2 listens of the first spelling without a song", item)
+		}
+		if _, ok, err := findIngestItem(ctx, repo, "", known); err != nil || ok {
+			t.Fatalf("matched track is in the ingest queue (err %v)", err)
+		}
+
+		// Неудачная попытка поиска откладывает трек
+		if err := repo.MarkIngest(ctx, item.ID, models.IngestStatusFailed, "timeout", time.Hour); err != nil {
+			t.Fatalf("MarkIngest: %v", err)
+		}
+		failed, ok, err := findIngestItem(ctx, repo, models.IngestStatusFailed, unknown)
+		if err != nil || !ok || failed.Attempts != 1 || failed.LastError != "timeout" {
+			t.Fatalf("queue item after MarkIngest = %+v, %v, %v, want failed with one attempt", failed, ok, err)
+		}
+		next, err := repo.NextIngestBatch(ctx, 10000, 5)
+		if err != nil {
+			t.Fatalf("NextIngestBatch: %v", err)
+		}
+		for _, it := range next {
+			if it.ID == item.ID {
+				t.Fatal("NextIngestBatch returned a track before its retry time")
+			}
+		}
+
+		// Найденная обработчиком песня привязывается ко всем прослушиваниям трека
+		linked, err := repo.ResolveIngest(ctx, item.ID, foundID)
+		if err != nil {
+			t.Fatalf("ResolveIngest: %v", err)
+		}
+		if linked != 2 {
+			t.Fatalf("ResolveIngest linked %d listens, want 2", linked)
+		}
+
+		// Новые прослушивания трека сопоставляются по результату очереди, хотя название в каталоге другое
+		inserted, matched, err = repo.SaveListens(ctx, user, []models.Listen{
+			{ListenedAt: t0.Add(2 * time.Minute), ArtistName: unknown, TrackName: "Gorod Zolotoy"},
+		})
+		if err != nil {
+			t.Fatalf("SaveListens after resolve: %v", err)
+		}
+		if inserted != 1 || matched != 1 {
+			t.Fatalf("SaveListens after resolve = %d inserted, %d matched, want 1, 1", inserted, matched)
+		}
+
+		listens, err := repo.ListListens(ctx, user, t0.Add(-time.Hour), t0.Add(time.Hour), 100, false)
+		if err != nil {
+			t.Fatalf("ListListens: %v", err)
+		}
+		if len(listens) != 4 {
+			t.Fatalf("ListListens returned %d listens, want 4", len(listens))
+		}
+		for _, l := range listens {
+			want := foundID
+			if strings.EqualFold(l.ArtistName, known) {
+				want = knownID
+			}
+			if l.SongID == nil || *l.SongID != want {
+				t.Fatalf("listen %s %q has song %v, want %d", l.ListenedAt, l.TrackName, l.SongID, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"musPlayer/models"
+
+	"github.com/lib/pq"
+)
+
+type lyricsRepository struct {
+	db Querier
+}
+
+func NewLyricsRepository(db Querier) LyricsRepository {
+	return &lyricsRepository{
+		db: db,
+	}
+}
+
+const lyricsVersionColumns = `id, song_id, lang, kind, variant, text, source, COALESCE(source_url, ''),
+       COALESCE(submitted_by, ''), is_default, created_at, updated_at`
+
+func scanLyricsVersion(row interface{ Scan(...interface{}) error }) (models.LyricsVersion, error) {
+	var v models.LyricsVersion
+	err := row.Scan(&v.ID, &v.SongID, &v.Lang, &v.Kind, &v.Variant, &v.Text, &v.Source, &v.SourceURL,
+		&v.SubmittedBy, &v.IsDefault, &v.CreatedAt, &v.UpdatedAt)
+	return v, err
+}
+
+// Список версий текста песни: сначала версия по умолчанию, затем оригинал, переводы и транслитерации
+func (r *lyricsRepository) ListLyricsVersions(ctx context.Context, songID int) ([]models.LyricsVersion, error) {
+	query := `SELECT ` + lyricsVersionColumns + ` FROM song_lyrics_versions
+              WHERE song_id = $1
+              ORDER BY is_default DESC,
+                       CASE kind WHEN 'original' THEN 0 WHEN 'translation' THEN 1 WHEN 'transliteration' THEN 2 ELSE 3 END,
+                       updated_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []models.LyricsVersion
+	for rows.Next() {
+		v, err := scanLyricsVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Версии текстов нескольких песен одним запросом, порядок внутри песни тот же, что у ListLyricsVersions
+func (r *lyricsRepository) ListLyricsVersionsBySongs(ctx context.Context, songIDs []int) (map[int][]models.LyricsVersion, error) {
+	query := `SELECT ` + lyricsVersionColumns + ` FROM song_lyrics_versions
+              WHERE song_id = ANY($1)
+              ORDER BY song_id, is_default DESC,
+                       CASE kind WHEN 'original' THEN 0 WHEN 'translation' THEN 1 WHEN 'transliteration' THEN 2 ELSE 3 END,
+                       updated_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(songIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int][]models.LyricsVersion, len(songIDs))
+	for rows.Next() {
+		v, err := scanLyricsVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions[v.SongID] = append(versions[v.SongID], v)
+	}
+
+	return versions, rows.Err()
+}
+
+// Получение версии текста по идентификатору
+func (r *lyricsRepository) GetLyricsVersion(ctx context.Context, songID, versionID int) (models.LyricsVersion, error) {
+	query := `SELECT ` + lyricsVersionColumns + ` FROM song_lyrics_versions WHERE song_id = $1 AND id = $2`
+
+	return scanLyricsVersion(r.db.QueryRowContext(ctx, query, songID, versionID))
+}
+
+// Добавление или замена версии с тем же языком, видом и вариантом
+func (r *lyricsRepository) SaveLyricsVersion(ctx context.Context, v models.LyricsVersion) (int, error) {
+	query := `INSERT INTO song_lyrics_versions (song_id, lang, kind, variant, text, source, source_url, submitted_by)
+              VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))
+              ON CONFLICT (song_id, lang, kind, variant) DO UPDATE
+                  SET text = EXCLUDED.text, source = EXCLUDED.source, source_url = EXCLUDED.source_url,
+                      submitted_by = EXCLUDED.submitted_by, updated_at = NOW()
+              RETURNING id`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query, v.SongID, v.Lang, v.Kind, v.Variant, v.Text, v.Source, v.SourceURL, v.SubmittedBy).Scan(&id)
+	if err != nil {
+		// Нарушение внешнего ключа — песни с таким идентификатором нет
+		if isForeignKeyViolation(err) {
+			return 0, sql.ErrNoRows
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Назначение версии по умолчанию; прежняя версия по умолчанию снимается в той же транзакции
+func (r *lyricsRepository) SetDefaultLyricsVersion(ctx context.Context, songID, versionID int) error {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM song_lyrics_versions WHERE song_id = $1 AND id = $2)`, songID, versionID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE song_lyrics_versions SET is_default = FALSE WHERE song_id = $1 AND is_default`, songID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE song_lyrics_versions SET is_default = TRUE WHERE id = $1`, versionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Удаление версии; оригинал удалить нельзя, он повторяет текст песни
+func (r *lyricsRepository) DeleteLyricsVersion(ctx context.Context, songID, versionID int) error {
+	query := `DELETE FROM song_lyrics_versions WHERE song_id = $1 AND id = $2 AND kind <> 'original'`
+
+	result, err := r.db.ExecContext(ctx, query, songID, versionID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -1,28 +1,162 @@
 package postgresrepo
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"log"
+	"musPlayer/internal/logger"
 	"musPlayer/models"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
 )
 
+// sqlState возвращает код ошибки Postgres (SQLSTATE) от lib/pq или pgx, для остальных ошибок — пустую строку
+func sqlState(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// maxConnectRetryDelay ограничивает паузу между попытками подключения при старте
+const maxConnectRetryDelay = 30 * time.Second
+
+// DataSourceName возвращает строку подключения: database.url или собранную из параметров,
+// дополненную connect_timeout и statement_timeout. Строку понимают и lib/pq, и pgx
+func DataSourceName(dbConfig models.DatabaseConfig) string {
+	params := [][2]string{
+		{"connect_timeout", strconv.Itoa(int((dbConfig.ConnectTimeout + time.Second - 1) / time.Second))},
+	}
+	if dbConfig.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", strconv.FormatInt(dbConfig.StatementTimeout.Milliseconds(), 10)})
+	}
+
+	if dbConfig.URL != "" {
+		return withDSNParams(dbConfig.URL, params)
+	}
+
+	dsn := [][2]string{
+		{"host", dbConfig.Host},
+		{"port", dbConfig.Port},
+		{"user", dbConfig.User},
+		{"password", dbConfig.Password},
+		{"dbname", dbConfig.Name},
+		{"sslmode", dbConfig.SSLMode},
+	}
+	for _, p := range [][2]string{{"sslrootcert", dbConfig.SSLRootCert}, {"sslcert", dbConfig.SSLCert}, {"sslkey", dbConfig.SSLKey}} {
+		if p[1] != "" {
+			dsn = append(dsn, p)
+		}
+	}
+	return withDSNParams("", append(dsn, params...))
+}
+
+// withDSNParams добавляет к строке подключения параметры, которых в ней еще нет
+func withDSNParams(dsn string, params [][2]string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			// Ошибку разбора покажет драйвер при подключении
+			return dsn
+		}
+		q := u.Query()
+		for _, p := range params {
+			if !q.Has(p[0]) {
+				q.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	parts := []string{}
+	if dsn != "" {
+		parts = append(parts, dsn)
+	}
+	for _, p := range params {
+		if !strings.Contains(" "+dsn, " "+p[0]+"=") {
+			parts = append(parts, p[0]+"="+quoteDSNValue(p[1]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue заключает значение в кавычки, если в нем есть пробелы, кавычки или обратная косая черта
+func quoteDSNValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// NewPostgresDb открывает пул соединений драйвером database.pg_driver и ждет, пока Postgres станет доступен:
+// при старте вместе с базой (docker compose, Kubernetes) она может принимать соединения не сразу
 func NewPostgresDb(dbConfig models.DatabaseConfig) (*sql.DB, error) {
-	dbSource := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbConfig.Host, dbConfig.Port, dbConfig.User, dbConfig.Password, dbConfig.Name)
+	dsn := DataSourceName(dbConfig)
 
-	log.Println("Opening database connection...")
-	db, err := sql.Open("postgres", dbSource)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+	logger.Logger.Infof("Opening database connection (driver %s)...", dbConfig.PgDriver)
+	var db *sql.DB
+	switch dbConfig.PgDriver {
+	case models.PgDriverPgx:
+		connConfig, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse connection string: %w", err)
+		}
+		// По умолчанию pgx подготавливает запросы и кеширует их на соединении
+		connConfig.StatementCacheCapacity = dbConfig.StatementCacheSize
+		if dbConfig.StatementCacheSize == 0 {
+			// Без кеша типы параметров все равно берутся из описания запроса сервером, как с кешем
+			connConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+		}
+		db = stdlib.OpenDB(*connConfig)
+	default:
+		var err error
+		if db, err = sql.Open("postgres", dsn); err != nil {
+			return nil, fmt.Errorf("failed to open database: %w", err)
+		}
 	}
 
-	log.Println("Pinging database...")
-	err = db.Ping()
-	if err != nil {
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+
+	if err := pingWithRetry(db, dbConfig); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 	return db, nil
 }
+
+// pingWithRetry проверяет соединение до connect_attempts раз, удваивая паузу между попытками
+func pingWithRetry(db *sql.DB, dbConfig models.DatabaseConfig) error {
+	delay := dbConfig.ConnectRetryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), dbConfig.ConnectTimeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt >= dbConfig.ConnectAttempts {
+			return err
+		}
+
+		logger.Logger.Warnf("Database is not available (attempt %d of %d), retrying in %s: %v", attempt, dbConfig.ConnectAttempts, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, maxConnectRetryDelay)
+	}
+}
@@ -0,0 +1,212 @@
+package postgresrepo
+
+import (
+	"musPlayer/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/lib/pq"
+)
+
+func TestDataSourceName(t *testing.T) {
+	base := models.DatabaseConfig{
+		Host:           "db",
+		Port:           "5432",
+		User:           "musplayer",
+		Password:       "secret",
+		Name:           "music",
+		SSLMode:        "disable",
+		ConnectTimeout: 5 * time.Second,
+	}
+	with := func(f func(c *models.DatabaseConfig)) models.DatabaseConfig {
+		c := base
+		f(&c)
+		return c
+	}
+
+	tests := []struct {
+		name string
+		cfg  models.DatabaseConfig
+		want string
+	}{
+		{
+			name: "fields",
+			cfg:  base,
+			want: "host=db port=5432 user=musplayer password=secret dbname=music sslmode=disable connect_timeout=5",
+		},
+		{
+			name: "verify-full with certificates",
+			cfg: with(func(c *models.DatabaseConfig) {
+				c.SSLMode = "verify-full"
+				c.SSLRootCert = "/etc/ssl/ca.pem"
+				c.SSLCert = "/etc/ssl/client.pem"
+				c.SSLKey = "/etc/ssl/client.key"
+			}),
+			want: "host=db port=5432 user=musplayer password=secret dbname=music sslmode=verify-full " +
+				"sslrootcert=/etc/ssl/ca.pem sslcert=/etc/ssl/client.pem sslkey=/etc/ssl/client.key connect_timeout=5",
+		},
+		{
+			name: "require without certificates",
+			cfg:  with(func(c *models.DatabaseConfig) { c.SSLMode = "require" }),
+			want: "host=db port=5432 user=musplayer password=secret dbname=music sslmode=require connect_timeout=5",
+		},
+		{
+			name: "quoted values",
+			cfg:  with(func(c *models.DatabaseConfig) { c.Password = `it's a \secret`; c.Name = "" }),
+			want: `host=db port=5432 user=musplayer password='it\'s a \\secret' dbname='' sslmode=disable connect_timeout=5`,
+		},
+		{
+			name: "timeouts are rounded up to seconds and milliseconds",
+			cfg: with(func(c *models.DatabaseConfig) {
+				c.ConnectTimeout = 1500 * time.Millisecond
+				c.StatementTimeout = 30 * time.Second
+			}),
+			want: "host=db port=5432 user=musplayer password=secret dbname=music sslmode=disable connect_timeout=2 statement_timeout=30000",
+		},
+		{
+			name: "url overrides fields and TLS",
+			cfg: with(func(c *models.DatabaseConfig) {
+				c.URL = "postgres://app:pw@managed.example.com:6543/prod?sslmode=verify-full"
+				c.SSLRootCert = "/etc/ssl/ca.pem"
+				c.StatementTimeout = time.Second
+			}),
+			want: "postgres://app:pw@managed.example.com:6543/prod?connect_timeout=5&sslmode=verify-full&statement_timeout=1000",
+		},
+		{
+			name: "url keeps its own parameters",
+			cfg: with(func(c *models.DatabaseConfig) {
+				c.URL = "postgresql://app@db/prod?connect_timeout=30&statement_timeout=0"
+				c.StatementTimeout = time.Second
+			}),
+			want: "postgresql://app@db/prod?connect_timeout=30&statement_timeout=0",
+		},
+		{
+			name: "keyword dsn override",
+			cfg: with(func(c *models.DatabaseConfig) {
+				c.URL = "host=managed.example.com sslmode=verify-full connect_timeout=10"
+				c.StatementTimeout = time.Second
+			}),
+			want: "host=managed.example.com sslmode=verify-full connect_timeout=10 statement_timeout=1000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DataSourceName(tt.cfg); got != tt.want {
+				t.Fatalf("DataSourceName() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataSourceNameIsParsedByDrivers(t *testing.T) {
+	cfg := models.DatabaseConfig{
+		Host:             "db",
+		Port:             "5432",
+		User:             "musplayer",
+		Password:         `it's a \secret`,
+		Name:             "music",
+		SSLMode:          "disable",
+		ConnectTimeout:   5 * time.Second,
+		StatementTimeout: 30 * time.Second,
+	}
+	dsn := DataSourceName(cfg)
+
+	pgxCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("pgx.ParseConfig: %v", err)
+	}
+	if pgxCfg.Password != cfg.Password || pgxCfg.Database != cfg.Name || pgxCfg.ConnectTimeout != cfg.ConnectTimeout {
+		t.Errorf("pgx parsed password %q, database %q, connect timeout %s", pgxCfg.Password, pgxCfg.Database, pgxCfg.ConnectTimeout)
+	}
+	if got := pgxCfg.RuntimeParams["statement_timeout"]; got != "30000" {
+		t.Errorf("pgx statement_timeout = %q, want 30000", got)
+	}
+
+	if _, err := pq.NewConnector(dsn); err != nil {
+		t.Fatalf("pq.NewConnector: %v", err)
+	}
+}
+
+func TestWithDSNParams(t *testing.T) {
+	params := [][2]string{{"connect_timeout", "5"}, {"application_name", "mus player"}}
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{dsn: "", want: "connect_timeout=5 application_name='mus player'"},
+		{dsn: "host=db", want: "host=db connect_timeout=5 application_name='mus player'"},
+		{dsn: "host=db connect_timeout=1", want: "host=db connect_timeout=1 application_name='mus player'"},
+		// Параметр ищется по имени целиком, а не по окончанию другого имени
+		{dsn: "host=db x_connect_timeout=1", want: "host=db x_connect_timeout=1 connect_timeout=5 application_name='mus player'"},
+		{dsn: "postgres://db/music", want: "postgres://db/music?application_name=mus+player&connect_timeout=5"},
+		{dsn: "postgres://db/music?connect_timeout=1", want: "postgres://db/music?application_name=mus+player&connect_timeout=1"},
+		// Неразбираемый URL возвращается как есть, ошибку покажет драйвер
+		{dsn: "postgres://db:port/music", want: "postgres://db:port/music"},
+	}
+	for _, tt := range tests {
+		if got := withDSNParams(tt.dsn, params); got != tt.want {
+			t.Errorf("withDSNParams(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+// Репозитории передают массивы через pq.Array с обоими драйверами. pgx кодирует параметр-driver.Valuer
+// по типу, который сервер сообщил для запроса, а массивы в результатах database/sql получает от pgx
+// в текстовом формате, который разбирает pq.Array
+func TestPQArrayWithPgx(t *testing.T) {
+	m := pgtype.NewMap()
+
+	t.Run("parameters", func(t *testing.T) {
+		tests := []struct {
+			name string
+			oid  uint32
+			arg  any
+			want any
+		}{
+			{name: "int ids as int4[]", oid: pgtype.Int4ArrayOID, arg: pq.Array([]int{1, 2, 3}), want: []int64{1, 2, 3}},
+			{name: "int ids as int8[]", oid: pgtype.Int8ArrayOID, arg: pq.Array([]int{1, 2, 3}), want: []int64{1, 2, 3}},
+			{name: "int64 ids", oid: pgtype.Int8ArrayOID, arg: pq.Array([]int64{7, 8}), want: []int64{7, 8}},
+			{name: "strings", oid: pgtype.TextArrayOID, arg: pq.Array([]string{"rock", "hip hop", `"q"`, ""}), want: []string{"rock", "hip hop", `"q"`, ""}},
+			{name: "empty", oid: pgtype.TextArrayOID, arg: pq.Array([]string{}), want: []string{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var eqb pgx.ExtendedQueryBuilder
+				sd := &pgconn.StatementDescription{ParamOIDs: []uint32{tt.oid}}
+				if err := eqb.Build(m, sd, []any{tt.arg}); err != nil {
+					t.Fatalf("pgx cannot encode %T: %v", tt.arg, err)
+				}
+
+				// Сервер разберет параметр так же, как кодек pgx для этого типа
+				got := reflect.New(reflect.TypeOf(tt.want))
+				if err := m.Scan(tt.oid, eqb.ParamFormats[0], eqb.ParamValues[0], got.Interface()); err != nil {
+					t.Fatalf("encoded value %q is not a valid array: %v", eqb.ParamValues[0], err)
+				}
+				if !reflect.DeepEqual(got.Elem().Interface(), tt.want) {
+					t.Fatalf("encoded %q = %v, want %v", eqb.ParamValues[0], got.Elem().Interface(), tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("results", func(t *testing.T) {
+		// Так stdlib превращает столбец text[] в driver.Value
+		var value string
+		plan := m.PlanScan(pgtype.TextArrayOID, pgtype.TextFormatCode, &value)
+		if err := plan.Scan([]byte(`{rock,"hip hop","with \"quotes\""}`), &value); err != nil {
+			t.Fatalf("scan text[] into string: %v", err)
+		}
+
+		var tags []string
+		if err := pq.Array(&tags).Scan(value); err != nil {
+			t.Fatalf("pq.Array.Scan(%q): %v", value, err)
+		}
+		if want := []string{"rock", "hip hop", `with "quotes"`}; !reflect.DeepEqual(tags, want) {
+			t.Fatalf("tags = %q, want %q", tags, want)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package postgresrepo
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// maxCacheKeyLen — длина столбца provider_cache.key в символах
+const maxCacheKeyLen = 1024
+
+type providerCacheRepository struct {
+	db Querier
+}
+
+func NewProviderCacheRepository(db Querier) ProviderCacheRepository {
+	return &providerCacheRepository{
+		db: db,
+	}
+}
+
+// storedCacheKey возвращает ключ, под которым запись хранится в таблице. Длинный ключ укорачивается,
+// а в конец добавляется SHA-256 полного ключа: начало ключа сохраняется для удаления по префиксу
+func storedCacheKey(key string) string {
+	if utf8.RuneCountInString(key) <= maxCacheKeyLen {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	suffix := "#" + hex.EncodeToString(sum[:])
+
+	keep := 0
+	for n := 0; n < maxCacheKeyLen-len(suffix); n++ {
+		_, size := utf8.DecodeRuneInString(key[keep:])
+		keep += size
+	}
+	return key[:keep] + suffix
+}
+
+// Получение записи кеша, если она не устарела
+func (r *providerCacheRepository) GetCacheEntry(ctx context.Context, key string) ([]byte, bool, error) {
+	query := `SELECT value FROM provider_cache WHERE key = $1 AND expires_at > NOW()`
+
+	var value []byte
+	err := r.db.QueryRowContext(ctx, query, storedCacheKey(key)).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Сохранение записи кеша
+func (r *providerCacheRepository) SetCacheEntry(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	query := `INSERT INTO provider_cache (key, value, expires_at) VALUES ($1, $2, NOW() + $3 * INTERVAL '1 millisecond')
+              ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at, created_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, query, storedCacheKey(key), value, ttl.Milliseconds())
+	return err
+}
+
+// Удаление записей по префиксу ключа
+func (r *providerCacheRepository) PurgeCacheEntries(ctx context.Context, prefix string) (int64, error) {
+	query := `DELETE FROM provider_cache WHERE starts_with(key, $1)`
+
+	result, err := r.db.ExecContext(ctx, query, prefix)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Удаление устаревших записей
+func (r *providerCacheRepository) DeleteExpiredCacheEntries(ctx context.Context) (int64, error) {
+	query := `DELETE FROM provider_cache WHERE expires_at <= NOW()`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
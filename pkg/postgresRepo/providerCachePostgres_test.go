@@ -0,0 +1,50 @@
+package postgresrepo
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStoredCacheKey(t *testing.T) {
+	long := "genius:search:" + strings.Repeat("a", 2000)
+	longCyrillic := "genius:search:" + strings.Repeat("я", 2000)
+
+	tests := []struct {
+		name   string
+		key    string
+		hashed bool
+	}{
+		{name: "short", key: "genius:search:hello", hashed: false},
+		{name: "at limit", key: strings.Repeat("a", maxCacheKeyLen), hashed: false},
+		{name: "cyrillic at limit", key: strings.Repeat("я", maxCacheKeyLen), hashed: false},
+		{name: "over limit", key: long, hashed: true},
+		{name: "cyrillic over limit", key: longCyrillic, hashed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := storedCacheKey(tt.key)
+			if !tt.hashed {
+				if got != tt.key {
+					t.Fatalf("key within the limit was changed")
+				}
+				return
+			}
+			if n := utf8.RuneCountInString(got); n != maxCacheKeyLen {
+				t.Fatalf("stored key has %d characters, want %d", n, maxCacheKeyLen)
+			}
+			if !utf8.ValidString(got) {
+				t.Fatal("stored key is not valid UTF-8")
+			}
+			// Префикс сохраняется для удаления по префиксу
+			if !strings.HasPrefix(got, "genius:search:") {
+				t.Fatalf("stored key lost its prefix: %.40q", got)
+			}
+		})
+	}
+
+	// Ключи с общим началом не должны совпасть после укорачивания
+	if storedCacheKey(long+"x") == storedCacheKey(long+"y") {
+		t.Fatal("different long keys map to the same stored key")
+	}
+}
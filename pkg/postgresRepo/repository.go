@@ -4,21 +4,169 @@ import (
 	"context"
 	"database/sql"
 	"musPlayer/models"
+	"time"
 )
 
 type SongRepository interface {
 	AddSong(ctx context.Context, song AddSongParams) (int, error)
-	GetSongs(ctx context.Context, filter string, limit, offset int) ([]models.Song, error)
+	GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error)
+	GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error)
 	DeleteSong(ctx context.Context, songID int64) error
 	UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error
 	GetSongText(ctx context.Context, songID int) (string, error)
+	GetArtistTexts(ctx context.Context, groupName string) ([]string, error)
 }
+
+// ProviderCacheRepository хранит ответы внешних провайдеров (Genius) с TTL
+type ProviderCacheRepository interface {
+	GetCacheEntry(ctx context.Context, key string) ([]byte, bool, error)
+	SetCacheEntry(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	PurgeCacheEntries(ctx context.Context, prefix string) (int64, error)
+	DeleteExpiredCacheEntries(ctx context.Context) (int64, error)
+}
+
+// SyncRepository хранит состояние синхронизации песен с исходными страницами и историю изменений
+type SyncRepository interface {
+	GetSongSyncState(ctx context.Context, songID int) (models.SongSyncState, error)
+	ListSongsForSync(ctx context.Context, before time.Time, limit int) ([]models.SongSyncState, error)
+	ApplySongSync(ctx context.Context, upd models.SongSyncUpdate) error
+	MarkSongSync(ctx context.Context, songID int, status, syncErr string) error
+}
+
+// LyricsRepository хранит версии текста песни: оригинал, переводы и транслитерации
+type LyricsRepository interface {
+	ListLyricsVersions(ctx context.Context, songID int) ([]models.LyricsVersion, error)
+	ListLyricsVersionsBySongs(ctx context.Context, songIDs []int) (map[int][]models.LyricsVersion, error)
+	GetLyricsVersion(ctx context.Context, songID, versionID int) (models.LyricsVersion, error)
+	SaveLyricsVersion(ctx context.Context, v models.LyricsVersion) (int, error)
+	SetDefaultLyricsVersion(ctx context.Context, songID, versionID int) error
+	DeleteLyricsVersion(ctx context.Context, songID, versionID int) error
+}
+
+// SyncedLyricsRepository хранит синхронизированные тексты (LRC) с временем каждой строки
+type SyncedLyricsRepository interface {
+	SaveSyncedLyrics(ctx context.Context, l models.SyncedLyrics) error
+	GetSyncedLyrics(ctx context.Context, songID int) (models.SyncedLyrics, error)
+	GetSyncedLinesAt(ctx context.Context, songID, atMs int) (current, next *models.SyncedLine, offsetMs int, err error)
+	ShiftSyncedLyrics(ctx context.Context, songID, shiftMs int) (int, error)
+}
+
+// SimilarityRepository хранит частоты слов песен для индекса похожих песен
+type SimilarityRepository interface {
+	SaveTermVector(ctx context.Context, v models.TermVector) error
+	LoadTermVectors(ctx context.Context) ([]models.TermVector, error)
+	ListSongsToIndex(ctx context.Context, limit int) ([]models.Song, error)
+	GetSongForIndex(ctx context.Context, songID int) (models.Song, error)
+	ListIndexedSongIDs(ctx context.Context) ([]int, error)
+	GetSongsByIDs(ctx context.Context, ids []int) ([]models.Song, error)
+}
+
+// TagRepository хранит теги и их назначение песням
+type TagRepository interface {
+	ListTags(ctx context.Context, namespace string) ([]models.Tag, error)
+	GetTag(ctx context.Context, tagID int) (models.Tag, error)
+	CreateTag(ctx context.Context, tag models.Tag) (models.Tag, error)
+	UpdateTag(ctx context.Context, tag models.Tag) (models.Tag, error)
+	DeleteTag(ctx context.Context, tagID int) error
+	AssignTags(ctx context.Context, tags []models.Tag, songs models.SongSelection) (int64, error)
+	UnassignTags(ctx context.Context, tags []string, songs models.SongSelection) (int64, error)
+	TagFacets(ctx context.Context, filter models.SongFilter, namespace string) ([]models.TagCount, error)
+}
+
+// LibraryRepository хранит личное состояние песен пользователя: библиотеку, избранное, оценки и заметки
+type LibraryRepository interface {
+	ListLibrary(ctx context.Context, userID string, filter models.SongFilter, favoritesOnly bool, limit, offset int) ([]models.LibrarySong, error)
+	AddToLibrary(ctx context.Context, userID string, songID int, note *string) error
+	RemoveFromLibrary(ctx context.Context, userID string, songID int) error
+	SetFavorite(ctx context.Context, userID string, songID int, favorite bool) error
+	SetRating(ctx context.Context, userID string, songID int, rating *int) error
+	GetRating(ctx context.Context, userID string, songID int) (models.SongRating, error)
+}
+
+// ListenRepository хранит историю прослушиваний и очередь треков, которых нет в каталоге
+type ListenRepository interface {
+	SaveListens(ctx context.Context, userID string, listens []models.Listen) (inserted, matched int, err error)
+	ListListens(ctx context.Context, userID string, after, before time.Time, limit int, ascending bool) ([]models.Listen, error)
+	SetPlayingNow(ctx context.Context, userID string, l models.Listen, ttl time.Duration) error
+	GetPlayingNow(ctx context.Context, userID string) (models.PlayingNow, error)
+	SongPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.SongPlayCount, error)
+	ArtistPlayCounts(ctx context.Context, userID string, period models.ListenStatsRange) ([]models.ArtistPlayCount, error)
+	ListenStreaks(ctx context.Context, userID, timeZone string) ([]models.ListenStreak, error)
+	ListIngestQueue(ctx context.Context, status string, limit, offset int) ([]models.IngestQueueItem, error)
+	NextIngestBatch(ctx context.Context, limit, maxAttempts int) ([]models.IngestQueueItem, error)
+	FindSongByArtistTitle(ctx context.Context, artist, title string) (int, error)
+	FindSongByLink(ctx context.Context, link string) (int, error)
+	ResolveIngest(ctx context.Context, queueID, songID int) (int64, error)
+	MarkIngest(ctx context.Context, queueID int, status, errMsg string, retryAfter time.Duration) error
+}
+
+// WebhookRepository хранит подписки на события, outbox событий и журнал их доставки
+type WebhookRepository interface {
+	ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int) (models.WebhookSubscription, error)
+	CreateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID int64) (models.WebhookDelivery, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error)
+	RecordAttempt(ctx context.Context, a models.DeliveryAttempt) error
+	PurgeEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SongEventRepository читает события каталога из outbox и ждет уведомлений о новых через LISTEN/NOTIFY
+type SongEventRepository interface {
+	SongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error)
+	SongEventsByIDs(ctx context.Context, ids []int64) ([]models.SongEvent, error)
+	LastSongEventID(ctx context.Context) (int64, error)
+	ListenSongEvents(ctx context.Context, onEvents func(ids []int64), onGap func()) error
+}
+
+// ChangeFeedRepository читает ленту изменений песен для инкрементальной синхронизации клиентов
+type ChangeFeedRepository interface {
+	ListSongChanges(ctx context.Context, since int64, limit int) (models.ChangeFeedPage, error)
+	PurgeTombstones(ctx context.Context, before time.Time) (int64, error)
+}
+
 type Repository struct {
 	SongRepository
+	ProviderCacheRepository
+	SyncRepository
+	LyricsRepository
+	SyncedLyricsRepository
+	SimilarityRepository
+	TagRepository
+	LibraryRepository
+	ListenRepository
+	WebhookRepository
+	SongEventRepository
+	ChangeFeedRepository
+	db  *sql.DB
+	q   Querier // db или транзакция WithTx, через которую работают репозитории
+	dsn string
+}
+
+// NewRepository создает репозиторий; dsn нужен для отдельного соединения LISTEN
+func NewRepository(db *sql.DB, dsn string) *Repository {
+	return newRepository(db, db, dsn)
 }
 
-func NewRepository(db *sql.DB) *Repository {
+func newRepository(q Querier, db *sql.DB, dsn string) *Repository {
 	return &Repository{
-		SongRepository: NewSongRepository(db),
+		SongRepository:          NewSongRepository(q),
+		ProviderCacheRepository: NewProviderCacheRepository(q),
+		SyncRepository:          NewSyncRepository(q),
+		LyricsRepository:        NewLyricsRepository(q),
+		SyncedLyricsRepository:  NewSyncedLyricsRepository(q),
+		SimilarityRepository:    NewSimilarityRepository(q),
+		TagRepository:           NewTagRepository(q),
+		LibraryRepository:       NewLibraryRepository(q),
+		ListenRepository:        NewListenRepository(q),
+		WebhookRepository:       NewWebhookRepository(q),
+		SongEventRepository:     NewSongEventRepository(q, dsn),
+		ChangeFeedRepository:    NewChangeFeedRepository(q),
+		db:                      db,
+		q:                       q,
+		dsn:                     dsn,
 	}
 }
@@ -0,0 +1,143 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"musPlayer/models"
+
+	"github.com/lib/pq"
+)
+
+type similarityRepository struct {
+	db Querier
+}
+
+func NewSimilarityRepository(db Querier) SimilarityRepository {
+	return &similarityRepository{
+		db: db,
+	}
+}
+
+// Сохранение частот слов песни
+func (r *similarityRepository) SaveTermVector(ctx context.Context, v models.TermVector) error {
+	terms, err := json.Marshal(v.Terms)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO song_term_vectors (song_id, terms) VALUES ($1, $2)
+              ON CONFLICT (song_id) DO UPDATE SET terms = EXCLUDED.terms, indexed_at = NOW()`
+	if _, err := r.db.ExecContext(ctx, query, v.SongID, terms); err != nil {
+		// Песню удалили, пока строился вектор
+		if isForeignKeyViolation(err) {
+			return sql.ErrNoRows
+		}
+		return err
+	}
+	return nil
+}
+
+// Загрузка всех сохраненных векторов
+func (r *similarityRepository) LoadTermVectors(ctx context.Context) ([]models.TermVector, error) {
+	query := `SELECT v.song_id, s.group_name, v.terms FROM song_term_vectors v JOIN songs s ON s.id = v.song_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var vectors []models.TermVector
+	for rows.Next() {
+		var v models.TermVector
+		var terms []byte
+		if err := rows.Scan(&v.SongID, &v.GroupName, &terms); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(terms, &v.Terms); err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, v)
+	}
+
+	return vectors, rows.Err()
+}
+
+// Песни без вектора или измененные после его построения
+func (r *similarityRepository) ListSongsToIndex(ctx context.Context, limit int) ([]models.Song, error) {
+	query := `SELECT s.id, s.group_name, COALESCE(s.text, '') FROM songs s
+              LEFT JOIN song_term_vectors v ON v.song_id = s.id
+              WHERE v.song_id IS NULL OR s.updated_at > v.indexed_at
+              ORDER BY s.id
+              LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.Song
+	for rows.Next() {
+		var song models.Song
+		if err := rows.Scan(&song.ID, &song.GroupName, &song.Text); err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
+
+// Песня с текстом для построения вектора
+func (r *similarityRepository) GetSongForIndex(ctx context.Context, songID int) (models.Song, error) {
+	query := `SELECT id, group_name, COALESCE(text, '') FROM songs WHERE id = $1`
+
+	var song models.Song
+	err := r.db.QueryRowContext(ctx, query, songID).Scan(&song.ID, &song.GroupName, &song.Text)
+	return song, err
+}
+
+// Идентификаторы песен, у которых есть вектор
+func (r *similarityRepository) ListIndexedSongIDs(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT song_id FROM song_term_vectors`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Песни по списку идентификаторов без текстов
+func (r *similarityRepository) GetSongsByIDs(ctx context.Context, ids []int) ([]models.Song, error) {
+	query := `SELECT id, group_name, song_name, COALESCE(release_date, ''), COALESCE(album, ''), COALESCE(link, ''), rating_avg, rating_count, created_at, updated_at
+              FROM songs WHERE id = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.Song
+	for rows.Next() {
+		var song models.Song
+		if err := rows.Scan(&song.ID, &song.GroupName, &song.SongName, &song.ReleaseDate, &song.Album, &song.Link, &song.RatingAvg, &song.RatingCount, &song.CreatedAt, &song.UpdatedAt); err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+
+	return songs, rows.Err()
+}
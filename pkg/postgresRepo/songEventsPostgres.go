@@ -0,0 +1,131 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"musPlayer/models"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Канал NOTIFY, в который триггер outbox_events_notify пишет идентификаторы новых событий
+const songEventsChannel = "song_events"
+
+type songEventRepository struct {
+	db  Querier
+	dsn string
+}
+
+func NewSongEventRepository(db Querier, dsn string) SongEventRepository {
+	return &songEventRepository{
+		db:  db,
+		dsn: dsn,
+	}
+}
+
+func scanSongEvents(rows *sql.Rows) ([]models.SongEvent, error) {
+	defer rows.Close()
+
+	var events []models.SongEvent
+	for rows.Next() {
+		var e models.SongEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.CreatedAt, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &e.SongEventData); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// События после afterID по возрастанию идентификатора
+func (r *songEventRepository) SongEventsAfter(ctx context.Context, afterID int64, limit int) ([]models.SongEvent, error) {
+	query := `SELECT id, event_type, created_at, payload FROM outbox_events WHERE id > $1 ORDER BY id LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSongEvents(rows)
+}
+
+// События с заданными идентификаторами по возрастанию идентификатора
+func (r *songEventRepository) SongEventsByIDs(ctx context.Context, ids []int64) ([]models.SongEvent, error) {
+	query := `SELECT id, event_type, created_at, payload FROM outbox_events WHERE id = ANY($1) ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	return scanSongEvents(rows)
+}
+
+// Идентификатор последнего события; 0, если событий нет
+func (r *songEventRepository) LastSongEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox_events`).Scan(&id)
+	return id, err
+}
+
+// ListenSongEvents ждет уведомлений о новых событиях, пока не отменен ctx. onEvents получает идентификаторы
+// событий, пришедших вместе; onGap вызывается после переподключения, когда уведомления могли потеряться
+func (r *songEventRepository) ListenSongEvents(ctx context.Context, onEvents func(ids []int64), onGap func()) error {
+	listener := pq.NewListener(r.dsn, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	if err := listener.Listen(songEventsChannel); err != nil {
+		return err
+	}
+
+	// Проверка соединения, которое может молча оборваться без трафика
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			listener.Ping()
+		case n := <-listener.Notify:
+			ids, gap := collectNotifications(n, listener.Notify)
+			if gap {
+				onGap()
+			}
+			if len(ids) > 0 {
+				onEvents(ids)
+			}
+		}
+	}
+}
+
+// collectNotifications забирает уже пришедшие уведомления, чтобы прочитать события одним запросом.
+// nil в канале означает восстановленное соединение
+func collectNotifications(first *pq.Notification, more <-chan *pq.Notification) (ids []int64, gap bool) {
+	const maxBatch = 500
+
+	n := first
+	for {
+		if n == nil {
+			gap = true
+		} else if id, err := strconv.ParseInt(n.Extra, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+		if len(ids) >= maxBatch {
+			return ids, gap
+		}
+
+		select {
+		case n = <-more:
+		default:
+			return ids, gap
+		}
+	}
+}
@@ -3,14 +3,20 @@ package postgresrepo
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"musPlayer/models"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type songRepository struct {
-	db *sql.DB
+	db Querier
 }
 
-func NewSongRepository(db *sql.DB) SongRepository {
+func NewSongRepository(db Querier) SongRepository {
 	return &songRepository{
 		db: db,
 	}
@@ -22,20 +28,40 @@ type AddSongParams struct {
 	SongName    string
 	Text        string
 	ReleaseDate string
+	Album       string
 	Link        string
 }
 
-// Добавление песни
+// Добавление песни вместе с событием song.created
 func (r *songRepository) AddSong(ctx context.Context, song AddSongParams) (int, error) {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
 	var id int
-	query := `INSERT INTO songs (song_id, group_name, song_name, text, release_date, link ) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	query := `INSERT INTO songs (song_id, group_name, song_name, text, release_date, album, link) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query, song.SongId, song.GroupName, song.SongName, song.Text, song.ReleaseDate, song.Link).Scan(&id)
+	err = tx.QueryRowContext(ctx, query, song.SongId, song.GroupName, song.SongName, song.Text, song.ReleaseDate, song.Album, song.Link).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	if err := r.recordEvent(ctx, tx, models.EventSongCreated, id); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// recordEvent пишет в outbox событие с текущим состоянием песни
+func (r *songRepository) recordEvent(ctx context.Context, tx Querier, eventType string, songID int) error {
+	saved, err := selectSongTx(ctx, tx, songID, false)
+	if err != nil {
+		return err
+	}
+	return recordSongEvent(ctx, tx, eventType, models.EventSourceAPI, saved)
 }
 
 // Получение текста песни
@@ -51,11 +77,51 @@ func (r *songRepository) GetSongText(ctx context.Context, songID int) (string, e
 	return songText, nil
 }
 
+// Получение текстов всех песен исполнителя
+func (r *songRepository) GetArtistTexts(ctx context.Context, groupName string) ([]string, error) {
+	query := `SELECT COALESCE(text, '') FROM songs WHERE LOWER(group_name) = LOWER($1) ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, groupName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, rows.Err()
+}
+
+// Колонки песни для списков; порядок совпадает с scanSong
+const songColumns = `songs.id, songs.group_name, songs.song_name, songs.text, songs.release_date, COALESCE(songs.album, ''), COALESCE(songs.link, ''),
+       ARRAY(SELECT t.full_name FROM song_tags st JOIN tags t ON t.id = st.tag_id WHERE st.song_id = songs.id ORDER BY t.full_name),
+       songs.rating_avg, songs.rating_count`
+
+// scanSong читает колонки songColumns, за которыми могут идти дополнительные
+func scanSong(row interface{ Scan(...interface{}) error }, song *models.Song, extra ...interface{}) error {
+	dest := append([]interface{}{&song.ID, &song.GroupName, &song.SongName, &song.Text, &song.ReleaseDate, &song.Album, &song.Link,
+		pq.Array(&song.Tags), &song.RatingAvg, &song.RatingCount}, extra...)
+	return row.Scan(dest...)
+}
+
 // Получение списка песен
-func (r *songRepository) GetSongs(ctx context.Context, filter string, limit, offset int) ([]models.Song, error) {
-	query := `SELECT id, group_name, song_name, text, release_date FROM songs WHERE group_name ILIKE $1 LIMIT $2 OFFSET $3`
+func (r *songRepository) GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
+	orderBy, err := songOrderBy(filter.Sort, nil)
+	if err != nil {
+		return nil, err
+	}
+	where, args := songFilterWhere(filter, nil)
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`SELECT %s FROM songs WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`, songColumns, where, orderBy, len(args)-1, len(args))
 
-	rows, err := r.db.QueryContext(ctx, query, "%"+filter+"%", limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,42 +130,166 @@ func (r *songRepository) GetSongs(ctx context.Context, filter string, limit, off
 	var songs []models.Song
 	for rows.Next() {
 		var song models.Song
-		if err := rows.Scan(&song.ID, &song.GroupName, &song.SongName, &song.Text, &song.ReleaseDate); err != nil {
+		if err := scanSong(rows, &song); err != nil {
 			return nil, err
 		}
 		songs = append(songs, song)
 	}
 
-	return songs, nil
+	return songs, rows.Err()
 }
 
-func (r *songRepository) DeleteSong(ctx context.Context, songID int64) error {
-	query := `DELETE FROM songs WHERE id = $1`
+// Песни нескольких исполнителей одним запросом: для каждого имени (без учета регистра) страница limit/offset в порядке id.
+// Результат разложен по именам в том виде, в каком они переданы
+func (r *songRepository) GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error) {
+	query := `SELECT ` + songColumns + `, songs.artist
+              FROM (SELECT req.name AS artist, songs.*,
+                           ROW_NUMBER() OVER (PARTITION BY req.name ORDER BY songs.id) AS rn
+                      FROM unnest($1::text[]) AS req(name)
+                      JOIN songs ON LOWER(songs.group_name) = LOWER(req.name)) AS songs
+              WHERE songs.rn > $2::int AND songs.rn <= $2::int + $3::int
+              ORDER BY songs.artist, songs.rn`
 
-	result, err := r.db.ExecContext(ctx, query, songID)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(names), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	songs := make(map[string][]models.Song, len(names))
+	for rows.Next() {
+		var song models.Song
+		var artist string
+		if err := scanSong(rows, &song, &artist); err != nil {
+			return nil, err
+		}
+		songs[artist] = append(songs[artist], song)
+	}
+
+	return songs, rows.Err()
+}
+
+// ErrInvalidSort возвращается для неизвестного поля сортировки
+var ErrInvalidSort = errors.New("invalid sort field")
+
+// Поля сортировки песен и соответствующие им выражения
+var songSortColumns = map[string]string{
+	"id":           "songs.id",
+	"group":        "LOWER(songs.group_name)",
+	"song":         "LOWER(songs.song_name)",
+	"release_date": "songs.release_date",
+	"created_at":   "songs.created_at",
+	"rating":       "songs.rating_avg",
+	"rating_count": "songs.rating_count",
+}
+
+// songOrderBy переводит поле сортировки (id, -rating) в ORDER BY; extra добавляет поля, доступные только в конкретном запросе.
+// Песни без значения идут в конце, при равенстве порядок определяется идентификатором
+func songOrderBy(sort string, extra map[string]string) (string, error) {
+	if sort == "" {
+		sort = "id"
+	}
+	field, desc := strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+
+	column, ok := songSortColumns[field]
+	if !ok {
+		column, ok = extra[field]
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSort, sort)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	if field == "id" {
+		return "songs.id " + direction, nil
+	}
+	return column + " " + direction + " NULLS LAST, songs.id", nil
+}
+
+// songFilterWhere строит условие WHERE по фильтру для таблицы songs. Параметры нумеруются после уже переданных args
+func songFilterWhere(f models.SongFilter, args []interface{}) (string, []interface{}) {
+	conds := []string{"songs.group_name ILIKE $" + strconv.Itoa(len(args)+1)}
+	args = append(args, "%"+f.Group+"%")
+
+	if len(f.IDs) > 0 {
+		args = append(args, pq.Array(f.IDs))
+		conds = append(conds, "songs.id = ANY($"+strconv.Itoa(len(args))+")")
+	}
+	if f.AfterID > 0 {
+		args = append(args, f.AfterID)
+		conds = append(conds, "songs.id > $"+strconv.Itoa(len(args)))
+	}
+	if f.Song != "" {
+		args = append(args, "%"+f.Song+"%")
+		conds = append(conds, "songs.song_name ILIKE $"+strconv.Itoa(len(args)))
+	}
+	if len(f.TagsAll) > 0 {
+		args = append(args, pq.Array(f.TagsAll), len(f.TagsAll))
+		conds = append(conds, fmt.Sprintf(`(SELECT COUNT(*) FROM song_tags st JOIN tags t ON t.id = st.tag_id
+                     WHERE st.song_id = songs.id AND t.full_name = ANY($%d)) = $%d`, len(args)-1, len(args)))
+	}
+	if len(f.TagsAny) > 0 {
+		args = append(args, pq.Array(f.TagsAny))
+		conds = append(conds, fmt.Sprintf(`EXISTS (SELECT 1 FROM song_tags st JOIN tags t ON t.id = st.tag_id
+                     WHERE st.song_id = songs.id AND t.full_name = ANY($%d))`, len(args)))
+	}
+	if len(f.TagsNone) > 0 {
+		args = append(args, pq.Array(f.TagsNone))
+		conds = append(conds, fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM song_tags st JOIN tags t ON t.id = st.tag_id
+                     WHERE st.song_id = songs.id AND t.full_name = ANY($%d))`, len(args)))
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// Удаление песни; в событие song.deleted попадает песня перед удалением
+func (r *songRepository) DeleteSong(ctx context.Context, songID int64) error {
+	tx, err := begin(ctx, r.db, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	song, err := selectSongTx(ctx, tx, int(songID), true)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	if _, err := tx.ExecContext(ctx, `DELETE FROM songs WHERE id = $1`, songID); err != nil {
+		return err
 	}
 
-	return nil
+	if err := recordSongEvent(ctx, tx, models.EventSongDeleted, models.EventSourceAPI, song); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// Обновление песни
+// Обновление песни вместе с событием song.updated
 func (r *songRepository) UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Поля, значение которых меняется вручную, запоминаются в manual_fields, чтобы синхронизация их не перезаписывала
 	query := `UPDATE songs 
-              SET group_name = $1, song_name = $2, text = $3, release_date = $4
+              SET manual_fields = ARRAY(
+                      SELECT DISTINCT f FROM unnest(manual_fields || ARRAY[
+                          CASE WHEN group_name IS DISTINCT FROM $1 THEN 'group_name' END,
+                          CASE WHEN song_name IS DISTINCT FROM $2 THEN 'song_name' END,
+                          CASE WHEN text IS DISTINCT FROM $3 THEN 'text' END,
+                          CASE WHEN release_date IS DISTINCT FROM $4 THEN 'release_date' END
+                      ]) AS f WHERE f IS NOT NULL),
+                  group_name = $1, song_name = $2, text = $3, release_date = $4, updated_at = NOW()
               WHERE id = $5`
 
-	result, err := r.db.ExecContext(ctx, query, updSong.GroupName, updSong.SongName, updSong.Text, updSong.ReleaseDate, updSong.ID)
+	result, err := tx.ExecContext(ctx, query, updSong.GroupName, updSong.SongName, updSong.Text, updSong.ReleaseDate, updSong.ID)
 	if err != nil {
 		return err
 	}
@@ -113,5 +303,9 @@ func (r *songRepository) UpdateSong(ctx context.Context, updSong models.SongUpda
 		return sql.ErrNoRows
 	}
 
-	return nil
+	if err := r.recordEvent(ctx, tx, models.EventSongUpdated, updSong.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
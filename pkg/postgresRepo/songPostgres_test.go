@@ -0,0 +1,48 @@
+package postgresrepo_test
+
+import (
+	"database/sql"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"musPlayer/pkg/repotest"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDSNEnv — строка подключения к отдельной базе с примененными миграциями.
+// Проверки удаляют созданные песни, но оставляют события ленты изменений и вебхуков
+const testDSNEnv = "MUSPLAYER_TEST_DSN"
+
+// forEachDriver открывает тестовую базу через каждый драйвер Postgres и запускает run подтестом.
+// Без MUSPLAYER_TEST_DSN тест пропускается
+func forEachDriver(t *testing.T, run func(t *testing.T, db *sql.DB)) {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	for _, driver := range []string{models.PgDriverPQ, models.PgDriverPgx} {
+		t.Run(driver, func(t *testing.T) {
+			db, err := postgresrepo.NewPostgresDb(models.DatabaseConfig{
+				URL:             dsn,
+				PgDriver:        driver,
+				ConnectTimeout:  5 * time.Second,
+				ConnectAttempts: 1,
+				MaxOpenConns:    10,
+			})
+			if err != nil {
+				t.Fatalf("NewPostgresDb: %v", err)
+			}
+			defer db.Close()
+			run(t, db)
+		})
+	}
+}
+
+func TestSongRepository(t *testing.T) {
+	forEachDriver(t, func(t *testing.T, db *sql.DB) {
+		repotest.RunSongRepositoryChecks(t, postgresrepo.NewSongRepository(db))
+	})
+}
@@ -0,0 +1,153 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"musPlayer/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SyncFields — колонки songs, которые синхронизируются с исходной страницей и могут быть отредактированы вручную
+var SyncFields = []string{"group_name", "song_name", "release_date", "album", "text", "link"}
+
+type syncRepository struct {
+	db Querier
+}
+
+func NewSyncRepository(db Querier) SyncRepository {
+	return &syncRepository{
+		db: db,
+	}
+}
+
+const syncStateColumns = `id, COALESCE(song_id, 0), group_name, song_name, COALESCE(release_date, ''), COALESCE(album, ''),
+       COALESCE(text, ''), COALESCE(link, ''), created_at, updated_at,
+       manual_fields, COALESCE(content_hash, ''), last_synced_at, sync_status, COALESCE(sync_error, '')`
+
+func scanSyncState(row interface{ Scan(...interface{}) error }) (models.SongSyncState, error) {
+	var s models.SongSyncState
+	var genius int
+	var lastSynced sql.NullTime
+	err := row.Scan(&s.ID, &genius, &s.GroupName, &s.SongName, &s.ReleaseDate, &s.Album,
+		&s.Text, &s.Link, &s.CreatedAt, &s.UpdatedAt,
+		pq.Array(&s.ManualFields), &s.ContentHash, &lastSynced, &s.SyncStatus, &s.SyncError)
+	if err != nil {
+		return s, err
+	}
+	if lastSynced.Valid {
+		s.LastSyncedAt = &lastSynced.Time
+	}
+	return s, nil
+}
+
+// Получение состояния синхронизации песни
+func (r *syncRepository) GetSongSyncState(ctx context.Context, songID int) (models.SongSyncState, error) {
+	query := `SELECT ` + syncStateColumns + ` FROM songs WHERE id = $1`
+
+	return scanSyncState(r.db.QueryRowContext(ctx, query, songID))
+}
+
+// Песни с источником, которые не синхронизировались с момента before, начиная с самых старых
+func (r *syncRepository) ListSongsForSync(ctx context.Context, before time.Time, limit int) ([]models.SongSyncState, error) {
+	query := `SELECT ` + syncStateColumns + ` FROM songs
+              WHERE link IS NOT NULL AND link <> '' AND (last_synced_at IS NULL OR last_synced_at < $1)
+              ORDER BY last_synced_at NULLS FIRST, id
+              LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.SongSyncState
+	for rows.Next() {
+		s, err := scanSyncState(rows)
+		if err != nil {
+			return nil, err
+		}
+		songs = append(songs, s)
+	}
+
+	return songs, rows.Err()
+}
+
+// Применение результата синхронизации: изменение полей, отметка о синхронизации, запись ревизии и событие song.updated в одной транзакции
+func (r *syncRepository) ApplySongSync(ctx context.Context, upd models.SongSyncUpdate) error {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	set := []string{"content_hash = $2", "sync_status = $3", "last_synced_at = NOW()", "sync_error = NULL"}
+	args := []interface{}{upd.ID, upd.ContentHash, upd.Status}
+	if len(upd.Changes) > 0 {
+		set = append(set, "updated_at = NOW()")
+	}
+	for _, column := range SyncFields {
+		change, ok := upd.Changes[column]
+		if !ok {
+			continue
+		}
+		args = append(args, change.New)
+		set = append(set, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	query := `UPDATE songs SET ` + strings.Join(set, ", ") + ` WHERE id = $1`
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if len(upd.Changes) > 0 {
+		changes, err := json.Marshal(upd.Changes)
+		if err != nil {
+			return err
+		}
+		query := `INSERT INTO song_revisions (song_id, source, source_url, changes, content_hash) VALUES ($1, $2, $3, $4, $5)`
+		if _, err := tx.ExecContext(ctx, query, upd.ID, upd.Source, upd.SourceURL, changes, upd.ContentHash); err != nil {
+			return err
+		}
+
+		song, err := selectSongTx(ctx, tx, upd.ID, false)
+		if err != nil {
+			return err
+		}
+		if err := recordSongEvent(ctx, tx, models.EventSongUpdated, models.EventSourceSync, song); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Отметка о неудачной или пропущенной синхронизации
+func (r *syncRepository) MarkSongSync(ctx context.Context, songID int, status, syncErr string) error {
+	query := `UPDATE songs SET sync_status = $2, sync_error = NULLIF($3, ''), last_synced_at = NOW() WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, songID, status, syncErr)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
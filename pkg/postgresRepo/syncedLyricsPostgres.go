@@ -0,0 +1,157 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"musPlayer/models"
+)
+
+type syncedLyricsRepository struct {
+	db Querier
+}
+
+func NewSyncedLyricsRepository(db Querier) SyncedLyricsRepository {
+	return &syncedLyricsRepository{
+		db: db,
+	}
+}
+
+// Сохранение синхронизированного текста: строки заменяются целиком в одной транзакции
+func (r *syncedLyricsRepository) SaveSyncedLyrics(ctx context.Context, l models.SyncedLyrics) error {
+	tags, err := json.Marshal(l.Tags)
+	if err != nil {
+		return err
+	}
+
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO song_synced_lyrics (song_id, offset_ms, tags) VALUES ($1, $2, $3)
+              ON CONFLICT (song_id) DO UPDATE SET offset_ms = EXCLUDED.offset_ms, tags = EXCLUDED.tags, updated_at = NOW()`
+	if _, err := tx.ExecContext(ctx, query, l.SongID, l.OffsetMs, tags); err != nil {
+		// Нарушение внешнего ключа — песни с таким идентификатором нет
+		if isForeignKeyViolation(err) {
+			return sql.ErrNoRows
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM song_synced_lines WHERE song_id = $1`, l.SongID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO song_synced_lines (song_id, line_no, time_ms, text, words) VALUES ($1, $2, $3, $4, $5)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, line := range l.Lines {
+		var words []byte
+		if len(line.Words) > 0 {
+			if words, err = json.Marshal(line.Words); err != nil {
+				return err
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, l.SongID, line.LineNo, line.TimeMs, line.Text, words); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Получение синхронизированного текста песни
+func (r *syncedLyricsRepository) GetSyncedLyrics(ctx context.Context, songID int) (models.SyncedLyrics, error) {
+	l := models.SyncedLyrics{SongID: songID}
+
+	var tags []byte
+	query := `SELECT offset_ms, tags, updated_at FROM song_synced_lyrics WHERE song_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, songID).Scan(&l.OffsetMs, &tags, &l.UpdatedAt); err != nil {
+		return l, err
+	}
+	if err := json.Unmarshal(tags, &l.Tags); err != nil {
+		return l, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT line_no, time_ms, text, words FROM song_synced_lines WHERE song_id = $1 ORDER BY time_ms, line_no`, songID)
+	if err != nil {
+		return l, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line, err := scanSyncedLine(rows)
+		if err != nil {
+			return l, err
+		}
+		l.Lines = append(l.Lines, line)
+	}
+
+	return l, rows.Err()
+}
+
+// Строка, звучащая в момент atMs, и следующая за ней. Время указывается без учета смещения, смещение возвращается отдельно
+func (r *syncedLyricsRepository) GetSyncedLinesAt(ctx context.Context, songID, atMs int) (current, next *models.SyncedLine, offsetMs int, err error) {
+	if err := r.db.QueryRowContext(ctx, `SELECT offset_ms FROM song_synced_lyrics WHERE song_id = $1`, songID).Scan(&offsetMs); err != nil {
+		return nil, nil, 0, err
+	}
+
+	// Положительное смещение показывает строки раньше, поэтому ищем по времени файла atMs + offset
+	query := `(SELECT TRUE, line_no, time_ms, text, words FROM song_synced_lines
+               WHERE song_id = $1 AND time_ms <= $2 ORDER BY time_ms DESC, line_no DESC LIMIT 1)
+              UNION ALL
+              (SELECT FALSE, line_no, time_ms, text, words FROM song_synced_lines
+               WHERE song_id = $1 AND time_ms > $2 ORDER BY time_ms, line_no LIMIT 1)`
+
+	rows, err := r.db.QueryContext(ctx, query, songID, atMs+offsetMs)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var isCurrent bool
+		line, err := scanSyncedLine(rows, &isCurrent)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if isCurrent {
+			current = &line
+		} else {
+			next = &line
+		}
+	}
+
+	return current, next, offsetMs, rows.Err()
+}
+
+// Сдвиг всех строк: положительный shiftMs показывает строки позже. Возвращает новое смещение
+func (r *syncedLyricsRepository) ShiftSyncedLyrics(ctx context.Context, songID, shiftMs int) (int, error) {
+	query := `UPDATE song_synced_lyrics SET offset_ms = offset_ms - $2, updated_at = NOW() WHERE song_id = $1 RETURNING offset_ms`
+
+	var offsetMs int
+	if err := r.db.QueryRowContext(ctx, query, songID, shiftMs).Scan(&offsetMs); err != nil {
+		return 0, err
+	}
+	return offsetMs, nil
+}
+
+// scanSyncedLine читает строку; prefix — дополнительные колонки перед колонками строки
+func scanSyncedLine(row interface{ Scan(...interface{}) error }, prefix ...interface{}) (models.SyncedLine, error) {
+	var line models.SyncedLine
+	var words []byte
+	if err := row.Scan(append(prefix, &line.LineNo, &line.TimeMs, &line.Text, &words)...); err != nil {
+		return line, err
+	}
+	if len(words) > 0 {
+		if err := json.Unmarshal(words, &line.Words); err != nil {
+			return line, err
+		}
+	}
+	return line, nil
+}
@@ -0,0 +1,281 @@
+package postgresrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// ErrTagExists возвращается, когда тег с таким полным именем уже есть
+var ErrTagExists = errors.New("tag already exists")
+
+type tagRepository struct {
+	db Querier
+}
+
+func NewTagRepository(db Querier) TagRepository {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+const tagColumns = `t.id, t.namespace, t.name, t.full_name, t.description, t.created_at,
+       (SELECT COUNT(*) FROM song_tags st WHERE st.tag_id = t.id)`
+
+func scanTag(row interface{ Scan(...interface{}) error }) (models.Tag, error) {
+	var t models.Tag
+	err := row.Scan(&t.ID, &t.Namespace, &t.Name, &t.FullName, &t.Description, &t.CreatedAt, &t.SongCount)
+	return t, err
+}
+
+// Список тегов с числом песен; пустое пространство имен — все теги
+func (r *tagRepository) ListTags(ctx context.Context, namespace string) ([]models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE $1 = '' OR t.namespace = $1 ORDER BY t.full_name`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+// Получение тега по идентификатору
+func (r *tagRepository) GetTag(ctx context.Context, tagID int) (models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags t WHERE t.id = $1`
+
+	return scanTag(r.db.QueryRowContext(ctx, query, tagID))
+}
+
+// Создание тега
+func (r *tagRepository) CreateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+	query := `INSERT INTO tags AS t (namespace, name, description) VALUES ($1, $2, $3) RETURNING ` + tagColumns
+
+	created, err := scanTag(r.db.QueryRowContext(ctx, query, tag.Namespace, tag.Name, tag.Description))
+	if isUniqueViolation(err) {
+		return models.Tag{}, ErrTagExists
+	}
+	return created, err
+}
+
+// Переименование тега и изменение описания; назначения песням сохраняются.
+// При переименовании для каждой песни с тегом пишется событие song.updated
+func (r *tagRepository) UpdateTag(ctx context.Context, tag models.Tag) (models.Tag, error) {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	if err := tx.QueryRowContext(ctx, `SELECT full_name FROM tags WHERE id = $1 FOR UPDATE`, tag.ID).Scan(&oldName); err != nil {
+		return models.Tag{}, err
+	}
+
+	query := `UPDATE tags t SET namespace = $1, name = $2, description = $3 WHERE t.id = $4 RETURNING ` + tagColumns
+
+	updated, err := scanTag(tx.QueryRowContext(ctx, query, tag.Namespace, tag.Name, tag.Description, tag.ID))
+	if isUniqueViolation(err) {
+		return models.Tag{}, ErrTagExists
+	}
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	if updated.FullName != oldName {
+		if _, err := recordTagEvents(ctx, tx, `SELECT song_id FROM song_tags WHERE tag_id = $1`, tag.ID); err != nil {
+			return models.Tag{}, err
+		}
+	}
+
+	return updated, tx.Commit()
+}
+
+// Удаление тега вместе с его назначениями и событиями song.updated для песен, с которых он снят
+func (r *tagRepository) DeleteTag(ctx context.Context, tagID int) error {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Блокировка не дает параллельно назначить тег песне, которая останется без события
+	var id int
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE id = $1 FOR UPDATE`, tagID).Scan(&id); err != nil {
+		return err
+	}
+
+	songIDs, _, err := querySongIDs(ctx, tx, `SELECT song_id FROM song_tags WHERE tag_id = $1`, tagID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, tagID); err != nil {
+		return err
+	}
+	if err := recordSongsUpdated(ctx, tx, songIDs); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Назначение тегов выбранным песням. Недостающие теги создаются; возвращается число новых назначений
+func (r *tagRepository) AssignTags(ctx context.Context, tags []models.Tag, songs models.SongSelection) (int64, error) {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	fullNames := make([]string, len(tags))
+	for i, t := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tags (namespace, name) VALUES ($1, $2) ON CONFLICT (full_name) DO NOTHING`, t.Namespace, t.Name); err != nil {
+			return 0, err
+		}
+		fullNames[i] = t.FullName
+	}
+
+	where, args := songSelectionWhere(songs, []interface{}{pq.Array(fullNames)})
+	query := `INSERT INTO song_tags (song_id, tag_id)
+              SELECT songs.id, t.id FROM songs CROSS JOIN tags t
+              WHERE t.full_name = ANY($1) AND ` + where + `
+              ON CONFLICT DO NOTHING
+              RETURNING song_id`
+
+	assigned, err := recordTagEvents(ctx, tx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return assigned, tx.Commit()
+}
+
+// Снятие тегов с выбранных песен; возвращается число снятых назначений
+func (r *tagRepository) UnassignTags(ctx context.Context, tags []string, songs models.SongSelection) (int64, error) {
+	tx, err := begin(ctx, r.db, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	where, args := songSelectionWhere(songs, []interface{}{pq.Array(tags)})
+	query := `DELETE FROM song_tags st USING tags t, songs
+              WHERE st.tag_id = t.id AND st.song_id = songs.id AND t.full_name = ANY($1) AND ` + where + `
+              RETURNING st.song_id`
+
+	removed, err := recordTagEvents(ctx, tx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return removed, tx.Commit()
+}
+
+// recordTagEvents выполняет запрос, который возвращает по строке на каждое измененное назначение тега,
+// и пишет событие song.updated для каждой затронутой песни. Возвращает число строк
+func recordTagEvents(ctx context.Context, tx Querier, query string, args ...interface{}) (int64, error) {
+	songIDs, changed, err := querySongIDs(ctx, tx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return changed, recordSongsUpdated(ctx, tx, songIDs)
+}
+
+// querySongIDs возвращает различные идентификаторы песен из первой колонки запроса и число строк
+func querySongIDs(ctx context.Context, tx Querier, query string, args ...interface{}) ([]int, int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	var count int64
+	seen := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, 0, err
+		}
+		count++
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, count, rows.Err()
+}
+
+// recordSongsUpdated пишет в outbox событие song.updated с текущим состоянием каждой песни
+func recordSongsUpdated(ctx context.Context, tx Querier, songIDs []int) error {
+	for _, id := range songIDs {
+		song, err := selectSongTx(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+		if err := recordSongEvent(ctx, tx, models.EventSongUpdated, models.EventSourceAPI, song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Число песен с каждым тегом среди отобранных фильтром
+func (r *tagRepository) TagFacets(ctx context.Context, filter models.SongFilter, namespace string) ([]models.TagCount, error) {
+	where, args := songFilterWhere(filter, nil)
+	if namespace != "" {
+		args = append(args, namespace)
+		where += " AND t.namespace = $" + strconv.Itoa(len(args))
+	}
+	query := `SELECT t.full_name, COUNT(*) FROM song_tags st
+              JOIN tags t ON t.id = st.tag_id
+              JOIN songs ON songs.id = st.song_id
+              WHERE ` + where + `
+              GROUP BY t.full_name
+              ORDER BY COUNT(*) DESC, t.full_name`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facets []models.TagCount
+	for rows.Next() {
+		var f models.TagCount
+		if err := rows.Scan(&f.Tag, &f.Count); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+
+	return facets, rows.Err()
+}
+
+// songSelectionWhere строит условие WHERE для таблицы songs по списку идентификаторов или фильтру
+func songSelectionWhere(songs models.SongSelection, args []interface{}) (string, []interface{}) {
+	if songs.Filter != nil {
+		return songFilterWhere(*songs.Filter, args)
+	}
+	args = append(args, pq.Array(songs.SongIDs))
+	return fmt.Sprintf("songs.id = ANY($%d)", len(args)), args
+}
+
+func isUniqueViolation(err error) bool {
+	return sqlState(err) == "23505"
+}
@@ -0,0 +1,156 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Querier — запросы, общие для *sql.DB и *sql.Tx. Репозитории выполняют запросы через него,
+// поэтому одни и те же репозитории работают и с пулом соединений, и внутри WithTx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// repoTx — транзакция или точка сохранения внутри нее. Commit и Rollback точки сохранения
+// освобождают или откатывают только ее, внешняя транзакция продолжается
+type repoTx struct {
+	*sql.Tx
+	depth int // 0 — сама транзакция, иначе уровень вложенности точки сохранения
+	done  bool
+}
+
+// begin начинает транзакцию на q. Если q уже транзакция, вместо новой начинается точка сохранения,
+// поэтому методы репозиториев, привязанных к WithTx, не завершают внешнюю транзакцию.
+// opts действуют только на новую транзакцию: точка сохранения наследует уровень изоляции внешней
+func begin(ctx context.Context, q Querier, opts *sql.TxOptions) (*repoTx, error) {
+	var parent *repoTx
+	switch q := q.(type) {
+	case *sql.DB:
+		t, err := q.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &repoTx{Tx: t}, nil
+	case *repoTx:
+		parent = q
+	case *sql.Tx:
+		parent = &repoTx{Tx: q}
+	default:
+		return nil, fmt.Errorf("cannot begin transaction on %T", q)
+	}
+
+	t := &repoTx{Tx: parent.Tx, depth: parent.depth + 1}
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+t.savepoint()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// savepoint — имя точки сохранения. Имена повторяются на одном уровне вложенности:
+// Postgres освобождает и откатывает последнюю точку с этим именем
+func (t *repoTx) savepoint() string {
+	return fmt.Sprintf("sp_%d", t.depth)
+}
+
+func (t *repoTx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.depth == 0 {
+		return t.Tx.Commit()
+	}
+	_, err := t.ExecContext(context.Background(), "RELEASE SAVEPOINT "+t.savepoint())
+	return err
+}
+
+// Rollback после Commit возвращает sql.ErrTxDone, поэтому его можно откладывать через defer, как у *sql.Tx
+func (t *repoTx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.depth == 0 {
+		return t.Tx.Rollback()
+	}
+	if _, err := t.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+t.savepoint()); err != nil {
+		return err
+	}
+	_, err := t.ExecContext(context.Background(), "RELEASE SAVEPOINT "+t.savepoint())
+	return err
+}
+
+// defaultTxAttempts — сколько раз WithTx выполняет функцию, если транзакция не прошла из-за конфликта
+const defaultTxAttempts = 3
+
+// TxOptions — параметры транзакции WithTxOptions
+type TxOptions struct {
+	Isolation sql.IsolationLevel // по умолчанию READ COMMITTED
+	ReadOnly  bool
+	// Attempts — сколько раз выполнить функцию при ошибке сериализации или взаимной блокировке;
+	// 0 — defaultTxAttempts, 1 — без повторов
+	Attempts int
+}
+
+// WithTx выполняет fn в транзакции READ COMMITTED; см. WithTxOptions
+func (r *Repository) WithTx(ctx context.Context, fn func(r *Repository) error) error {
+	return r.WithTxOptions(ctx, TxOptions{}, fn)
+}
+
+// WithTxOptions выполняет fn с репозиториями, привязанными к одной транзакции: если fn вернула ошибку,
+// все ее изменения откатываются. При ошибке сериализации или взаимной блокировке транзакция повторяется
+// целиком, поэтому fn не должна иметь побочных эффектов вне базы.
+// Вложенный вызов на репозитории из fn использует точку сохранения: его ошибка откатывает только его изменения,
+// параметры и повторы определяет внешний вызов.
+// Каталог без Postgres (NewCatalogRepository) транзакций не поддерживает, fn выполняется без них
+func (r *Repository) WithTxOptions(ctx context.Context, opts TxOptions, fn func(r *Repository) error) error {
+	if r.db == nil {
+		return fn(r)
+	}
+	if _, nested := r.q.(*repoTx); nested {
+		return r.runTx(ctx, nil, fn)
+	}
+
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = defaultTxAttempts
+	}
+	txOpts := &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+	for attempt := 1; ; attempt++ {
+		err := r.runTx(ctx, txOpts, fn)
+		if err == nil || attempt >= attempts || !isRetryableTxError(err) {
+			return err
+		}
+		// Небольшая случайная пауза, чтобы конфликтующие транзакции не столкнулись снова
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt)*10*time.Millisecond + time.Duration(rand.Intn(10))*time.Millisecond):
+		}
+	}
+}
+
+func (r *Repository) runTx(ctx context.Context, opts *sql.TxOptions, fn func(r *Repository) error) error {
+	t, err := begin(ctx, r.q, opts)
+	if err != nil {
+		return err
+	}
+	defer t.Rollback()
+
+	if err := fn(newRepository(t, r.db, r.dsn)); err != nil {
+		return err
+	}
+	return t.Commit()
+}
+
+// isRetryableTxError — транзакция откатилась из-за конфликта с другой и может пройти при повторе
+func isRetryableTxError(err error) bool {
+	// serialization_failure и deadlock_detected
+	code := sqlState(err)
+	return code == "40001" || code == "40P01"
+}
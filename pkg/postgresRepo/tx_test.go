@@ -0,0 +1,243 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// fakeDB — драйвер database/sql, который записывает выполненные команды и возвращает для них заданные ошибки
+type fakeDB struct {
+	mu  sync.Mutex
+	log []string
+	// errs — очередь ошибок команды: очередное выполнение команды возвращает следующую ошибку
+	errs map[string][]error
+}
+
+func (d *fakeDB) exec(query string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, query)
+	if errs := d.errs[query]; len(errs) > 0 {
+		d.errs[query] = errs[1:]
+		return errs[0]
+	}
+	return nil
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error)             { return fakeConn{d}, nil }
+func (d *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDB) Driver() driver.Driver                        { return d }
+
+type fakeConn struct{ d *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c fakeConn) Close() error { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c fakeConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	query := "BEGIN"
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		query += " " + sql.IsolationLevel(opts.Isolation).String()
+	}
+	if err := c.d.exec(query); err != nil {
+		return nil, err
+	}
+	return fakeTx{c.d}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.d.exec(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{ d *fakeDB }
+
+func (t fakeTx) Commit() error   { return t.d.exec("COMMIT") }
+func (t fakeTx) Rollback() error { return t.d.exec("ROLLBACK") }
+
+func newFakeRepository(t *testing.T, errs map[string][]error) (*Repository, *fakeDB) {
+	d := &fakeDB{errs: errs}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return NewRepository(db, ""), d
+}
+
+func exec(ctx context.Context, r *Repository, query string) error {
+	_, err := r.q.ExecContext(ctx, query)
+	return err
+}
+
+func TestWithTx(t *testing.T) {
+	errFn := errors.New("fn failed")
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context, r *Repository) error
+		wantErr error
+		wantLog []string
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, r *Repository) error {
+				return exec(ctx, r, "INSERT a")
+			},
+			wantLog: []string{"BEGIN", "INSERT a", "COMMIT"},
+		},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context, r *Repository) error {
+				if err := exec(ctx, r, "INSERT a"); err != nil {
+					return err
+				}
+				return errFn
+			},
+			wantErr: errFn,
+			wantLog: []string{"BEGIN", "INSERT a", "ROLLBACK"},
+		},
+		{
+			name: "nested call releases its savepoint",
+			fn: func(ctx context.Context, r *Repository) error {
+				return r.WithTx(ctx, func(r *Repository) error {
+					return exec(ctx, r, "INSERT a")
+				})
+			},
+			wantLog: []string{"BEGIN", "SAVEPOINT sp_1", "INSERT a", "RELEASE SAVEPOINT sp_1", "COMMIT"},
+		},
+		{
+			name: "failed nested call rolls back only its savepoint",
+			fn: func(ctx context.Context, r *Repository) error {
+				if err := exec(ctx, r, "INSERT a"); err != nil {
+					return err
+				}
+				err := r.WithTx(ctx, func(r *Repository) error {
+					if err := exec(ctx, r, "INSERT b"); err != nil {
+						return err
+					}
+					return errFn
+				})
+				if !errors.Is(err, errFn) {
+					return errors.New("nested error was lost")
+				}
+				return exec(ctx, r, "INSERT c")
+			},
+			wantLog: []string{
+				"BEGIN", "INSERT a",
+				"SAVEPOINT sp_1", "INSERT b", "ROLLBACK TO SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1",
+				"INSERT c", "COMMIT",
+			},
+		},
+		{
+			name: "savepoints are numbered by depth",
+			fn: func(ctx context.Context, r *Repository) error {
+				return r.WithTx(ctx, func(r *Repository) error {
+					return r.WithTx(ctx, func(r *Repository) error {
+						return errFn
+					})
+				})
+			},
+			wantErr: errFn,
+			wantLog: []string{
+				"BEGIN", "SAVEPOINT sp_1", "SAVEPOINT sp_2",
+				"ROLLBACK TO SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2",
+				"ROLLBACK TO SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1",
+				"ROLLBACK",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, d := newFakeRepository(t, nil)
+			ctx := context.Background()
+			err := r.WithTx(ctx, func(r *Repository) error {
+				return tt.fn(ctx, r)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(d.log, tt.wantLog) {
+				t.Fatalf("statements = %q, want %q", d.log, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestWithTxOptionsRetry(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	unique := &pq.Error{Code: "23505"}
+
+	tests := []struct {
+		name      string
+		attempts  int
+		errs      map[string][]error
+		wantCalls int
+		wantState string
+	}{
+		{name: "serialization failure on commit", errs: map[string][]error{"COMMIT": {serialization}}, wantCalls: 2},
+		{name: "deadlock in statement", errs: map[string][]error{"UPDATE a": {deadlock, deadlock}}, wantCalls: 3},
+		{name: "attempts exhausted", attempts: 2, errs: map[string][]error{"COMMIT": {serialization, serialization}}, wantCalls: 2, wantState: "40001"},
+		{name: "single attempt", attempts: 1, errs: map[string][]error{"COMMIT": {serialization}}, wantCalls: 1, wantState: "40001"},
+		{name: "other errors are not retried", errs: map[string][]error{"UPDATE a": {unique}}, wantCalls: 1, wantState: "23505"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, d := newFakeRepository(t, tt.errs)
+			ctx := context.Background()
+
+			calls := 0
+			opts := TxOptions{Isolation: sql.LevelSerializable, Attempts: tt.attempts}
+			err := r.WithTxOptions(ctx, opts, func(r *Repository) error {
+				calls++
+				return exec(ctx, r, "UPDATE a")
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			if got := sqlState(err); got != tt.wantState {
+				t.Errorf("WithTxOptions() error = %v, want SQLSTATE %q", err, tt.wantState)
+			}
+			// Каждая попытка — новая транзакция с заданным уровнем изоляции
+			begins := 0
+			for _, q := range d.log {
+				if q == "BEGIN" {
+					t.Errorf("transaction started without isolation level: %q", d.log)
+				}
+				if q == "BEGIN "+sql.LevelSerializable.String() {
+					begins++
+				}
+			}
+			if begins != tt.wantCalls {
+				t.Errorf("started %d transactions, want %d: %q", begins, tt.wantCalls, d.log)
+			}
+		})
+	}
+}
+
+func TestWithTxWithoutDatabase(t *testing.T) {
+	// Каталог без Postgres выполняет функцию без транзакции
+	r := NewCatalogRepository(nil)
+	calls := 0
+	err := r.WithTxOptions(context.Background(), TxOptions{Isolation: sql.LevelSerializable}, func(got *Repository) error {
+		calls++
+		if got != r {
+			t.Error("fn received a different repository")
+		}
+		return nil
+	})
+	if err != nil || calls != 1 {
+		t.Fatalf("WithTxOptions() = %v after %d calls, want nil after 1", err, calls)
+	}
+}
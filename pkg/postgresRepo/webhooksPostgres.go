@@ -0,0 +1,230 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"musPlayer/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type webhookRepository struct {
+	db Querier
+}
+
+func NewWebhookRepository(db Querier) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+// recordSongEvent пишет событие песни в outbox и ставит его доставку активным подпискам.
+// Вызывается в транзакции, изменившей песню, чтобы событие не терялось и не появлялось без изменения
+func recordSongEvent(ctx context.Context, tx Querier, eventType, source string, song models.Song) error {
+	payload, err := json.Marshal(models.SongEventData{Song: song, Source: source})
+	if err != nil {
+		return err
+	}
+
+	query := `WITH event AS (
+                  INSERT INTO outbox_events (event_type, song_id, payload) VALUES ($1, $2, $3) RETURNING id
+              )
+              INSERT INTO webhook_deliveries (event_id, subscription_id)
+              SELECT event.id, s.id FROM event, webhook_subscriptions s
+              WHERE s.active AND (cardinality(s.events) = 0 OR $1 = ANY(s.events))`
+	_, err = tx.ExecContext(ctx, query, eventType, song.ID, payload)
+	return err
+}
+
+// selectSongTx читает песню в транзакции; lock блокирует строку до конца транзакции
+func selectSongTx(ctx context.Context, tx Querier, songID int, lock bool) (models.Song, error) {
+	query := `SELECT ` + songColumns + ` FROM songs WHERE songs.id = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	var song models.Song
+	err := scanSong(tx.QueryRowContext(ctx, query, songID), &song)
+	return song, err
+}
+
+const webhookColumns = `id, url, secret, events, active, description, created_at, updated_at`
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (models.WebhookSubscription, error) {
+	var w models.WebhookSubscription
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, pq.Array(&w.Events), &w.Active, &w.Description, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+// Список подписок
+func (r *webhookRepository) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.WebhookSubscription
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, w)
+	}
+
+	return subs, rows.Err()
+}
+
+// Получение подписки
+func (r *webhookRepository) GetWebhook(ctx context.Context, id int) (models.WebhookSubscription, error) {
+	return scanWebhook(r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+}
+
+// Создание подписки
+func (r *webhookRepository) CreateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error) {
+	query := `INSERT INTO webhook_subscriptions (url, secret, events, active, description) VALUES ($1, $2, $3, $4, $5)
+              RETURNING ` + webhookColumns
+
+	return scanWebhook(r.db.QueryRowContext(ctx, query, w.URL, w.Secret, pq.Array(w.Events), w.Active, w.Description))
+}
+
+// Изменение подписки; пустой секрет оставляет прежний
+func (r *webhookRepository) UpdateWebhook(ctx context.Context, w models.WebhookSubscription) (models.WebhookSubscription, error) {
+	query := `UPDATE webhook_subscriptions
+              SET url = $2, secret = COALESCE(NULLIF($3, ''), secret), events = $4, active = $5, description = $6, updated_at = NOW()
+              WHERE id = $1
+              RETURNING ` + webhookColumns
+
+	return scanWebhook(r.db.QueryRowContext(ctx, query, w.ID, w.URL, w.Secret, pq.Array(w.Events), w.Active, w.Description))
+}
+
+// Удаление подписки вместе с ее доставками
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+const deliveryColumns = `d.id, d.subscription_id, d.event_id, e.event_type, e.song_id, d.status, d.attempts,
+       CASE WHEN d.status IN ('pending', 'failed') THEN d.next_attempt_at END,
+       d.last_attempt_at, d.response_code, COALESCE(d.last_error, ''), d.delivered_at, d.created_at`
+
+func scanDelivery(row interface{ Scan(...interface{}) error }) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.SongID, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+	return d, err
+}
+
+// Журнал доставок подписки от новых к старым; пустой статус — все доставки
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit, offset int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + `
+              FROM webhook_deliveries d JOIN outbox_events e ON e.id = d.event_id
+              WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2)
+              ORDER BY d.id DESC
+              LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Повторная доставка: доставка снова ставится в очередь с полным числом попыток
+func (r *webhookRepository) Redeliver(ctx context.Context, deliveryID int64) (models.WebhookDelivery, error) {
+	query := `WITH d AS (
+                  UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+                  WHERE id = $1
+                  RETURNING *
+              )
+              SELECT ` + deliveryColumns + ` FROM d JOIN outbox_events e ON e.id = d.event_id`
+
+	return scanDelivery(r.db.QueryRowContext(ctx, query, deliveryID))
+}
+
+// Выборка доставок, которым пора уходить. Взятые доставки откладываются на lease, чтобы их не взял
+// другой экземпляр сервиса; если процесс упадет, доставка повторится после lease
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.PendingDelivery, error) {
+	query := `WITH due AS (
+                  SELECT d.id FROM webhook_deliveries d
+                  JOIN webhook_subscriptions s ON s.id = d.subscription_id
+                  WHERE d.status IN ('pending', 'failed') AND d.next_attempt_at <= NOW() AND s.active
+                  ORDER BY d.next_attempt_at, d.id
+                  LIMIT $1
+                  FOR UPDATE OF d SKIP LOCKED
+              )
+              UPDATE webhook_deliveries d SET next_attempt_at = NOW() + make_interval(secs => $2)
+              FROM due, webhook_subscriptions s, outbox_events e
+              WHERE d.id = due.id AND s.id = d.subscription_id AND e.id = d.event_id
+              RETURNING d.id, d.attempts, s.url, s.secret, e.id, e.event_type, e.created_at, e.payload`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []models.PendingDelivery
+	for rows.Next() {
+		var p models.PendingDelivery
+		if err := rows.Scan(&p.ID, &p.Attempts, &p.URL, &p.Secret, &p.Event.ID, &p.Event.Type, &p.Event.CreatedAt, &p.Event.Data); err != nil {
+			return nil, err
+		}
+		batch = append(batch, p)
+	}
+
+	return batch, rows.Err()
+}
+
+// Запись результата попытки доставки
+func (r *webhookRepository) RecordAttempt(ctx context.Context, a models.DeliveryAttempt) error {
+	query := `UPDATE webhook_deliveries
+              SET status = $2, attempts = attempts + 1, last_attempt_at = NOW(), response_code = $3,
+                  last_error = NULLIF($4, ''),
+                  next_attempt_at = CASE WHEN $2 = 'failed' THEN NOW() + make_interval(secs => $5) ELSE next_attempt_at END,
+                  delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END
+              WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, a.DeliveryID, a.Status, a.ResponseCode, a.Error, a.RetryAfter.Seconds())
+	return err
+}
+
+// Удаление событий старше before, доставки которых завершены
+func (r *webhookRepository) PurgeEvents(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM outbox_events e
+              WHERE e.created_at < $1
+                AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.event_id = e.id AND d.status IN ('pending', 'failed'))`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,488 @@
+// Package repotest проверяет, что реализации репозиториев ведут себя одинаково.
+// Тесты каждой реализации вызывают RunSongRepositoryChecks со своим репозиторием
+package repotest
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// check — одна проверка контракта SongRepository
+type check struct {
+	name string
+	run  func(ctx context.Context, repo postgresrepo.SongRepository) error
+}
+
+// songRepositoryChecks — проверки контракта SongRepository. Каждая проверка создает песни исполнителей
+// с уникальным префиксом и удаляет их за собой, поэтому проверки можно запускать на базе, где уже есть данные
+var songRepositoryChecks = []check{
+	{"add and read back", checkAddSong},
+	{"filter by artist and title", checkFilter},
+	{"filter by ids", checkFilterIDs},
+	{"sort and paginate", checkSort},
+	{"tag filters on untagged songs", checkTags},
+	{"update", checkUpdate},
+	{"delete", checkDelete},
+	{"songs by artists", checkArtists},
+	{"concurrent writes", checkConcurrentWrites},
+}
+
+// RunSongRepositoryChecks выполняет каждую проверку контракта как подтест t
+func RunSongRepositoryChecks(t *testing.T, repo postgresrepo.SongRepository) {
+	t.Helper()
+	for _, c := range songRepositoryChecks {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.run(context.Background(), repo); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// fixture создает песни проверки и удаляет их по завершении
+type fixture struct {
+	repo   postgresrepo.SongRepository
+	prefix string
+
+	mu  sync.Mutex
+	ids []int
+}
+
+func newFixture(repo postgresrepo.SongRepository) *fixture {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return &fixture{repo: repo, prefix: "conformance " + hex.EncodeToString(b) + " "}
+}
+
+// add добавляет песню исполнителя prefix+group
+func (f *fixture) add(ctx context.Context, p postgresrepo.AddSongParams) (int, error) {
+	p.GroupName = f.prefix + p.GroupName
+	id, err := f.repo.AddSong(ctx, p)
+	if err != nil {
+		return 0, fmt.Errorf("AddSong: %w", err)
+	}
+	f.mu.Lock()
+	f.ids = append(f.ids, id)
+	f.mu.Unlock()
+	return id, nil
+}
+
+func (f *fixture) addAll(ctx context.Context, songs ...postgresrepo.AddSongParams) ([]int, error) {
+	ids := make([]int, len(songs))
+	for i, p := range songs {
+		id, err := f.add(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (f *fixture) cleanup(ctx context.Context) {
+	for _, id := range f.ids {
+		_ = f.repo.DeleteSong(ctx, int64(id))
+	}
+}
+
+// songs — песни проверки по фильтру; исполнитель всегда ограничен префиксом проверки
+func (f *fixture) songs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
+	filter.Group = f.prefix + filter.Group
+	songs, err := f.repo.GetSongs(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetSongs(%+v): %w", filter, err)
+	}
+	return songs, nil
+}
+
+func songIDs(songs []models.Song) []int {
+	result := make([]int, len(songs))
+	for i, s := range songs {
+		result[i] = s.ID
+	}
+	return result
+}
+
+// expectIDs сравнивает идентификаторы песен с ожидаемыми с учетом порядка
+func expectIDs(what string, songs []models.Song, want ...int) error {
+	got := songIDs(songs)
+	if len(got) == 0 && len(want) == 0 {
+		return nil
+	}
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%s: got ids %v, want %v", what, got, want)
+	}
+	return nil
+}
+
+func checkAddSong(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	p := postgresrepo.AddSongParams{
+		GroupName:   "Artist",
+		SongId:      42,
+		SongName:    "Title",
+		Text:        "first line\\nsecond line",
+		ReleaseDate: "2001-02-03",
+		Album:       "Album",
+		Link:        "https://genius.com/artist-title-lyrics",
+	}
+	ids, err := f.addAll(ctx, p, p)
+	if err != nil {
+		return err
+	}
+	if ids[0] <= 0 || ids[1] <= ids[0] {
+		return fmt.Errorf("ids must be positive and increasing, got %v", ids)
+	}
+
+	songs, err := f.songs(ctx, models.SongFilter{IDs: ids[:1]}, 10, 0)
+	if err != nil {
+		return err
+	}
+	if len(songs) != 1 {
+		return fmt.Errorf("got %d songs by id, want 1", len(songs))
+	}
+	want := models.Song{
+		ID:          ids[0],
+		GroupName:   f.prefix + p.GroupName,
+		SongName:    p.SongName,
+		ReleaseDate: p.ReleaseDate,
+		Album:       p.Album,
+		Text:        p.Text,
+		Link:        p.Link,
+	}
+	got := songs[0]
+	if len(got.Tags) != 0 || got.RatingAvg != nil || got.RatingCount != 0 {
+		return fmt.Errorf("new song has tags %v, rating %v, rating count %d", got.Tags, got.RatingAvg, got.RatingCount)
+	}
+	got.Tags = nil
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("got %+v, want %+v", got, want)
+	}
+
+	text, err := repo.GetSongText(ctx, ids[0])
+	if err != nil {
+		return fmt.Errorf("GetSongText: %w", err)
+	}
+	if text != p.Text {
+		return fmt.Errorf("GetSongText: got %q, want %q", text, p.Text)
+	}
+	return nil
+}
+
+func checkFilter(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	ids, err := f.addAll(ctx,
+		postgresrepo.AddSongParams{GroupName: "Alpha", SongName: "Song One"},
+		postgresrepo.AddSongParams{GroupName: "alphabet", SongName: "Другая Песня"},
+		postgresrepo.AddSongParams{GroupName: "Beta", SongName: "Song Two"},
+	)
+	if err != nil {
+		return err
+	}
+
+	cases := []struct {
+		filter models.SongFilter
+		want   []int
+	}{
+		{models.SongFilter{}, ids},
+		{models.SongFilter{Group: "ALPHA"}, ids[:2]},
+		{models.SongFilter{Group: "alphab"}, ids[1:2]},
+		{models.SongFilter{Song: "song"}, []int{ids[0], ids[2]}},
+		{models.SongFilter{Song: "песня"}, ids[1:2]},
+		{models.SongFilter{Group: "beta", Song: "one"}, nil},
+	}
+	for _, c := range cases {
+		songs, err := f.songs(ctx, c.filter, 10, 0)
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(fmt.Sprintf("group %q, song %q", c.filter.Group, c.filter.Song), songs, c.want...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkFilterIDs(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	p := postgresrepo.AddSongParams{GroupName: "Artist", SongName: "Title"}
+	ids, err := f.addAll(ctx, p, p, p)
+	if err != nil {
+		return err
+	}
+
+	songs, err := f.songs(ctx, models.SongFilter{IDs: []int{ids[2], ids[0]}}, 10, 0)
+	if err != nil {
+		return err
+	}
+	if err := expectIDs("ids filter", songs, ids[0], ids[2]); err != nil {
+		return err
+	}
+
+	songs, err = f.songs(ctx, models.SongFilter{AfterID: ids[0]}, 10, 0)
+	if err != nil {
+		return err
+	}
+	return expectIDs("after_id filter", songs, ids[1:]...)
+}
+
+func checkSort(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	ids, err := f.addAll(ctx,
+		postgresrepo.AddSongParams{GroupName: "b", SongName: "b", ReleaseDate: "2003"},
+		postgresrepo.AddSongParams{GroupName: "A", SongName: "A", ReleaseDate: "2001"},
+		postgresrepo.AddSongParams{GroupName: "c", SongName: "c", ReleaseDate: ""},
+		postgresrepo.AddSongParams{GroupName: "a", SongName: "a", ReleaseDate: "2001"},
+	)
+	if err != nil {
+		return err
+	}
+
+	cases := []struct {
+		sort          string
+		limit, offset int
+		want          []int
+	}{
+		{"", 10, 0, ids},
+		{"-id", 10, 0, []int{ids[3], ids[2], ids[1], ids[0]}},
+		{"group", 10, 0, []int{ids[1], ids[3], ids[0], ids[2]}},
+		{"-song", 10, 0, []int{ids[2], ids[0], ids[1], ids[3]}},
+		{"release_date", 10, 0, []int{ids[2], ids[1], ids[3], ids[0]}},
+		{"-release_date", 10, 0, []int{ids[0], ids[1], ids[3], ids[2]}},
+		// Оценок нет ни у одной песни: значения NULL равны, порядок определяется id
+		{"-rating", 10, 0, ids},
+		{"rating_count", 10, 0, ids},
+		{"id", 2, 1, ids[1:3]},
+		{"-id", 2, 3, ids[:1]},
+		{"id", 0, 0, nil},
+		{"id", 10, 4, nil},
+	}
+	for _, c := range cases {
+		songs, err := f.songs(ctx, models.SongFilter{Sort: c.sort}, c.limit, c.offset)
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(fmt.Sprintf("sort %q, limit %d, offset %d", c.sort, c.limit, c.offset), songs, c.want...); err != nil {
+			return err
+		}
+	}
+
+	if _, err := repo.GetSongs(ctx, models.SongFilter{Sort: "title"}, 10, 0); !errors.Is(err, postgresrepo.ErrInvalidSort) {
+		return fmt.Errorf("unknown sort field: got error %v, want ErrInvalidSort", err)
+	}
+	return nil
+}
+
+func checkTags(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	id, err := f.add(ctx, postgresrepo.AddSongParams{GroupName: "Artist", SongName: "Title"})
+	if err != nil {
+		return err
+	}
+
+	tags := []string{"genre:rock"}
+	cases := []struct {
+		name   string
+		filter models.SongFilter
+		want   []int
+	}{
+		{"tags_all", models.SongFilter{TagsAll: tags}, nil},
+		{"tags_any", models.SongFilter{TagsAny: tags}, nil},
+		{"tags_none", models.SongFilter{TagsNone: tags}, []int{id}},
+	}
+	for _, c := range cases {
+		songs, err := f.songs(ctx, c.filter, 10, 0)
+		if err != nil {
+			return err
+		}
+		if err := expectIDs(c.name, songs, c.want...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkUpdate(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	ids, err := f.addAll(ctx,
+		postgresrepo.AddSongParams{GroupName: "Artist", SongName: "Title", Text: "old", ReleaseDate: "2001", Album: "Album", Link: "link"},
+		postgresrepo.AddSongParams{GroupName: "Artist", SongName: "Other", Text: "other"},
+	)
+	if err != nil {
+		return err
+	}
+
+	upd := models.SongUpdateParams{ID: ids[0], GroupName: f.prefix + "Renamed", SongName: "New title", ReleaseDate: "2002", Text: "new"}
+	if err := repo.UpdateSong(ctx, upd); err != nil {
+		return fmt.Errorf("UpdateSong: %w", err)
+	}
+
+	songs, err := f.songs(ctx, models.SongFilter{}, 10, 0)
+	if err != nil {
+		return err
+	}
+	if len(songs) != 2 {
+		return fmt.Errorf("got %d songs after update, want 2", len(songs))
+	}
+	got := songs[0]
+	if got.GroupName != upd.GroupName || got.SongName != upd.SongName || got.ReleaseDate != upd.ReleaseDate || got.Text != upd.Text {
+		return fmt.Errorf("updated song is %+v, want fields of %+v", got, upd)
+	}
+	if got.Album != "Album" || got.Link != "link" {
+		return fmt.Errorf("update changed album %q or link %q", got.Album, got.Link)
+	}
+	if songs[1].Text != "other" {
+		return fmt.Errorf("update changed another song: %+v", songs[1])
+	}
+	if text, err := repo.GetSongText(ctx, ids[0]); err != nil || text != "new" {
+		return fmt.Errorf("GetSongText after update: got %q, %v", text, err)
+	}
+
+	// Несуществующий id берется у только что удаленной песни, чтобы не задеть чужие данные
+	if err := repo.DeleteSong(ctx, int64(ids[1])); err != nil {
+		return fmt.Errorf("DeleteSong: %w", err)
+	}
+	upd.ID = ids[1]
+	if err := repo.UpdateSong(ctx, upd); !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("update of a missing song: got error %v, want sql.ErrNoRows", err)
+	}
+	return nil
+}
+
+func checkDelete(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	p := postgresrepo.AddSongParams{GroupName: "Artist", SongName: "Title", Text: "text"}
+	ids, err := f.addAll(ctx, p, p)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.DeleteSong(ctx, int64(ids[0])); err != nil {
+		return fmt.Errorf("DeleteSong: %w", err)
+	}
+	songs, err := f.songs(ctx, models.SongFilter{}, 10, 0)
+	if err != nil {
+		return err
+	}
+	if err := expectIDs("songs after delete", songs, ids[1]); err != nil {
+		return err
+	}
+	if _, err := repo.GetSongText(ctx, ids[0]); !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("GetSongText of a deleted song: got error %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.DeleteSong(ctx, int64(ids[0])); !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("second delete: got error %v, want sql.ErrNoRows", err)
+	}
+	return nil
+}
+
+func checkArtists(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	ids, err := f.addAll(ctx,
+		postgresrepo.AddSongParams{GroupName: "Кино", SongName: "1", Text: "one"},
+		postgresrepo.AddSongParams{GroupName: "Other", SongName: "2", Text: "two"},
+		postgresrepo.AddSongParams{GroupName: "КИНО", SongName: "3", Text: "three"},
+		postgresrepo.AddSongParams{GroupName: "кино", SongName: "4", Text: "four"},
+	)
+	if err != nil {
+		return err
+	}
+
+	kino, other, missing := f.prefix+"кИнО", f.prefix+"other", f.prefix+"missing"
+	cases := []struct {
+		limit, offset int
+		want          map[string][]int
+	}{
+		{10, 0, map[string][]int{kino: {ids[0], ids[2], ids[3]}, other: {ids[1]}}},
+		{2, 1, map[string][]int{kino: {ids[2], ids[3]}}},
+		{1, 0, map[string][]int{kino: {ids[0]}, other: {ids[1]}}},
+	}
+	for _, c := range cases {
+		songs, err := repo.GetSongsByArtists(ctx, []string{kino, other, missing}, c.limit, c.offset)
+		if err != nil {
+			return fmt.Errorf("GetSongsByArtists: %w", err)
+		}
+		got := make(map[string][]int, len(songs))
+		for name, list := range songs {
+			got[name] = songIDs(list)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			return fmt.Errorf("GetSongsByArtists limit %d, offset %d: got %v, want %v", c.limit, c.offset, got, c.want)
+		}
+	}
+
+	texts, err := repo.GetArtistTexts(ctx, kino)
+	if err != nil {
+		return fmt.Errorf("GetArtistTexts: %w", err)
+	}
+	if want := []string{"one", "three", "four"}; !reflect.DeepEqual(texts, want) {
+		return fmt.Errorf("GetArtistTexts: got %q, want %q", texts, want)
+	}
+	// Исполнитель сравнивается целиком, а не как подстрока
+	if texts, err := repo.GetArtistTexts(ctx, f.prefix+"кин"); err != nil || len(texts) != 0 {
+		return fmt.Errorf("GetArtistTexts of a name prefix: got %q, %v", texts, err)
+	}
+	return nil
+}
+
+func checkConcurrentWrites(ctx context.Context, repo postgresrepo.SongRepository) error {
+	f := newFixture(repo)
+	defer f.cleanup(ctx)
+
+	const writers = 20
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := f.add(ctx, postgresrepo.AddSongParams{GroupName: "Artist", SongName: fmt.Sprint(i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	songs, err := f.songs(ctx, models.SongFilter{}, 2*writers, 0)
+	if err != nil {
+		return err
+	}
+	seen := make(map[int]bool, len(songs))
+	for _, s := range songs {
+		seen[s.ID] = true
+	}
+	if len(songs) != writers || len(seen) != writers {
+		return fmt.Errorf("got %d songs with %d distinct ids, want %d", len(songs), len(seen), writers)
+	}
+	return nil
+}
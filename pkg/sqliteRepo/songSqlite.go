@@ -0,0 +1,238 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"musPlayer/models"
+	postgresrepo "musPlayer/pkg/postgresRepo"
+	"strings"
+)
+
+// errNegativePage повторяет ошибку Postgres: в SQLite отрицательный LIMIT означает «без ограничения»
+var errNegativePage = errors.New("limit and offset must not be negative")
+
+// songRepository хранит каталог песен в SQLite и повторяет поведение songRepository из postgresRepo.
+// Тегов и оценок в SQLite нет, поэтому песни всегда без тегов и без средней оценки
+type songRepository struct {
+	db *sql.DB
+}
+
+func NewSongRepository(db *sql.DB) postgresrepo.SongRepository {
+	return &songRepository{
+		db: db,
+	}
+}
+
+func (r *songRepository) AddSong(ctx context.Context, song postgresrepo.AddSongParams) (int, error) {
+	query := `INSERT INTO songs (song_id, group_name, song_name, text, release_date, album, link) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	var id int
+	err := r.db.QueryRowContext(ctx, query, song.SongId, song.GroupName, song.SongName, song.Text, song.ReleaseDate, song.Album, song.Link).Scan(&id)
+	return id, err
+}
+
+func (r *songRepository) GetSongText(ctx context.Context, songID int) (string, error) {
+	var text string
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(text, '') FROM songs WHERE id = ?`, songID).Scan(&text)
+	return text, err
+}
+
+func (r *songRepository) GetArtistTexts(ctx context.Context, groupName string) ([]string, error) {
+	query := `SELECT COALESCE(text, '') FROM songs WHERE unicode_lower(group_name) = unicode_lower(?) ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, groupName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+	return texts, rows.Err()
+}
+
+// Колонки песни для списков; порядок совпадает с scanSong
+const songColumns = `songs.id, songs.group_name, songs.song_name, COALESCE(songs.text, ''), COALESCE(songs.release_date, ''),
+       COALESCE(songs.album, ''), COALESCE(songs.link, ''), songs.rating_avg, songs.rating_count`
+
+func scanSong(row interface{ Scan(...interface{}) error }, song *models.Song, extra ...interface{}) error {
+	dest := append([]interface{}{&song.ID, &song.GroupName, &song.SongName, &song.Text, &song.ReleaseDate, &song.Album, &song.Link,
+		&song.RatingAvg, &song.RatingCount}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	song.Tags = []string{}
+	return nil
+}
+
+func (r *songRepository) GetSongs(ctx context.Context, filter models.SongFilter, limit, offset int) ([]models.Song, error) {
+	orderBy, err := songOrderBy(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 0 || offset < 0 {
+		return nil, errNegativePage
+	}
+	where, args := songFilterWhere(filter)
+	query := fmt.Sprintf(`SELECT %s FROM songs WHERE %s ORDER BY %s LIMIT ? OFFSET ?`, songColumns, where, orderBy)
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songs []models.Song
+	for rows.Next() {
+		var song models.Song
+		if err := scanSong(rows, &song); err != nil {
+			return nil, err
+		}
+		songs = append(songs, song)
+	}
+	return songs, rows.Err()
+}
+
+// Песни нескольких исполнителей одним запросом, как в Postgres: для каждого имени страница limit/offset в порядке id
+func (r *songRepository) GetSongsByArtists(ctx context.Context, names []string, limit, offset int) (map[string][]models.Song, error) {
+	if limit < 0 || offset < 0 {
+		return nil, errNegativePage
+	}
+	songs := make(map[string][]models.Song, len(names))
+	if len(names) == 0 {
+		return songs, nil
+	}
+
+	args := make([]interface{}, 0, len(names)+2)
+	for _, name := range names {
+		args = append(args, name)
+	}
+	query := `WITH req(name) AS (VALUES ` + placeholders(len(names), "(?)") + `)
+              SELECT ` + songColumns + `, songs.artist
+              FROM (SELECT req.name AS artist, songs.*,
+                           ROW_NUMBER() OVER (PARTITION BY req.name ORDER BY songs.id) AS rn
+                      FROM req
+                      JOIN songs ON unicode_lower(songs.group_name) = unicode_lower(req.name)) AS songs
+              WHERE songs.rn > ? AND songs.rn <= ? + ?
+              ORDER BY songs.artist, songs.rn`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, offset, offset, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var song models.Song
+		var artist string
+		if err := scanSong(rows, &song, &artist); err != nil {
+			return nil, err
+		}
+		songs[artist] = append(songs[artist], song)
+	}
+	return songs, rows.Err()
+}
+
+func (r *songRepository) DeleteSong(ctx context.Context, songID int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM songs WHERE id = ?`, songID)
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+func (r *songRepository) UpdateSong(ctx context.Context, updSong models.SongUpdateParams) error {
+	query := `UPDATE songs
+              SET group_name = ?, song_name = ?, text = ?, release_date = ?, updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now')
+              WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, updSong.GroupName, updSong.SongName, updSong.Text, updSong.ReleaseDate, updSong.ID)
+	if err != nil {
+		return err
+	}
+	return requireRow(result)
+}
+
+// requireRow возвращает sql.ErrNoRows, если запрос не затронул ни одной строки
+func requireRow(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Поля сортировки песен и соответствующие им выражения, как в Postgres
+var songSortColumns = map[string]string{
+	"id":           "songs.id",
+	"group":        "unicode_lower(songs.group_name)",
+	"song":         "unicode_lower(songs.song_name)",
+	"release_date": "songs.release_date",
+	"created_at":   "songs.created_at",
+	"rating":       "songs.rating_avg",
+	"rating_count": "songs.rating_count",
+}
+
+// songOrderBy повторяет songOrderBy из postgresRepo: значения NULL в конце, при равенстве — по id
+func songOrderBy(sort string) (string, error) {
+	if sort == "" {
+		sort = "id"
+	}
+	field, desc := strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+
+	column, ok := songSortColumns[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", postgresrepo.ErrInvalidSort, sort)
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+	if field == "id" {
+		return "songs.id " + direction, nil
+	}
+	return column + " " + direction + " NULLS LAST, songs.id", nil
+}
+
+// songFilterWhere повторяет songFilterWhere из postgresRepo; ILIKE заменяет LIKE по строкам в нижнем регистре.
+// Тегов в SQLite нет, поэтому условия tags_all и tags_any ложны, а tags_none выполняется всегда
+func songFilterWhere(f models.SongFilter) (string, []interface{}) {
+	conds := []string{`unicode_lower(songs.group_name) LIKE unicode_lower(?) ESCAPE '\'`}
+	args := []interface{}{"%" + f.Group + "%"}
+
+	if len(f.IDs) > 0 {
+		conds = append(conds, "songs.id IN ("+placeholders(len(f.IDs), "?")+")")
+		for _, id := range f.IDs {
+			args = append(args, id)
+		}
+	}
+	if f.AfterID > 0 {
+		conds = append(conds, "songs.id > ?")
+		args = append(args, f.AfterID)
+	}
+	if f.Song != "" {
+		conds = append(conds, `unicode_lower(songs.song_name) LIKE unicode_lower(?) ESCAPE '\'`)
+		args = append(args, "%"+f.Song+"%")
+	}
+	if len(f.TagsAll) > 0 || len(f.TagsAny) > 0 {
+		conds = append(conds, "FALSE")
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func placeholders(n int, placeholder string) string {
+	return strings.TrimSuffix(strings.Repeat(placeholder+", ", n), ", ")
+}
@@ -0,0 +1,27 @@
+package sqliterepo
+
+import (
+	"musPlayer/pkg/repotest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSongRepository(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "file", path: filepath.Join(t.TempDir(), "songs.db")},
+		{name: "in memory", path: ":memory:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewSqliteDb(tt.path)
+			if err != nil {
+				t.Fatalf("NewSqliteDb: %v", err)
+			}
+			defer db.Close()
+			repotest.RunSongRepositoryChecks(t, NewSongRepository(db))
+		})
+	}
+}
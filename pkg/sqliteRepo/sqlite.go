@@ -0,0 +1,61 @@
+package sqliterepo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"musPlayer/internal/logger"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Схема каталога песен. Миграции в schema/migrations написаны для Postgres, поэтому SQLite создает таблицу сам
+const schema = `
+CREATE TABLE IF NOT EXISTS songs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id INTEGER,
+    group_name TEXT NOT NULL,
+    song_name TEXT NOT NULL,
+    release_date TEXT,
+    text TEXT,
+    link TEXT,
+    album TEXT,
+    rating_avg REAL,
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+`
+
+func init() {
+	// LOWER и LIKE в SQLite меняют регистр только латиницы; unicode_lower повторяет LOWER из Postgres для кириллицы
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []byte:
+			return strings.ToLower(string(v)), nil
+		default:
+			return v, nil
+		}
+	})
+}
+
+// NewSqliteDb открывает файл базы SQLite (":memory:" — база в памяти) и создает в нем схему.
+// Соединение одно: SQLite все равно допускает только одного писателя, а база в памяти у каждого соединения своя
+func NewSqliteDb(path string) (*sql.DB, error) {
+	logger.Logger.Infof("Opening SQLite database %s...", path)
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(context.Background(), schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return db, nil
+}
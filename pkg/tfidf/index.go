@@ -0,0 +1,198 @@
+// Package tfidf — потокобезопасный инкрементальный индекс документов для поиска похожих по косинусной мере TF-IDF
+package tfidf
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Document — документ индекса: частоты терминов и группа (исполнитель) для фильтрации
+type Document struct {
+	ID    int
+	Group string
+	Terms map[string]int
+}
+
+// Match — похожий документ с оценкой от 0 до 1
+type Match struct {
+	ID    int
+	Score float64
+}
+
+// GroupFilter ограничивает результаты по группе исходного документа
+type GroupFilter int
+
+const (
+	AnyGroup GroupFilter = iota
+	SameGroup
+	OtherGroup
+)
+
+// Index хранит документы и обратный индекс термин → документы.
+// IDF не хранится, а считается при запросе, поэтому добавление и удаление документа обходятся в O(число терминов)
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[int]*Document
+	postings map[string]map[int]int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[int]*Document),
+		postings: make(map[string]map[int]int),
+	}
+}
+
+// Upsert добавляет документ или заменяет прежнюю версию
+func (ix *Index) Upsert(doc Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(doc.ID)
+	d := doc
+	ix.docs[doc.ID] = &d
+	for term, count := range doc.Terms {
+		if count <= 0 {
+			continue
+		}
+		p := ix.postings[term]
+		if p == nil {
+			p = make(map[int]int)
+			ix.postings[term] = p
+		}
+		p[doc.ID] = count
+	}
+}
+
+// Remove удаляет документ
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.Terms {
+		p := ix.postings[term]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(ix.postings, term)
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Contains сообщает, есть ли документ в индексе
+func (ix *Index) Contains(id int) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	_, ok := ix.docs[id]
+	return ok
+}
+
+// IDs возвращает идентификаторы всех документов
+func (ix *Index) IDs() []int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	ids := make([]int, 0, len(ix.docs))
+	for id := range ix.docs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Len возвращает число документов
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Similar возвращает до limit документов, самых похожих на документ id. Второе значение — есть ли id в индексе
+func (ix *Index) Similar(id, limit int, filter GroupFilter) ([]Match, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	doc, ok := ix.docs[id]
+	if !ok {
+		return nil, false
+	}
+
+	n := float64(len(ix.docs))
+	idf := func(term string) float64 {
+		// Сглаженный IDF: термин, который есть во всех документах, получает вес 1, а не 0
+		return math.Log((1+n)/(1+float64(len(ix.postings[term])))) + 1
+	}
+
+	// Скалярные произведения с документами, у которых есть общие термины
+	dots := make(map[int]float64)
+	queryNorm := 0.0
+	for term, count := range doc.Terms {
+		w := idf(term)
+		qw := weight(count) * w
+		queryNorm += qw * qw
+		for other, otherCount := range ix.postings[term] {
+			if other == id || !matchesGroup(doc, ix.docs[other], filter) {
+				continue
+			}
+			dots[other] += qw * weight(otherCount) * w
+		}
+	}
+	if queryNorm == 0 {
+		return []Match{}, true
+	}
+	queryNorm = math.Sqrt(queryNorm)
+
+	matches := make([]Match, 0, len(dots))
+	for other, dot := range dots {
+		norm := ix.norm(ix.docs[other], idf)
+		if norm == 0 {
+			continue
+		}
+		matches = append(matches, Match{ID: other, Score: dot / (queryNorm * norm)})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, true
+}
+
+func (ix *Index) norm(doc *Document, idf func(string) float64) float64 {
+	sum := 0.0
+	for term, count := range doc.Terms {
+		w := weight(count) * idf(term)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// weight — сублинейный TF: повторы припева не должны перевешивать остальной текст
+func weight(count int) float64 {
+	if count <= 0 {
+		return 0
+	}
+	return 1 + math.Log(float64(count))
+}
+
+func matchesGroup(doc, other *Document, filter GroupFilter) bool {
+	switch filter {
+	case SameGroup:
+		return doc.Group == other.Group
+	case OtherGroup:
+		return doc.Group != other.Group
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package tfidf
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Upsert(Document{ID: 1, Group: "Кино", Terms: map[string]int{"звезда": 3, "солнце": 2, "город": 1}})
+	ix.Upsert(Document{ID: 2, Group: "Кино", Terms: map[string]int{"звезда": 1, "солнце": 1, "война": 2}})
+	ix.Upsert(Document{ID: 3, Group: "ДДТ", Terms: map[string]int{"осень": 4, "город": 1}})
+	ix.Upsert(Document{ID: 4, Group: "ДДТ", Terms: map[string]int{"звезда": 2, "солнце": 2, "город": 1}})
+	ix.Upsert(Document{ID: 5, Group: "Аквариум", Terms: map[string]int{"поезд": 1}})
+	return ix
+}
+
+func ids(matches []Match) []int {
+	out := make([]int, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func TestSimilar(t *testing.T) {
+	ix := newTestIndex()
+	tests := []struct {
+		name   string
+		id     int
+		limit  int
+		filter GroupFilter
+		want   []int
+	}{
+		{name: "any group", id: 1, filter: AnyGroup, want: []int{4, 2, 3}},
+		{name: "limit", id: 1, limit: 2, filter: AnyGroup, want: []int{4, 2}},
+		{name: "same group", id: 1, filter: SameGroup, want: []int{2}},
+		{name: "other group", id: 1, filter: OtherGroup, want: []int{4, 3}},
+		{name: "no shared terms", id: 5, filter: AnyGroup, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, ok := ix.Similar(tt.id, tt.limit, tt.filter)
+			if !ok {
+				t.Fatalf("document %d is not in the index", tt.id)
+			}
+			if got := ids(matches); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Similar(%d) = %v, want %v", tt.id, got, tt.want)
+			}
+			for _, m := range matches {
+				if m.Score <= 0 || m.Score > 1+1e-9 {
+					t.Errorf("match %d score = %v, want within (0, 1]", m.ID, m.Score)
+				}
+			}
+		})
+	}
+}
+
+func TestSimilarIdenticalDocuments(t *testing.T) {
+	ix := NewIndex()
+	terms := map[string]int{"звезда": 2, "солнце": 1}
+	ix.Upsert(Document{ID: 1, Terms: terms})
+	ix.Upsert(Document{ID: 2, Terms: terms})
+
+	matches, _ := ix.Similar(1, 0, AnyGroup)
+	if len(matches) != 1 || math.Abs(matches[0].Score-1) > 1e-9 {
+		t.Fatalf("Similar() = %v, want document 2 with score 1", matches)
+	}
+}
+
+func TestSimilarMissingDocument(t *testing.T) {
+	if _, ok := newTestIndex().Similar(42, 10, AnyGroup); ok {
+		t.Fatal("Similar reported a missing document as present")
+	}
+}
+
+func TestUpsertReplacesAndRemoveCleansPostings(t *testing.T) {
+	ix := newTestIndex()
+
+	// Новая версия документа 2 больше не содержит общих с 1 терминов
+	ix.Upsert(Document{ID: 2, Group: "Кино", Terms: map[string]int{"война": 1}})
+	matches, _ := ix.Similar(1, 0, SameGroup)
+	if len(matches) != 0 {
+		t.Fatalf("Similar() after replacing = %v, want none", matches)
+	}
+	if _, ok := ix.postings["звезда"][2]; ok {
+		t.Fatal("old terms of the replaced document are still indexed")
+	}
+
+	ix.Remove(2)
+	ix.Remove(2)
+	if ix.Contains(2) || ix.Len() != 4 {
+		t.Fatalf("Contains(2) = %v, Len() = %d after removal", ix.Contains(2), ix.Len())
+	}
+	if _, ok := ix.postings["война"]; ok {
+		t.Fatal("empty posting list was not removed")
+	}
+}
+
+func TestWeight(t *testing.T) {
+	tests := []struct {
+		count int
+		want  float64
+	}{
+		{count: 0, want: 0},
+		{count: -1, want: 0},
+		{count: 1, want: 1},
+		{count: 10, want: 1 + math.Log(10)},
+	}
+	for _, tt := range tests {
+		if got := weight(tt.count); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("weight(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+}
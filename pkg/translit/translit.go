@@ -0,0 +1,118 @@
+// Package translit переводит кириллицу в латиницу по правилам транслитерации
+package translit
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Scheme — схема транслитерации
+type Scheme string
+
+const (
+	// SchemeSimple — упрощенная практическая схема без диакритики
+	SchemeSimple Scheme = "simple"
+	// SchemeGOST — ГОСТ 7.79-2000 (система А), совпадает с ISO 9:1995: одна буква кириллицы — одна буква латиницы
+	SchemeGOST Scheme = "gost"
+	// SchemeScientific — научная транслитерация, принятая в славистике
+	SchemeScientific Scheme = "scientific"
+)
+
+// Schemes — поддерживаемые схемы
+var Schemes = []Scheme{SchemeSimple, SchemeGOST, SchemeScientific}
+
+// simple — практическая транслитерация, близкая к тому, как русские названия пишут латиницей на Genius
+var simple = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+	// Украинские и белорусские буквы
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// iso9 — ГОСТ 7.79-2000 / ISO 9:1995
+var iso9 = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "ë", 'ж': "ž",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "h", 'ц': "c",
+	'ч': "č", 'ш': "š", 'щ': "ŝ", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ", 'э': "è", 'ю': "û",
+	'я': "â",
+	'і': "ì", 'ї': "ï", 'є': "ê", 'ґ': "g̀", 'ў': "ŭ",
+}
+
+// scientific — научная транслитерация
+var scientific = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "ë", 'ж': "ž",
+	'з': "z", 'и': "i", 'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "x", 'ц': "c",
+	'ч': "č", 'ш': "š", 'щ': "šč", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ", 'э': "è", 'ю': "ju",
+	'я': "ja",
+	'і': "i", 'ї': "ji", 'є': "je", 'ґ': "g", 'ў': "ŭ",
+}
+
+// Transliterate транслитерирует строку выбранной схемой
+func Transliterate(s string, scheme Scheme) (string, error) {
+	switch scheme {
+	case SchemeSimple:
+		return apply(s, simple), nil
+	case SchemeGOST:
+		return apply(s, iso9), nil
+	case SchemeScientific:
+		return apply(s, scientific), nil
+	}
+	return "", fmt.Errorf("unknown transliteration scheme %q", scheme)
+}
+
+// Simple транслитерирует строку упрощенной практической схемой. Латиница и прочие символы не меняются,
+// регистр первой буквы сохраняется
+func Simple(s string) string {
+	return apply(s, simple)
+}
+
+func apply(s string, table map[rune]string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	runes := []rune(s)
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		latin, ok := table[lower]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if r != lower && latin != "" {
+			// В словах, написанных капслоком, многобуквенные сочетания тоже пишутся заглавными: ЩИ → SHCHI, БОРЩ → BORSHCH
+			if isUpperAt(runes, i+1) || (!isLetterAt(runes, i+1) && isUpperAt(runes, i-1)) {
+				latin = strings.ToUpper(latin)
+			} else {
+				first := []rune(latin)
+				first[0] = unicode.ToUpper(first[0])
+				latin = string(first)
+			}
+		}
+		b.WriteString(latin)
+	}
+	return b.String()
+}
+
+func isUpperAt(runes []rune, i int) bool {
+	return i >= 0 && i < len(runes) && unicode.IsUpper(runes[i])
+}
+
+func isLetterAt(runes []rune, i int) bool {
+	return i >= 0 && i < len(runes) && unicode.IsLetter(runes[i])
+}
+
+// HasCyrillic сообщает, есть ли в строке кириллические буквы
+func HasCyrillic(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package translit
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		in                       string
+		simple, gost, scientific string
+	}{
+		{in: "Группа крови", simple: "Gruppa krovi", gost: "Gruppa krovi", scientific: "Gruppa krovi"},
+		{in: "щука", simple: "shchuka", gost: "ŝuka", scientific: "ščuka"},
+		{in: "Хорошо", simple: "Khorosho", gost: "Horošo", scientific: "Xorošo"},
+		{in: "цель", simple: "tsel", gost: "celʹ", scientific: "celʹ"},
+		{in: "объём", simple: "obem", gost: "obʺëm", scientific: "obʺëm"},
+		{in: "Юля и Яна", simple: "Yulya i Yana", gost: "Ûlâ i Âna", scientific: "Julja i Jana"},
+		{in: "йод", simple: "yod", gost: "jod", scientific: "jod"},
+		{in: "эхо", simple: "ekho", gost: "èho", scientific: "èxo"},
+		{in: "Київ", simple: "Kiyiv", gost: "Kiïv", scientific: "Kijiv"},
+		// Капслок: многобуквенные сочетания тоже заглавными, в том числе в конце слова
+		{in: "ЩИ", simple: "SHCHI", gost: "ŜI", scientific: "ŠČI"},
+		{in: "БОРЩ!", simple: "BORSHCH!", gost: "BORŜ!", scientific: "BORŠČ!"},
+		{in: "Щ", simple: "Shch", gost: "Ŝ", scientific: "Šč"},
+		// Латиница, цифры и пунктуация не меняются
+		{in: "AC/DC — 1979", simple: "AC/DC — 1979", gost: "AC/DC — 1979", scientific: "AC/DC — 1979"},
+	}
+	for _, tt := range tests {
+		for scheme, want := range map[Scheme]string{SchemeSimple: tt.simple, SchemeGOST: tt.gost, SchemeScientific: tt.scientific} {
+			got, err := Transliterate(tt.in, scheme)
+			if err != nil {
+				t.Fatalf("Transliterate(%q, %s): %v", tt.in, scheme, err)
+			}
+			if got != want {
+				t.Errorf("Transliterate(%q, %s) = %q, want %q", tt.in, scheme, got, want)
+			}
+		}
+	}
+}
+
+func TestTransliterateUnknownScheme(t *testing.T) {
+	if _, err := Transliterate("кино", "bgn"); err == nil {
+		t.Fatal("expected an error for an unknown scheme")
+	}
+}
+
+func TestGOSTIsOneToOne(t *testing.T) {
+	// В системе А каждой букве соответствует ровно один символ латиницы (с диакритикой), кроме ґ
+	seen := make(map[string]rune)
+	for cyr, lat := range iso9 {
+		if prev, ok := seen[lat]; ok {
+			t.Errorf("%q and %q both map to %q", prev, cyr, lat)
+		}
+		seen[lat] = cyr
+		if cyr != 'ґ' && len([]rune(lat)) != 1 {
+			t.Errorf("%q maps to %q, want a single character", cyr, lat)
+		}
+	}
+}
+
+func TestHasCyrillic(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{in: "Кино", want: true},
+		{in: "Kino", want: false},
+		{in: "Mix ё", want: true},
+		{in: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := HasCyrillic(tt.in); got != tt.want {
+			t.Errorf("HasCyrillic(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE provider_cache (
+    key VARCHAR(1024) PRIMARY KEY,
+    value BYTEA NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX provider_cache_expires_at_idx ON provider_cache (expires_at);
@@ -0,0 +1 @@
+ALTER TABLE songs ADD COLUMN album VARCHAR(255);
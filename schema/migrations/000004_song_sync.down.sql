@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS song_revisions;
+
+DROP INDEX IF EXISTS songs_last_synced_at_idx;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS last_synced_at,
+    DROP COLUMN IF EXISTS sync_status,
+    DROP COLUMN IF EXISTS sync_error,
+    DROP COLUMN IF EXISTS content_hash,
+    DROP COLUMN IF EXISTS manual_fields;
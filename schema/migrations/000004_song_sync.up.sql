@@ -0,0 +1,20 @@
+ALTER TABLE songs
+    ADD COLUMN last_synced_at TIMESTAMP,
+    ADD COLUMN sync_status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    ADD COLUMN sync_error TEXT,
+    ADD COLUMN content_hash VARCHAR(64),
+    ADD COLUMN manual_fields TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX songs_last_synced_at_idx ON songs (last_synced_at NULLS FIRST);
+
+CREATE TABLE song_revisions (
+    id SERIAL PRIMARY KEY,
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    source_url VARCHAR(1024),
+    changes JSONB NOT NULL,
+    content_hash VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX song_revisions_song_id_idx ON song_revisions (song_id, created_at);
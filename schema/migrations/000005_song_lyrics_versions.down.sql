@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS songs_original_lyrics ON songs;
+
+DROP FUNCTION IF EXISTS sync_original_lyrics();
+
+DROP TABLE IF EXISTS song_lyrics_versions;
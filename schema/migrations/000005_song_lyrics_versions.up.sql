@@ -0,0 +1,77 @@
+CREATE TABLE song_lyrics_versions (
+    id SERIAL PRIMARY KEY,
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    lang VARCHAR(35) NOT NULL,
+    kind VARCHAR(32) NOT NULL CHECK (kind IN ('original', 'translation', 'transliteration', 'user')),
+    -- Схема транслитерации или автор пользовательской версии; различает версии одного вида на одном языке
+    variant VARCHAR(64) NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    source VARCHAR(64) NOT NULL,
+    source_url VARCHAR(1024),
+    submitted_by VARCHAR(255),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (song_id, lang, kind, variant)
+);
+
+CREATE UNIQUE INDEX song_lyrics_versions_default_idx ON song_lyrics_versions (song_id) WHERE is_default;
+
+-- Оригинал всегда повторяет songs.text: при изменении текста версия-оригинал обновляется,
+-- а сгенерированные из него транслитерации удаляются и строятся заново при следующем запросе
+CREATE FUNCTION sync_original_lyrics() RETURNS trigger AS $$
+DECLARE
+    original_lang VARCHAR(35);
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.text IS NOT DISTINCT FROM OLD.text THEN
+        RETURN NEW;
+    END IF;
+
+    DELETE FROM song_lyrics_versions WHERE song_id = NEW.id AND source = 'translit';
+
+    IF COALESCE(NEW.text, '') = '' THEN
+        DELETE FROM song_lyrics_versions WHERE song_id = NEW.id AND kind = 'original';
+        RETURN NEW;
+    END IF;
+
+    original_lang := CASE
+        WHEN NEW.text ~ '[іїєґІЇЄҐ]' THEN 'uk'
+        WHEN NEW.text ~ '[а-яёА-ЯЁ]' THEN 'ru'
+        ELSE 'en'
+    END;
+
+    DELETE FROM song_lyrics_versions WHERE song_id = NEW.id AND kind = 'original' AND lang <> original_lang;
+
+    INSERT INTO song_lyrics_versions (song_id, lang, kind, text, source, source_url, is_default)
+    VALUES (
+        NEW.id, original_lang, 'original', NEW.text,
+        CASE WHEN 'text' = ANY(NEW.manual_fields) OR COALESCE(NEW.link, '') = '' THEN 'manual' ELSE 'genius' END,
+        NULLIF(NEW.link, ''),
+        NOT EXISTS (SELECT 1 FROM song_lyrics_versions WHERE song_id = NEW.id AND is_default)
+    )
+    ON CONFLICT (song_id, lang, kind, variant) DO UPDATE
+        SET text = EXCLUDED.text, source = EXCLUDED.source, source_url = EXCLUDED.source_url, updated_at = NOW();
+
+    -- Если прежний оригинал был версией по умолчанию и удален, по умолчанию становится новый
+    UPDATE song_lyrics_versions SET is_default = TRUE
+    WHERE song_id = NEW.id AND kind = 'original'
+      AND NOT EXISTS (SELECT 1 FROM song_lyrics_versions WHERE song_id = NEW.id AND is_default);
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_original_lyrics
+    AFTER INSERT OR UPDATE OF text ON songs
+    FOR EACH ROW EXECUTE FUNCTION sync_original_lyrics();
+
+-- Оригиналы для уже сохраненных песен
+INSERT INTO song_lyrics_versions (song_id, lang, kind, text, source, source_url, is_default)
+SELECT id,
+       CASE WHEN text ~ '[іїєґІЇЄҐ]' THEN 'uk' WHEN text ~ '[а-яёА-ЯЁ]' THEN 'ru' ELSE 'en' END,
+       'original', text,
+       CASE WHEN 'text' = ANY(manual_fields) OR COALESCE(link, '') = '' THEN 'manual' ELSE 'genius' END,
+       NULLIF(link, ''),
+       TRUE
+FROM songs
+WHERE COALESCE(text, '') <> '';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS song_synced_lines;
+
+DROP TABLE IF EXISTS song_synced_lyrics;
@@ -0,0 +1,20 @@
+CREATE TABLE song_synced_lyrics (
+    song_id INT PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+    -- Заголовок [offset:] в миллисекундах: положительное значение показывает строки раньше
+    offset_ms INT NOT NULL DEFAULT 0,
+    tags JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE song_synced_lines (
+    song_id INT NOT NULL REFERENCES song_synced_lyrics(song_id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+    time_ms INT NOT NULL,
+    text TEXT NOT NULL,
+    -- Слова enhanced LRC: [{"time_ms": 12500, "text": "..."}]
+    words JSONB,
+    PRIMARY KEY (song_id, line_no)
+);
+
+CREATE INDEX song_synced_lines_time_idx ON song_synced_lines (song_id, time_ms);
@@ -0,0 +1,6 @@
+-- Частоты слов текста для индекса похожих песен. После перезапуска индекс восстанавливается из этой таблицы без повторного разбора текстов
+CREATE TABLE song_term_vectors (
+    song_id INT PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+    terms JSONB NOT NULL,
+    indexed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
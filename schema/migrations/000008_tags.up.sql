@@ -0,0 +1,19 @@
+-- Теги песен: жанр, настроение, эпоха или произвольная метка команды. Тег с пространством имен записывается как genre:rock
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    namespace VARCHAR(64) NOT NULL DEFAULT '',
+    name VARCHAR(128) NOT NULL,
+    full_name VARCHAR(193) GENERATED ALWAYS AS (CASE WHEN namespace = '' THEN name ELSE namespace || ':' || name END) STORED,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (full_name)
+);
+
+CREATE TABLE song_tags (
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    tag_id INT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (song_id, tag_id)
+);
+
+CREATE INDEX song_tags_tag_id_idx ON song_tags (tag_id);
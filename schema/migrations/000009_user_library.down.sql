@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS user_songs_rating ON user_songs;
+
+DROP FUNCTION IF EXISTS update_song_rating();
+
+ALTER TABLE songs DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE songs DROP COLUMN IF EXISTS rating_avg;
+
+DROP TABLE IF EXISTS user_songs;
@@ -0,0 +1,48 @@
+-- Личное состояние песни для пользователя (X-User-ID): библиотека, избранное, оценка и заметка
+CREATE TABLE user_songs (
+    user_id VARCHAR(255) NOT NULL,
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    in_library BOOLEAN NOT NULL DEFAULT FALSE,
+    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    rating SMALLINT CHECK (rating BETWEEN 1 AND 5),
+    note TEXT NOT NULL DEFAULT '',
+    added_at TIMESTAMP,
+    favorited_at TIMESTAMP,
+    rated_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, song_id)
+);
+
+CREATE INDEX user_songs_library_idx ON user_songs (user_id, added_at) WHERE in_library;
+CREATE INDEX user_songs_song_id_idx ON user_songs (song_id);
+
+-- Средняя оценка хранится в songs, чтобы отдавать ее в списках и сортировать по ней без агрегации
+ALTER TABLE songs ADD COLUMN rating_avg NUMERIC(3, 2);
+ALTER TABLE songs ADD COLUMN rating_count INT NOT NULL DEFAULT 0;
+
+CREATE FUNCTION update_song_rating() RETURNS trigger AS $$
+DECLARE
+    target INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.song_id;
+    ELSE
+        target := NEW.song_id;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND NEW.rating IS NOT DISTINCT FROM OLD.rating THEN
+        RETURN NULL;
+    END IF;
+
+    UPDATE songs
+    SET rating_avg = r.avg, rating_count = r.count
+    FROM (SELECT AVG(rating)::NUMERIC(3, 2) AS avg, COUNT(rating) AS count FROM user_songs WHERE song_id = target) r
+    WHERE songs.id = target;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER user_songs_rating
+    AFTER INSERT OR UPDATE OF rating OR DELETE ON user_songs
+    FOR EACH ROW EXECUTE FUNCTION update_song_rating();
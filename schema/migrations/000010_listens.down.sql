@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS listens_playing_now;
+
+DROP TABLE IF EXISTS listen_ingest_queue;
+
+DROP INDEX IF EXISTS songs_artist_title_idx;
+
+DROP FUNCTION IF EXISTS ensure_listens_partition(DATE);
+
+-- Секции удаляются вместе с родительской таблицей
+DROP TABLE IF EXISTS listens;
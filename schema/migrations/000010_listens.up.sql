@@ -0,0 +1,69 @@
+-- История прослушиваний в формате ListenBrainz. Таблица секционирована по месяцам; секции создаются
+-- функцией ensure_listens_partition перед записью, поэтому импорт старой истории не требует подготовки
+CREATE TABLE listens (
+    user_id VARCHAR(255) NOT NULL,
+    listened_at TIMESTAMP NOT NULL,
+    song_id INT REFERENCES songs(id) ON DELETE SET NULL,
+    artist_name VARCHAR(512) NOT NULL,
+    track_name VARCHAR(512) NOT NULL,
+    release_name VARCHAR(512) NOT NULL DEFAULT '',
+    duration_ms INT,
+    recording_mbid VARCHAR(36),
+    submission_client VARCHAR(255),
+    additional_info JSONB NOT NULL DEFAULT '{}',
+    inserted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Как и в ListenBrainz, повторная отправка того же прослушивания не создает дубликат
+    PRIMARY KEY (user_id, listened_at, track_name)
+) PARTITION BY RANGE (listened_at);
+
+CREATE INDEX listens_song_id_idx ON listens (song_id);
+CREATE INDEX listens_unmatched_idx ON listens (LOWER(artist_name), LOWER(track_name)) WHERE song_id IS NULL;
+
+CREATE FUNCTION ensure_listens_partition(month_start DATE) RETURNS void AS $$
+DECLARE
+    from_date DATE := date_trunc('month', month_start)::DATE;
+BEGIN
+    EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF listens FOR VALUES FROM (%L) TO (%L)',
+        'listens_' || to_char(from_date, 'YYYY_MM'), from_date, (from_date + INTERVAL '1 month')::DATE);
+EXCEPTION WHEN duplicate_table THEN
+    -- Секцию одновременно создал другой запрос
+    NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT ensure_listens_partition(CURRENT_DATE);
+
+-- Сопоставление прослушиваний с песнями по исполнителю и названию
+CREATE INDEX songs_artist_title_idx ON songs (LOWER(group_name), LOWER(song_name));
+
+-- Песни из прослушиваний, которых нет в каталоге; фоновый обработчик ищет их на Genius и добавляет
+CREATE TABLE listen_ingest_queue (
+    id SERIAL PRIMARY KEY,
+    artist_key VARCHAR(512) NOT NULL,
+    track_key VARCHAR(512) NOT NULL,
+    artist_name VARCHAR(512) NOT NULL,
+    track_name VARCHAR(512) NOT NULL,
+    listen_count INT NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'matched', 'not_found', 'ambiguous', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    song_id INT REFERENCES songs(id) ON DELETE SET NULL,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (artist_key, track_key)
+);
+
+CREATE INDEX listen_ingest_queue_pending_idx ON listen_ingest_queue (next_attempt_at) WHERE status IN ('pending', 'failed');
+
+-- Трек, который пользователь слушает прямо сейчас (listen_type = playing_now)
+CREATE TABLE listens_playing_now (
+    user_id VARCHAR(255) PRIMARY KEY,
+    song_id INT REFERENCES songs(id) ON DELETE SET NULL,
+    artist_name VARCHAR(512) NOT NULL,
+    track_name VARCHAR(512) NOT NULL,
+    release_name VARCHAR(512) NOT NULL DEFAULT '',
+    additional_info JSONB NOT NULL DEFAULT '{}',
+    started_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
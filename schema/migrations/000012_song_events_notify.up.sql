@@ -0,0 +1,12 @@
+-- Уведомление о новом событии outbox для живых подписок всех экземпляров сервиса.
+-- NOTIFY доставляется при фиксации транзакции; в канал передается только идентификатор события
+CREATE FUNCTION notify_song_event() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('song_events', NEW.id::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_events_notify
+    AFTER INSERT ON outbox_events
+    FOR EACH ROW EXECUTE FUNCTION notify_song_event();
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS tags_change_feed ON tags;
+DROP TRIGGER IF EXISTS song_tags_change_feed ON song_tags;
+DROP TRIGGER IF EXISTS songs_change_feed_update ON songs;
+DROP TRIGGER IF EXISTS songs_change_feed ON songs;
+
+DROP FUNCTION IF EXISTS tags_change_feed();
+DROP FUNCTION IF EXISTS song_tags_change_feed();
+DROP FUNCTION IF EXISTS songs_change_feed();
+DROP FUNCTION IF EXISTS record_song_change(INT, BOOLEAN);
+
+DROP TABLE IF EXISTS song_changes;
+DROP TABLE IF EXISTS change_feed_state;
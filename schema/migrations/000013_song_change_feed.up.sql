@@ -0,0 +1,97 @@
+-- Лента изменений для инкрементальной синхронизации клиентов: последнее изменение каждой песни с порядковым номером
+CREATE TABLE change_feed_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    -- Надгробия с номером не больше purged_seq удалены; клиентам с более старым курсором нужна полная синхронизация
+    purged_seq BIGINT NOT NULL DEFAULT 0
+);
+
+-- Без внешнего ключа: надгробие удаленной песни живет дольше нее
+CREATE TABLE song_changes (
+    song_id INT PRIMARY KEY,
+    seq BIGINT NOT NULL UNIQUE,
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX song_changes_tombstones_idx ON song_changes (changed_at) WHERE deleted;
+
+INSERT INTO change_feed_state (last_seq) SELECT COUNT(*) FROM songs;
+INSERT INTO song_changes (song_id, seq) SELECT id, ROW_NUMBER() OVER (ORDER BY id) FROM songs;
+
+-- Строка счетчика остается заблокированной до конца транзакции, поэтому номера фиксируются строго по возрастанию
+-- и клиент, прочитавший номер N, не пропустит изменение с меньшим номером, зафиксированное позже.
+-- Цена этого — все транзакции, меняющие песни или их теги, выстраиваются в очередь на строке счетчика до своего
+-- COMMIT. Для правок каталога через API и синхронизации это приемлемо; частые записи в ленту не попадают
+-- (оценки пользователей, служебные колонки синхронизации), а длинные транзакции с изменениями песен держать не стоит
+CREATE FUNCTION record_song_change(target INT, is_deleted BOOLEAN) RETURNS void AS $$
+DECLARE
+    next_seq BIGINT;
+BEGIN
+    UPDATE change_feed_state SET last_seq = last_seq + 1 RETURNING last_seq INTO next_seq;
+
+    INSERT INTO song_changes (song_id, seq, deleted, changed_at) VALUES (target, next_seq, is_deleted, NOW())
+    ON CONFLICT (song_id) DO UPDATE SET seq = EXCLUDED.seq, deleted = EXCLUDED.deleted, changed_at = EXCLUDED.changed_at;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION songs_change_feed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM record_song_change(OLD.id, TRUE);
+    ELSE
+        PERFORM record_song_change(NEW.id, FALSE);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_change_feed
+    AFTER INSERT OR DELETE ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_change_feed();
+
+-- Служебные колонки синхронизации и средняя оценка не попадают в ленту: оценка пересчитывается при каждой оценке
+-- пользователя и блокировала бы счетчик чаще всех остальных изменений. Клиент получает актуальную оценку
+-- вместе со следующим изменением песни
+CREATE TRIGGER songs_change_feed_update
+    AFTER UPDATE ON songs
+    FOR EACH ROW
+    WHEN ((OLD.group_name, OLD.song_name, OLD.text, OLD.release_date, OLD.album, OLD.link)
+          IS DISTINCT FROM (NEW.group_name, NEW.song_name, NEW.text, NEW.release_date, NEW.album, NEW.link))
+    EXECUTE FUNCTION songs_change_feed();
+
+CREATE FUNCTION song_tags_change_feed() RETURNS trigger AS $$
+DECLARE
+    target INT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.song_id;
+    ELSE
+        target := NEW.song_id;
+    END IF;
+
+    -- Теги удаленной песни снимаются каскадом, ее надгробие уже записано
+    IF EXISTS (SELECT 1 FROM songs WHERE id = target) THEN
+        PERFORM record_song_change(target, FALSE);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_tags_change_feed
+    AFTER INSERT OR DELETE ON song_tags
+    FOR EACH ROW EXECUTE FUNCTION song_tags_change_feed();
+
+-- Переименование тега меняет список тегов всех отмеченных им песен
+CREATE FUNCTION tags_change_feed() RETURNS trigger AS $$
+BEGIN
+    PERFORM record_song_change(st.song_id, FALSE) FROM song_tags st WHERE st.tag_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tags_change_feed
+    AFTER UPDATE ON tags
+    FOR EACH ROW
+    WHEN (OLD.full_name IS DISTINCT FROM NEW.full_name)
+    EXECUTE FUNCTION tags_change_feed();
@@ -2,33 +2,47 @@ package todo
 
 import (
 	"context"
+	"errors"
 	"musPlayer/internal/logger"
 	"net/http"
 	"time"
 )
 
 type Server struct {
+	port       string
 	httpServer *http.Server
 }
 
-// Run запускает HTTP-сервер на заданном порту с указанным обработчиком
-func (s *Server) Run(port string, handler http.Handler) error {
-	s.httpServer = &http.Server{
-		Addr:              ":" + port,
-		MaxHeaderBytes:    1 << 20, // 1MB
-		ReadHeaderTimeout: 30 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		Handler:           handler,
+// NewServer создает HTTP-сервер на заданном порту с указанным обработчиком.
+// Сервер создается заранее, чтобы Shutdown, вызванный до или во время Run, остановил именно его
+func NewServer(port string, handler http.Handler) *Server {
+	return &Server{
+		port: port,
+		httpServer: &http.Server{
+			Addr:              ":" + port,
+			MaxHeaderBytes:    1 << 20, // 1MB
+			ReadHeaderTimeout: 30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			Handler:           handler,
+		},
 	}
+}
 
+// Run запускает HTTP-сервер и блокируется до его остановки
+func (s *Server) Run() error {
 	// Логируем, что сервер запущен
-	logger.Logger.Infof("Server is running on port %s", port)
+	logger.Logger.Infof("Server is running on port %s", s.port)
 
-	// Запускаем сервер и возвращаем ошибку, если она произошла
-	return s.httpServer.ListenAndServe()
+	// Запускаем сервер и возвращаем ошибку, если она произошла.
+	// http.ErrServerClosed означает штатную остановку через Shutdown, в том числе до запуска
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
-// Shutdown корректно завершает работу сервера
+// Shutdown корректно завершает работу сервера: перестает принимать соединения
+// и ждет завершения текущих запросов до истечения ctx
 func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }